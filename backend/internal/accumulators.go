@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"sort"
+	"strconv"
+)
+
+// AccumulatorReport contains the accumulators of a job merged over all tasks and the user accumulators of
+// every vertex per subtask.
+type AccumulatorReport struct {
+	JobId        string               `json:"jobId"`
+	Accumulators []FlinkAccumulator   `json:"accumulators"`
+	Vertices     []VertexAccumulators `json:"vertices"`
+}
+
+type VertexAccumulators struct {
+	VertexId     string                  `json:"vertexId"`
+	Name         string                  `json:"name"`
+	Parallelism  int                     `json:"parallelism"`
+	Accumulators []AggregatedAccumulator `json:"accumulators"`
+	Error        string                  `json:"error,omitempty"`
+}
+
+// AggregatedAccumulator is a user accumulator of a vertex aggregated over its subtasks. Sum, min and max are
+// only set if the values of all subtasks are numeric, which isn't the case for histograms and custom accumulators.
+type AggregatedAccumulator struct {
+	Name       string               `json:"name"`
+	Type       string               `json:"type"`
+	Sum        *float64             `json:"sum,omitempty"`
+	Min        *float64             `json:"min,omitempty"`
+	Max        *float64             `json:"max,omitempty"`
+	MinSubtask *int                 `json:"minSubtask,omitempty"`
+	MaxSubtask *int                 `json:"maxSubtask,omitempty"`
+	Subtasks   []SubtaskAccumulator `json:"subtasks"`
+}
+
+type SubtaskAccumulator struct {
+	Subtask  int    `json:"subtask"`
+	Attempt  int    `json:"attempt"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Value    string `json:"value"`
+}
+
+// aggregateSubtaskAccumulators groups the user accumulators of all subtasks of a vertex by name. Subtasks which
+// didn't report an accumulator yet are missing from its subtasks.
+func aggregateSubtaskAccumulators(response *FlinkSubtasksAccumulators) []AggregatedAccumulator {
+	accumulators := map[string]*AggregatedAccumulator{}
+	values := map[string][]float64{}
+
+	for _, subtask := range response.Subtasks {
+		for _, accumulator := range subtask.UserAccumulators {
+			aggregated, ok := accumulators[accumulator.Name]
+			if !ok {
+				aggregated = &AggregatedAccumulator{
+					Name:     accumulator.Name,
+					Type:     accumulator.Type,
+					Subtasks: []SubtaskAccumulator{},
+				}
+				accumulators[accumulator.Name] = aggregated
+				values[accumulator.Name] = []float64{}
+			}
+
+			aggregated.Subtasks = append(aggregated.Subtasks, SubtaskAccumulator{
+				Subtask:  subtask.Subtask,
+				Attempt:  subtask.Attempt,
+				Endpoint: subtask.Endpoint,
+				Value:    accumulator.Value,
+			})
+
+			// a single non numeric value makes the whole accumulator non numeric
+			if value, err := strconv.ParseFloat(accumulator.Value, 64); err == nil && values[accumulator.Name] != nil {
+				values[accumulator.Name] = append(values[accumulator.Name], value)
+			} else {
+				values[accumulator.Name] = nil
+			}
+		}
+	}
+
+	result := make([]AggregatedAccumulator, 0, len(accumulators))
+	for name, aggregated := range accumulators {
+		if values[name] != nil {
+			aggregated.aggregate(values[name])
+		}
+
+		result = append(result, *aggregated)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+func (a *AggregatedAccumulator) aggregate(values []float64) {
+	sum, minIndex, maxIndex := 0.0, 0, 0
+
+	for i, value := range values {
+		sum += value
+
+		if value < values[minIndex] {
+			minIndex = i
+		}
+
+		if value > values[maxIndex] {
+			maxIndex = i
+		}
+	}
+
+	minValue, maxValue := values[minIndex], values[maxIndex]
+	minSubtask, maxSubtask := a.Subtasks[minIndex].Subtask, a.Subtasks[maxIndex].Subtask
+
+	a.Sum = &sum
+	a.Min = &minValue
+	a.Max = &maxValue
+	a.MinSubtask = &minSubtask
+	a.MaxSubtask = &maxSubtask
+}
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestAggregateSubtaskAccumulators(t *testing.T) {
+	response := &FlinkSubtasksAccumulators{
+		Parallelism: 3,
+		Subtasks: []FlinkSubtaskAccumulators{
+			{Subtask: 0, UserAccumulators: []FlinkAccumulator{
+				{Name: "dropped-records", Type: "LongCounter", Value: "4"},
+				{Name: "lateness", Type: "Histogram", Value: "{10=2}"},
+			}},
+			{Subtask: 1, UserAccumulators: []FlinkAccumulator{
+				{Name: "dropped-records", Type: "LongCounter", Value: "10"},
+				{Name: "lateness", Type: "Histogram", Value: "{}"},
+			}},
+			{Subtask: 2, UserAccumulators: []FlinkAccumulator{
+				{Name: "dropped-records", Type: "LongCounter", Value: "1"},
+			}},
+		},
+	}
+
+	accumulators := aggregateSubtaskAccumulators(response)
+	if len(accumulators) != 2 || accumulators[0].Name != "dropped-records" || accumulators[1].Name != "lateness" {
+		t.Fatalf("unexpected accumulators: %+v", accumulators)
+	}
+
+	dropped := accumulators[0]
+	if dropped.Sum == nil || *dropped.Sum != 15 || *dropped.Min != 1 || *dropped.MinSubtask != 2 || *dropped.Max != 10 || *dropped.MaxSubtask != 1 {
+		t.Fatalf("unexpected aggregation of dropped records: %+v", dropped)
+	}
+
+	lateness := accumulators[1]
+	if lateness.Sum != nil || len(lateness.Subtasks) != 2 {
+		t.Fatalf("expected a non numeric accumulator with 2 subtasks, got %+v", lateness)
+	}
+}
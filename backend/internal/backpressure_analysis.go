@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	metricBusyTimeMsPerSecond          = "busyTimeMsPerSecond"
+	metricBackPressuredTimeMsPerSecond = "backPressuredTimeMsPerSecond"
+
+	// a vertex is considered busy or backpressured if one of its subtasks spends this share of time in that state
+	bottleneckBusyThreshold          = 0.8
+	bottleneckBackpressureThreshold  = 0.5
+	bottleneckSourceBusyThreshold    = 0.95
+	bottleneckMaxBackpressureOfOwner = 0.1
+)
+
+// VertexBackpressure summarizes how busy and backpressured the subtasks of a vertex are.
+// Ratios are the share of time (0-1) the busiest or most backpressured subtask spends in that state.
+type VertexBackpressure struct {
+	VertexId              string   `json:"vertexId"`
+	Name                  string   `json:"name"`
+	Parallelism           int      `json:"parallelism"`
+	BackpressureLevel     string   `json:"backpressureLevel"`
+	BusyRatio             float64  `json:"busyRatio"`
+	BackpressuredRatio    float64  `json:"backpressuredRatio"`
+	AvgBusyRatio          float64  `json:"avgBusyRatio"`
+	AvgBackpressuredRatio float64  `json:"avgBackpressuredRatio"`
+	Upstream              []string `json:"upstream"`
+	Error                 string   `json:"error,omitempty"`
+}
+
+// BottleneckFinding is a vertex which is busy while the vertices upstream of it are backpressured.
+type BottleneckFinding struct {
+	Rank                  int      `json:"rank"`
+	VertexId              string   `json:"vertexId"`
+	Name                  string   `json:"name"`
+	Score                 float64  `json:"score"`
+	BusyRatio             float64  `json:"busyRatio"`
+	BackpressuredRatio    float64  `json:"backpressuredRatio"`
+	BackpressuredUpstream []string `json:"backpressuredUpstream"`
+	Reason                string   `json:"reason"`
+}
+
+// BackpressureReport is the backpressure state of all vertices of a job together with the ranked bottlenecks.
+type BackpressureReport struct {
+	JobId       string               `json:"jobId"`
+	Vertices    []VertexBackpressure `json:"vertices"`
+	Bottlenecks []BottleneckFinding  `json:"bottlenecks"`
+}
+
+// findBottlenecks ranks the vertices which are busy themselves while their upstream vertices are backpressured.
+// A source which is busy without being backpressured is reported as well, as nothing upstream can show it.
+// Vertices are expected in topological order, which is used to break ties in favor of the first bottleneck.
+func findBottlenecks(vertices []VertexBackpressure) []BottleneckFinding {
+	byId := make(map[string]VertexBackpressure, len(vertices))
+	for _, vertex := range vertices {
+		byId[vertex.VertexId] = vertex
+	}
+
+	findings := make([]BottleneckFinding, 0)
+	for _, vertex := range vertices {
+		if vertex.Error != "" || vertex.BusyRatio < bottleneckBusyThreshold || vertex.BackpressuredRatio > bottleneckMaxBackpressureOfOwner {
+			continue
+		}
+
+		if len(vertex.Upstream) == 0 {
+			if vertex.BusyRatio >= bottleneckSourceBusyThreshold {
+				findings = append(findings, BottleneckFinding{
+					VertexId:              vertex.VertexId,
+					Name:                  vertex.Name,
+					Score:                 vertex.BusyRatio * bottleneckBackpressureThreshold,
+					BusyRatio:             vertex.BusyRatio,
+					BackpressuredRatio:    vertex.BackpressuredRatio,
+					BackpressuredUpstream: []string{},
+					Reason:                fmt.Sprintf("source is busy %.0f%% of the time without being backpressured", vertex.BusyRatio*100),
+				})
+			}
+
+			continue
+		}
+
+		upstreamBackpressure := 0.0
+		backpressuredUpstream := make([]string, 0)
+		for _, upstreamId := range vertex.Upstream {
+			upstream, ok := byId[upstreamId]
+			if !ok || upstream.BackpressuredRatio < bottleneckBackpressureThreshold {
+				continue
+			}
+
+			backpressuredUpstream = append(backpressuredUpstream, upstream.Name)
+			upstreamBackpressure = max(upstreamBackpressure, upstream.BackpressuredRatio)
+		}
+
+		if len(backpressuredUpstream) == 0 {
+			continue
+		}
+
+		findings = append(findings, BottleneckFinding{
+			VertexId:              vertex.VertexId,
+			Name:                  vertex.Name,
+			Score:                 vertex.BusyRatio * upstreamBackpressure,
+			BusyRatio:             vertex.BusyRatio,
+			BackpressuredRatio:    vertex.BackpressuredRatio,
+			BackpressuredUpstream: backpressuredUpstream,
+			Reason: fmt.Sprintf("busy %.0f%% of the time while %d upstream vertices are backpressured up to %.0f%%",
+				vertex.BusyRatio*100, len(backpressuredUpstream), upstreamBackpressure*100),
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Score > findings[j].Score
+	})
+
+	for i := range findings {
+		findings[i].Rank = i + 1
+	}
+
+	return findings
+}
+
+// applyAggregatedMetrics sets the busy and backpressured ratios of a vertex from the subtask metrics in ms per second.
+func (v *VertexBackpressure) applyAggregatedMetrics(metrics []FlinkMetric) {
+	for _, metric := range metrics {
+		switch metric.Id {
+		case metricBusyTimeMsPerSecond:
+			v.BusyRatio = msPerSecondRatio(metric.Max)
+			v.AvgBusyRatio = msPerSecondRatio(metric.Avg)
+		case metricBackPressuredTimeMsPerSecond:
+			v.BackpressuredRatio = msPerSecondRatio(metric.Max)
+			v.AvgBackpressuredRatio = msPerSecondRatio(metric.Avg)
+		}
+	}
+}
+
+func msPerSecondRatio(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+
+	return min(*value/1000, 1)
+}
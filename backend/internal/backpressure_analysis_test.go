@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindBottlenecks(t *testing.T) {
+	// the vertices are in topological order
+	vertices := []VertexBackpressure{
+		{VertexId: "source", Name: "Source", BusyRatio: 0.2, BackpressuredRatio: 0.9},
+		{VertexId: "busy-source", Name: "Busy Source", BusyRatio: 0.97},
+		{VertexId: "slow-source", Name: "Slow Source", BusyRatio: 0.9},
+		{VertexId: "map", Name: "Map", BusyRatio: 0.9, BackpressuredRatio: 0.05, Upstream: []string{"source", "slow-source"}},
+		{VertexId: "filter", Name: "Filter", BusyRatio: 0.95, BackpressuredRatio: 0.6, Upstream: []string{"source"}},
+		{VertexId: "join", Name: "Join", BusyRatio: 0.9, Upstream: []string{"source"}},
+		{VertexId: "window", Name: "Window", BusyRatio: 0.99, Upstream: []string{"filter"}, Error: "metrics unavailable"},
+		{VertexId: "sink", Name: "Sink", BusyRatio: 0.85, Upstream: []string{"map", "join"}},
+	}
+
+	findings := findBottlenecks(vertices)
+
+	// the map and the join are tied, the map comes first in topological order
+	expected := []BottleneckFinding{
+		{Rank: 1, VertexId: "map", Name: "Map", Score: 0.9 * 0.9, BusyRatio: 0.9, BackpressuredRatio: 0.05, BackpressuredUpstream: []string{"Source"}},
+		{Rank: 2, VertexId: "join", Name: "Join", Score: 0.9 * 0.9, BusyRatio: 0.9, BackpressuredUpstream: []string{"Source"}},
+		{Rank: 3, VertexId: "busy-source", Name: "Busy Source", Score: 0.97 * bottleneckBackpressureThreshold, BusyRatio: 0.97, BackpressuredUpstream: []string{}},
+	}
+
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %+v", len(expected), findings)
+	}
+
+	for i, finding := range findings {
+		want := expected[i]
+		if finding.Rank != want.Rank || finding.VertexId != want.VertexId || finding.Name != want.Name || finding.Score != want.Score ||
+			finding.BusyRatio != want.BusyRatio || finding.BackpressuredRatio != want.BackpressuredRatio ||
+			!slices.Equal(finding.BackpressuredUpstream, want.BackpressuredUpstream) || finding.Reason == "" {
+			t.Errorf("expected finding %d to be %+v, got %+v", i, want, finding)
+		}
+	}
+}
+
+func TestFindBottlenecksWithoutBackpressure(t *testing.T) {
+	vertices := []VertexBackpressure{
+		{VertexId: "source", Name: "Source", BusyRatio: 0.5},
+		{VertexId: "map", Name: "Map", BusyRatio: 0.9, Upstream: []string{"source"}},
+		{VertexId: "sink", Name: "Sink", BusyRatio: 0.1, Upstream: []string{"map", "unknown"}},
+	}
+
+	if findings := findBottlenecks(vertices); len(findings) != 0 {
+		t.Errorf("expected no findings, got %+v", findings)
+	}
+}
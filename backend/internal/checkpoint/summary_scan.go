@@ -38,6 +38,32 @@ func extractStateFilePaths(data []byte) []string {
 	return uniqueStrings(paths)
 }
 
+// FindStatePaths extracts state file paths from arbitrary serialized data, e.g. the state handles Flink
+// keeps in its high availability services. Bytes in front of a path, such as a printable length prefix,
+// are dropped.
+func FindStatePaths(data []byte) []string {
+	paths := make([]string, 0)
+	for _, s := range scanInlineStrings(data) {
+		if idx := statePathIndex(s); idx >= 0 {
+			paths = append(paths, s[idx:])
+		}
+	}
+
+	return uniqueStrings(paths)
+}
+
+// statePathIndex returns the position of the first state path inside a string or -1.
+func statePathIndex(value string) int {
+	first := -1
+	for _, prefix := range []string{"s3://", "hdfs://", "file:/", "gs://"} {
+		if idx := strings.Index(value, prefix); idx >= 0 && (first == -1 || idx < first) {
+			first = idx
+		}
+	}
+
+	return first
+}
+
 // hasStatePathPrefix reports whether a string looks like a state file path.
 func hasStatePathPrefix(value string) bool {
 	if len(value) < 5 {
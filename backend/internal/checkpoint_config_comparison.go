@@ -0,0 +1,343 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	ConfigComparisonMatch         = "match"
+	ConfigComparisonMismatch      = "mismatch"
+	ConfigComparisonNotConfigured = "not_configured"
+	ConfigComparisonUnknown       = "unknown"
+)
+
+// CheckpointConfigSetting compares a single setting of the effective checkpoint configuration of a job with the value
+// configured in the spec of the deployment.
+type CheckpointConfigSetting struct {
+	Setting    string `json:"setting"`
+	Effective  string `json:"effective"`
+	ConfigKey  string `json:"configKey"`
+	Configured string `json:"configured,omitempty"`
+	Status     string `json:"status"`
+	Note       string `json:"note,omitempty"`
+}
+
+// CheckpointConfigComparison is the effective checkpoint configuration of a job next to the checkpointing and
+// state keys of the flinkConfiguration of the deployment.
+type CheckpointConfigComparison struct {
+	JobId         string                    `json:"jobId"`
+	Effective     *FlinkCheckpointConfig    `json:"effective"`
+	Settings      []CheckpointConfigSetting `json:"settings"`
+	Mismatches    int                       `json:"mismatches"`
+	Configuration map[string]string         `json:"configuration"`
+}
+
+// checkpointConfigRule maps a setting of the effective configuration to its config keys, the first key is the current
+// one and the others are deprecated names which are still accepted by Flink.
+type checkpointConfigRule struct {
+	setting   string
+	keys      []string
+	effective func(config *FlinkCheckpointConfig) string
+	matches   func(config *FlinkCheckpointConfig, configured string) (bool, error)
+	note      string
+}
+
+var checkpointConfigRules = []checkpointConfigRule{
+	{
+		setting:   "mode",
+		keys:      []string{"execution.checkpointing.mode"},
+		effective: func(c *FlinkCheckpointConfig) string { return c.Mode },
+		matches: func(c *FlinkCheckpointConfig, configured string) (bool, error) {
+			return normalizeConfigEnum(c.Mode) == normalizeConfigEnum(configured), nil
+		},
+		note: "the job sets its own checkpointing mode, e.g. with env.enableCheckpointing(interval, mode)",
+	},
+	{
+		setting:   "interval",
+		keys:      []string{"execution.checkpointing.interval"},
+		effective: func(c *FlinkCheckpointConfig) string { return formatCheckpointInterval(c.Interval) },
+		matches:   durationMatches(func(c *FlinkCheckpointConfig) int64 { return c.Interval }),
+		note:      "the job overrides the configured interval, usually with env.enableCheckpointing(interval)",
+	},
+	{
+		setting:   "timeout",
+		keys:      []string{"execution.checkpointing.timeout"},
+		effective: func(c *FlinkCheckpointConfig) string { return formatMillis(c.Timeout) },
+		matches:   durationMatches(func(c *FlinkCheckpointConfig) int64 { return c.Timeout }),
+		note:      "the job overrides the configured timeout, usually with getCheckpointConfig().setCheckpointTimeout()",
+	},
+	{
+		setting:   "min_pause",
+		keys:      []string{"execution.checkpointing.min-pause"},
+		effective: func(c *FlinkCheckpointConfig) string { return formatMillis(c.MinPause) },
+		matches:   durationMatches(func(c *FlinkCheckpointConfig) int64 { return c.MinPause }),
+		note:      "the job overrides the configured min pause, usually with getCheckpointConfig().setMinPauseBetweenCheckpoints()",
+	},
+	{
+		setting:   "max_concurrent",
+		keys:      []string{"execution.checkpointing.max-concurrent-checkpoints"},
+		effective: func(c *FlinkCheckpointConfig) string { return strconv.Itoa(c.MaxConcurrent) },
+		matches:   intMatches(func(c *FlinkCheckpointConfig) int { return c.MaxConcurrent }),
+		note:      "the job overrides the configured value, usually with getCheckpointConfig().setMaxConcurrentCheckpoints()",
+	},
+	{
+		setting:   "externalization",
+		keys:      []string{"execution.checkpointing.externalized-checkpoint-retention"},
+		effective: func(c *FlinkCheckpointConfig) string { return externalizedCheckpointRetention(c.Externalization) },
+		matches: func(c *FlinkCheckpointConfig, configured string) (bool, error) {
+			return normalizeConfigEnum(externalizedCheckpointRetention(c.Externalization)) == normalizeConfigEnum(configured), nil
+		},
+		note: "the job overrides the retention, usually with getCheckpointConfig().setExternalizedCheckpointCleanup()",
+	},
+	{
+		setting:   "unaligned_checkpoints",
+		keys:      []string{"execution.checkpointing.unaligned.enabled", "execution.checkpointing.unaligned"},
+		effective: func(c *FlinkCheckpointConfig) string { return strconv.FormatBool(c.UnalignedCheckpoints) },
+		matches:   boolMatches(func(c *FlinkCheckpointConfig) bool { return c.UnalignedCheckpoints }),
+		note:      "the job overrides the configured value, usually with getCheckpointConfig().enableUnalignedCheckpoints()",
+	},
+	{
+		setting:   "aligned_checkpoint_timeout",
+		keys:      []string{"execution.checkpointing.aligned-checkpoint-timeout", "execution.checkpointing.alignment-timeout"},
+		effective: func(c *FlinkCheckpointConfig) string { return formatMillis(c.AlignedCheckpointTimeout) },
+		matches:   durationMatches(func(c *FlinkCheckpointConfig) int64 { return c.AlignedCheckpointTimeout }),
+		note:      "the job overrides the configured value, usually with getCheckpointConfig().setAlignedCheckpointTimeout()",
+	},
+	{
+		setting:   "tolerable_failed_checkpoints",
+		keys:      []string{"execution.checkpointing.tolerable-failed-checkpoints"},
+		effective: func(c *FlinkCheckpointConfig) string { return strconv.Itoa(c.TolerableFailedCheckpoints) },
+		matches:   intMatches(func(c *FlinkCheckpointConfig) int { return c.TolerableFailedCheckpoints }),
+		note:      "the job overrides the configured value, usually with getCheckpointConfig().setTolerableCheckpointFailureNumber()",
+	},
+	{
+		setting:   "checkpoints_after_tasks_finish",
+		keys:      []string{"execution.checkpointing.checkpoints-after-tasks-finish", "execution.checkpointing.checkpoints-after-tasks-finish.enabled"},
+		effective: func(c *FlinkCheckpointConfig) string { return strconv.FormatBool(c.CheckpointsAfterTasksFinish) },
+		matches:   boolMatches(func(c *FlinkCheckpointConfig) bool { return c.CheckpointsAfterTasksFinish }),
+		note:      "the job overrides the configured value",
+	},
+	{
+		setting:   "state_backend",
+		keys:      []string{"state.backend.type", "state.backend"},
+		effective: func(c *FlinkCheckpointConfig) string { return c.StateBackend },
+		matches: func(c *FlinkCheckpointConfig, configured string) (bool, error) {
+			return normalizeStateBackend(c.StateBackend) == normalizeStateBackend(configured), nil
+		},
+		note: "the job sets its own state backend, usually with env.setStateBackend()",
+	},
+	{
+		setting:   "checkpoint_storage",
+		keys:      []string{"execution.checkpointing.storage", "state.checkpoint-storage"},
+		effective: func(c *FlinkCheckpointConfig) string { return c.CheckpointStorage },
+		matches: func(c *FlinkCheckpointConfig, configured string) (bool, error) {
+			return normalizeCheckpointStorage(c.CheckpointStorage) == normalizeCheckpointStorage(configured), nil
+		},
+		note: "the job sets its own checkpoint storage, usually with getCheckpointConfig().setCheckpointStorage()",
+	},
+	{
+		setting:   "state_changelog",
+		keys:      []string{"state.changelog.enabled", "state.backend.changelog.enabled"},
+		effective: func(c *FlinkCheckpointConfig) string { return strconv.FormatBool(c.StateChangelogEnabled) },
+		matches:   boolMatches(func(c *FlinkCheckpointConfig) bool { return c.StateChangelogEnabled }),
+		note:      "the job overrides the configured value, usually with env.enableChangelogStateBackend()",
+	},
+}
+
+// compareCheckpointConfig compares the effective checkpoint configuration of a job with the flinkConfiguration of the deployment.
+// Settings which are not configured are not counted as mismatch, as the job may rely on the defaults of Flink.
+func compareCheckpointConfig(jobID string, effective *FlinkCheckpointConfig, flinkConfiguration map[string]any) *CheckpointConfigComparison {
+	comparison := &CheckpointConfigComparison{
+		JobId:         jobID,
+		Effective:     effective,
+		Settings:      make([]CheckpointConfigSetting, 0, len(checkpointConfigRules)),
+		Configuration: map[string]string{},
+	}
+
+	for key, value := range flinkConfiguration {
+		if strings.HasPrefix(key, "execution.checkpointing.") || strings.HasPrefix(key, "state.") {
+			comparison.Configuration[key] = fmt.Sprint(value)
+		}
+	}
+
+	for _, rule := range checkpointConfigRules {
+		setting := CheckpointConfigSetting{
+			Setting:   rule.setting,
+			Effective: rule.effective(effective),
+			ConfigKey: rule.keys[0],
+			Status:    ConfigComparisonNotConfigured,
+		}
+
+		for _, key := range rule.keys {
+			if value, ok := comparison.Configuration[key]; ok {
+				setting.ConfigKey = key
+				setting.Configured = value
+
+				break
+			}
+		}
+
+		if setting.Configured != "" {
+			matches, err := rule.matches(effective, setting.Configured)
+
+			switch {
+			case err != nil:
+				setting.Status = ConfigComparisonUnknown
+				setting.Note = fmt.Sprintf("could not parse the configured value: %s", err)
+			case matches:
+				setting.Status = ConfigComparisonMatch
+			default:
+				setting.Status = ConfigComparisonMismatch
+				setting.Note = rule.note
+				comparison.Mismatches++
+			}
+		}
+
+		comparison.Settings = append(comparison.Settings, setting)
+	}
+
+	sort.SliceStable(comparison.Settings, func(i, j int) bool {
+		return comparison.Settings[i].Status == ConfigComparisonMismatch && comparison.Settings[j].Status != ConfigComparisonMismatch
+	})
+
+	return comparison
+}
+
+func durationMatches(effective func(config *FlinkCheckpointConfig) int64) func(config *FlinkCheckpointConfig, configured string) (bool, error) {
+	return func(config *FlinkCheckpointConfig, configured string) (bool, error) {
+		duration, err := parseFlinkDuration(configured)
+		if err != nil {
+			return false, err
+		}
+
+		return duration.Milliseconds() == effective(config), nil
+	}
+}
+
+func intMatches(effective func(config *FlinkCheckpointConfig) int) func(config *FlinkCheckpointConfig, configured string) (bool, error) {
+	return func(config *FlinkCheckpointConfig, configured string) (bool, error) {
+		value, err := strconv.Atoi(strings.TrimSpace(configured))
+		if err != nil {
+			return false, err
+		}
+
+		return value == effective(config), nil
+	}
+}
+
+func boolMatches(effective func(config *FlinkCheckpointConfig) bool) func(config *FlinkCheckpointConfig, configured string) (bool, error) {
+	return func(config *FlinkCheckpointConfig, configured string) (bool, error) {
+		value, err := strconv.ParseBool(strings.ToLower(strings.TrimSpace(configured)))
+		if err != nil {
+			return false, err
+		}
+
+		return value == effective(config), nil
+	}
+}
+
+// parseFlinkDuration parses a duration in the format accepted by Flink, e.g. "30 s", "10min", "1h" or "500".
+// A number without a unit is interpreted as milliseconds.
+func parseFlinkDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	unitStart := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+
+	number, unit := value, ""
+	if unitStart >= 0 {
+		number, unit = value[:unitStart], strings.ToLower(strings.TrimSpace(value[unitStart:]))
+	}
+
+	amount, err := strconv.ParseInt(number, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var multiplier time.Duration
+	switch unit {
+	case "ns", "nano", "nanos", "nanosecond", "nanoseconds":
+		multiplier = time.Nanosecond
+	case "µs", "us", "micro", "micros", "microsecond", "microseconds":
+		multiplier = time.Microsecond
+	case "", "ms", "milli", "millis", "millisecond", "milliseconds":
+		multiplier = time.Millisecond
+	case "s", "sec", "secs", "second", "seconds":
+		multiplier = time.Second
+	case "m", "min", "mins", "minute", "minutes":
+		multiplier = time.Minute
+	case "h", "hour", "hours":
+		multiplier = time.Hour
+	case "d", "day", "days":
+		multiplier = 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid duration unit %q in %q", unit, value)
+	}
+
+	return time.Duration(amount) * multiplier, nil
+}
+
+func formatMillis(millis int64) string {
+	return (time.Duration(millis) * time.Millisecond).String()
+}
+
+// formatCheckpointInterval formats the interval, which Flink reports as Long.MAX_VALUE if checkpointing is disabled.
+func formatCheckpointInterval(millis int64) string {
+	if millis == math.MaxInt64 {
+		return "disabled"
+	}
+
+	return formatMillis(millis)
+}
+
+func externalizedCheckpointRetention(externalization FlinkCheckpointExternalization) string {
+	switch {
+	case !externalization.Enabled:
+		return "NO_EXTERNALIZED_CHECKPOINTS"
+	case externalization.DeleteOnCancellation:
+		return "DELETE_ON_CANCELLATION"
+	default:
+		return "RETAIN_ON_CANCELLATION"
+	}
+}
+
+// normalizeConfigEnum makes enum values comparable regardless of case and separators, e.g. "exactly_once" and "EXACTLY-ONCE".
+func normalizeConfigEnum(value string) string {
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(value)))
+}
+
+// normalizeStateBackend maps the configured shortcuts and the class names reported by the job to the same names.
+// The deprecated "filesystem" and "jobmanager" backends keep their state on the heap like the hashmap backend.
+func normalizeStateBackend(value string) string {
+	value = strings.ToLower(value)
+
+	switch {
+	case strings.Contains(value, "rocksdb"):
+		return "rocksdb"
+	case strings.Contains(value, "forst"):
+		return "forst"
+	case strings.Contains(value, "hashmap"), strings.Contains(value, "filesystem"), strings.Contains(value, "jobmanager"), strings.Contains(value, "memory"):
+		return "hashmap"
+	default:
+		return value
+	}
+}
+
+func normalizeCheckpointStorage(value string) string {
+	value = strings.ToLower(value)
+
+	switch {
+	case strings.Contains(value, "filesystem"):
+		return "filesystem"
+	case strings.Contains(value, "jobmanager"):
+		return "jobmanager"
+	default:
+		return value
+	}
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFlinkDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"500":     500 * time.Millisecond,
+		"30 s":    30 * time.Second,
+		"10min":   10 * time.Minute,
+		"1 h":     time.Hour,
+		"2 days":  48 * time.Hour,
+		"250 ms":  250 * time.Millisecond,
+		" 5 MIN ": 5 * time.Minute,
+	}
+
+	for value, expected := range cases {
+		duration, err := parseFlinkDuration(value)
+		if err != nil {
+			t.Fatalf("parse %q: %v", value, err)
+		}
+		if duration != expected {
+			t.Fatalf("expected %q to be %s, got %s", value, expected, duration)
+		}
+	}
+
+	for _, value := range []string{"", "abc", "10 lightyears", "1.5s"} {
+		if _, err := parseFlinkDuration(value); err == nil {
+			t.Fatalf("expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestCompareCheckpointConfig(t *testing.T) {
+	effective := &FlinkCheckpointConfig{
+		Mode:              "exactly_once",
+		Interval:          10000,
+		Timeout:           600000,
+		StateBackend:      "EmbeddedRocksDBStateBackend",
+		CheckpointStorage: "FileSystemCheckpointStorage",
+	}
+
+	comparison := compareCheckpointConfig("job", effective, map[string]any{
+		"execution.checkpointing.interval":  "1 min",
+		"execution.checkpointing.timeout":   "10 min",
+		"execution.checkpointing.mode":      "EXACTLY_ONCE",
+		"state.backend.type":                "rocksdb",
+		"execution.checkpointing.min-pause": "not a duration",
+		"taskmanager.numberOfTaskSlots":     2,
+	})
+
+	statuses := map[string]string{}
+	for _, setting := range comparison.Settings {
+		statuses[setting.Setting] = setting.Status
+	}
+
+	expected := map[string]string{
+		"interval":           ConfigComparisonMismatch,
+		"timeout":            ConfigComparisonMatch,
+		"mode":               ConfigComparisonMatch,
+		"state_backend":      ConfigComparisonMatch,
+		"min_pause":          ConfigComparisonUnknown,
+		"checkpoint_storage": ConfigComparisonNotConfigured,
+	}
+
+	for setting, status := range expected {
+		if statuses[setting] != status {
+			t.Fatalf("expected %s to be %s, got %s", setting, status, statuses[setting])
+		}
+	}
+
+	if comparison.Mismatches != 1 || comparison.Settings[0].Setting != "interval" {
+		t.Fatalf("expected the interval to be the only mismatch listed first, got %+v", comparison.Settings[0])
+	}
+
+	if _, ok := comparison.Configuration["taskmanager.numberOfTaskSlots"]; ok {
+		t.Fatal("expected unrelated keys to be left out of the configuration")
+	}
+}
@@ -0,0 +1,203 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	CheckpointPhaseStartDelay = "start_delay"
+	CheckpointPhaseAlignment  = "alignment"
+	CheckpointPhaseSync       = "sync"
+	CheckpointPhaseAsync      = "async"
+	CheckpointPhaseOther      = "other"
+
+	flinkSubtaskCheckpointCompleted = "completed"
+)
+
+// CheckpointSubtaskTiming splits the end to end duration of a subtask checkpoint into its phases. Other is the part
+// which is not covered by any phase, e.g. the time until the acknowledgement reached the job manager.
+type CheckpointSubtaskTiming struct {
+	VertexId            string `json:"vertexId"`
+	VertexName          string `json:"vertexName"`
+	Subtask             int    `json:"subtask"`
+	Status              string `json:"status"`
+	EndToEndDurationMs  int64  `json:"endToEndDurationMs"`
+	StartDelayMs        int64  `json:"startDelayMs"`
+	AlignmentDurationMs int64  `json:"alignmentDurationMs"`
+	SyncDurationMs      int64  `json:"syncDurationMs"`
+	AsyncDurationMs     int64  `json:"asyncDurationMs"`
+	OtherMs             int64  `json:"otherMs"`
+	DominantPhase       string `json:"dominantPhase,omitempty"`
+	StateSize           int64  `json:"stateSize"`
+	CheckpointedSize    int64  `json:"checkpointedSize"`
+	Unaligned           bool   `json:"unaligned"`
+	Aborted             bool   `json:"aborted"`
+}
+
+// CheckpointVertexTiming is the checkpoint of a single vertex with the timings of all of its subtasks.
+// Pending subtasks did not acknowledge the checkpoint, which is the usual cause of a checkpoint timeout.
+type CheckpointVertexTiming struct {
+	VertexId                string                    `json:"vertexId"`
+	Name                    string                    `json:"name"`
+	Status                  string                    `json:"status"`
+	NumSubtasks             int                       `json:"numSubtasks"`
+	NumAcknowledgedSubtasks int                       `json:"numAcknowledgedSubtasks"`
+	EndToEndDurationMs      int64                     `json:"endToEndDurationMs"`
+	StateSize               int64                     `json:"stateSize"`
+	PendingSubtasks         []int                     `json:"pendingSubtasks"`
+	Slowest                 *CheckpointSubtaskTiming  `json:"slowest,omitempty"`
+	Subtasks                []CheckpointSubtaskTiming `json:"subtasks"`
+	Error                   string                    `json:"error,omitempty"`
+}
+
+// CheckpointDrilldown names the vertex and subtask which held up a checkpoint and why.
+type CheckpointDrilldown struct {
+	Checkpoint        FlinkCheckpointDetail    `json:"checkpoint"`
+	FailureMessage    string                   `json:"failureMessage,omitempty"`
+	SlowestVertexId   string                   `json:"slowestVertexId,omitempty"`
+	SlowestVertexName string                   `json:"slowestVertexName,omitempty"`
+	SlowestSubtask    *CheckpointSubtaskTiming `json:"slowestSubtask,omitempty"`
+	Summary           string                   `json:"summary"`
+	Vertices          []CheckpointVertexTiming `json:"vertices"`
+}
+
+// toCheckpointSubtaskTiming converts the statistics of a subtask. The end to end duration of pending subtasks
+// is the time from triggering the checkpoint until it failed or until now if it is still in progress.
+func toCheckpointSubtaskTiming(vertex *CheckpointVertexTiming, subtask FlinkSubtaskCheckpointStatistics, triggerTimestamp int64, pendingUntil int64) CheckpointSubtaskTiming {
+	timing := CheckpointSubtaskTiming{
+		VertexId:         vertex.VertexId,
+		VertexName:       vertex.Name,
+		Subtask:          subtask.Index,
+		Status:           subtask.Status,
+		StateSize:        subtask.StateSize,
+		CheckpointedSize: subtask.CheckpointedSize,
+		Unaligned:        subtask.UnalignedCheckpoint,
+		Aborted:          subtask.Aborted,
+	}
+
+	if subtask.Status != flinkSubtaskCheckpointCompleted {
+		timing.EndToEndDurationMs = max(pendingUntil-triggerTimestamp, 0)
+
+		return timing
+	}
+
+	timing.EndToEndDurationMs = subtask.EndToEndDuration
+	timing.StartDelayMs = subtask.StartDelay
+	timing.AlignmentDurationMs = subtask.Alignment.Duration
+	timing.SyncDurationMs = subtask.Checkpoint.Sync
+	timing.AsyncDurationMs = subtask.Checkpoint.Async
+	timing.OtherMs = max(timing.EndToEndDurationMs-timing.StartDelayMs-timing.AlignmentDurationMs-timing.SyncDurationMs-timing.AsyncDurationMs, 0)
+	timing.DominantPhase = timing.dominantPhase()
+
+	return timing
+}
+
+func (t CheckpointSubtaskTiming) dominantPhase() string {
+	phases := []struct {
+		name     string
+		duration int64
+	}{
+		{CheckpointPhaseStartDelay, t.StartDelayMs},
+		{CheckpointPhaseAlignment, t.AlignmentDurationMs},
+		{CheckpointPhaseSync, t.SyncDurationMs},
+		{CheckpointPhaseAsync, t.AsyncDurationMs},
+		{CheckpointPhaseOther, t.OtherMs},
+	}
+
+	dominant := phases[0]
+	for _, phase := range phases[1:] {
+		if phase.duration > dominant.duration {
+			dominant = phase
+		}
+	}
+
+	return dominant.name
+}
+
+// applySubtasks sets the subtask timings of a vertex and determines its slowest subtask. Pending subtasks are
+// always slower than acknowledged ones as they are still blocking the checkpoint.
+func (v *CheckpointVertexTiming) applySubtasks(details *FlinkTaskCheckpointDetails, triggerTimestamp int64, pendingUntil int64) {
+	v.Subtasks = make([]CheckpointSubtaskTiming, 0, len(details.Subtasks))
+	v.PendingSubtasks = []int{}
+
+	for _, subtask := range details.Subtasks {
+		timing := toCheckpointSubtaskTiming(v, subtask, triggerTimestamp, pendingUntil)
+		if timing.Status != flinkSubtaskCheckpointCompleted {
+			v.PendingSubtasks = append(v.PendingSubtasks, timing.Subtask)
+		}
+
+		v.Subtasks = append(v.Subtasks, timing)
+	}
+
+	for i := range v.Subtasks {
+		if v.Slowest == nil || isSlowerSubtask(v.Subtasks[i], *v.Slowest) {
+			v.Slowest = &v.Subtasks[i]
+		}
+	}
+}
+
+func isSlowerSubtask(a, b CheckpointSubtaskTiming) bool {
+	aPending := a.Status != flinkSubtaskCheckpointCompleted
+	bPending := b.Status != flinkSubtaskCheckpointCompleted
+
+	if aPending != bPending {
+		return aPending
+	}
+
+	return a.EndToEndDurationMs > b.EndToEndDurationMs
+}
+
+// buildCheckpointDrilldown picks the slowest vertex and subtask over all vertices and summarizes the result.
+func buildCheckpointDrilldown(details *FlinkCheckpointDetails, vertices []CheckpointVertexTiming) *CheckpointDrilldown {
+	sort.SliceStable(vertices, func(i, j int) bool {
+		return vertices[i].EndToEndDurationMs > vertices[j].EndToEndDurationMs
+	})
+
+	drilldown := &CheckpointDrilldown{
+		Checkpoint:     details.FlinkCheckpointDetail,
+		FailureMessage: details.FailureMessage,
+		Vertices:       vertices,
+	}
+
+	var slowestVertex *CheckpointVertexTiming
+	for i := range vertices {
+		slowest := vertices[i].Slowest
+		if slowest == nil {
+			continue
+		}
+
+		if drilldown.SlowestSubtask == nil || isSlowerSubtask(*slowest, *drilldown.SlowestSubtask) {
+			slowestVertex = &vertices[i]
+			drilldown.SlowestSubtask = slowest
+		}
+	}
+
+	if slowestVertex == nil {
+		drilldown.Summary = fmt.Sprintf("checkpoint %d has no subtask statistics", details.Id)
+
+		return drilldown
+	}
+
+	drilldown.SlowestVertexId = slowestVertex.VertexId
+	drilldown.SlowestVertexName = slowestVertex.Name
+	drilldown.Summary = drilldown.summarize(slowestVertex)
+
+	return drilldown
+}
+
+func (d *CheckpointDrilldown) summarize(vertex *CheckpointVertexTiming) string {
+	subtask := d.SlowestSubtask
+
+	if subtask.Status != flinkSubtaskCheckpointCompleted {
+		return fmt.Sprintf("subtask %d of %s did not acknowledge checkpoint %d after %s, %d of %d subtasks of the vertex are pending",
+			subtask.Subtask, subtask.VertexName, d.Checkpoint.Id, time.Duration(subtask.EndToEndDurationMs)*time.Millisecond,
+			len(vertex.PendingSubtasks), vertex.NumSubtasks)
+	}
+
+	return fmt.Sprintf("subtask %d of %s was the slowest with %s, mostly spent in %s (start delay %s, alignment %s, sync %s, async %s)",
+		subtask.Subtask, subtask.VertexName, time.Duration(subtask.EndToEndDurationMs)*time.Millisecond, subtask.DominantPhase,
+		time.Duration(subtask.StartDelayMs)*time.Millisecond, time.Duration(subtask.AlignmentDurationMs)*time.Millisecond,
+		time.Duration(subtask.SyncDurationMs)*time.Millisecond, time.Duration(subtask.AsyncDurationMs)*time.Millisecond)
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestToCheckpointSubtaskTiming(t *testing.T) {
+	vertex := &CheckpointVertexTiming{VertexId: "vertex", Name: "Window"}
+
+	completed := func(endToEnd, startDelay, alignment, sync, async int64) FlinkSubtaskCheckpointStatistics {
+		return FlinkSubtaskCheckpointStatistics{
+			Index:            1,
+			Status:           flinkSubtaskCheckpointCompleted,
+			EndToEndDuration: endToEnd,
+			StartDelay:       startDelay,
+			Alignment:        FlinkSubtaskCheckpointAlignment{Duration: alignment},
+			Checkpoint:       FlinkSubtaskCheckpointDuration{Sync: sync, Async: async},
+		}
+	}
+
+	cases := map[string]struct {
+		subtask      FlinkSubtaskCheckpointStatistics
+		pendingUntil int64
+		endToEndMs   int64
+		otherMs      int64
+		dominant     string
+	}{
+		"completed": {
+			subtask:    completed(1000, 100, 200, 50, 600),
+			endToEndMs: 1000,
+			otherMs:    50,
+			dominant:   CheckpointPhaseAsync,
+		},
+		"other is clamped at zero": {
+			subtask:    completed(500, 100, 200, 50, 600),
+			endToEndMs: 500,
+			dominant:   CheckpointPhaseAsync,
+		},
+		"other dominates": {
+			subtask:    completed(5000, 100, 200, 50, 600),
+			endToEndMs: 5000,
+			otherMs:    4050,
+			dominant:   CheckpointPhaseOther,
+		},
+		"pending": {
+			subtask:      FlinkSubtaskCheckpointStatistics{Index: 1, Status: "pending_or_failed"},
+			pendingUntil: 31000,
+			endToEndMs:   30000,
+		},
+		"pending before the trigger": {
+			subtask:      FlinkSubtaskCheckpointStatistics{Index: 1, Status: "pending_or_failed"},
+			pendingUntil: 500,
+		},
+	}
+
+	for name, tc := range cases {
+		timing := toCheckpointSubtaskTiming(vertex, tc.subtask, 1000, tc.pendingUntil)
+
+		if timing.VertexId != "vertex" || timing.VertexName != "Window" || timing.Subtask != 1 || timing.Status != tc.subtask.Status {
+			t.Errorf("%s: unexpected timing %+v", name, timing)
+		}
+
+		if timing.EndToEndDurationMs != tc.endToEndMs || timing.OtherMs != tc.otherMs || timing.DominantPhase != tc.dominant {
+			t.Errorf("%s: expected %d/%d/%q, got %d/%d/%q", name, tc.endToEndMs, tc.otherMs, tc.dominant, timing.EndToEndDurationMs, timing.OtherMs, timing.DominantPhase)
+		}
+	}
+}
+
+func TestCheckpointSubtaskTimingDominantPhase(t *testing.T) {
+	cases := map[string]struct {
+		timing   CheckpointSubtaskTiming
+		expected string
+	}{
+		"start delay":     {timing: CheckpointSubtaskTiming{StartDelayMs: 10, AlignmentDurationMs: 5}, expected: CheckpointPhaseStartDelay},
+		"alignment":       {timing: CheckpointSubtaskTiming{StartDelayMs: 10, AlignmentDurationMs: 50, SyncDurationMs: 5}, expected: CheckpointPhaseAlignment},
+		"sync":            {timing: CheckpointSubtaskTiming{SyncDurationMs: 50, AsyncDurationMs: 5}, expected: CheckpointPhaseSync},
+		"async":           {timing: CheckpointSubtaskTiming{SyncDurationMs: 5, AsyncDurationMs: 50, OtherMs: 10}, expected: CheckpointPhaseAsync},
+		"other":           {timing: CheckpointSubtaskTiming{AsyncDurationMs: 5, OtherMs: 50}, expected: CheckpointPhaseOther},
+		"first phase tie": {timing: CheckpointSubtaskTiming{AlignmentDurationMs: 50, AsyncDurationMs: 50}, expected: CheckpointPhaseAlignment},
+		"no durations":    {timing: CheckpointSubtaskTiming{}, expected: CheckpointPhaseStartDelay},
+	}
+
+	for name, tc := range cases {
+		if phase := tc.timing.dominantPhase(); phase != tc.expected {
+			t.Errorf("%s: expected %q, got %q", name, tc.expected, phase)
+		}
+	}
+}
+
+func TestBuildCheckpointDrilldown(t *testing.T) {
+	details := &FlinkCheckpointDetails{
+		FlinkCheckpointDetail: FlinkCheckpointDetail{Id: 42, Status: "FAILED", TriggerTimestamp: 1000},
+		FailureMessage:        "Checkpoint expired before completing.",
+	}
+
+	source := CheckpointVertexTiming{VertexId: "source", Name: "Source", NumSubtasks: 2, EndToEndDurationMs: 10000}
+	source.applySubtasks(&FlinkTaskCheckpointDetails{Subtasks: []FlinkSubtaskCheckpointStatistics{
+		{Index: 0, Status: flinkSubtaskCheckpointCompleted, EndToEndDuration: 4000},
+		{Index: 1, Status: flinkSubtaskCheckpointCompleted, EndToEndDuration: 10000, Checkpoint: FlinkSubtaskCheckpointDuration{Async: 9000}},
+	}}, details.TriggerTimestamp, 3000)
+
+	// the pending subtask is only pending for 2 seconds but still beats the completed subtask of the source
+	window := CheckpointVertexTiming{VertexId: "window", Name: "Window", NumSubtasks: 3, EndToEndDurationMs: 1000}
+	window.applySubtasks(&FlinkTaskCheckpointDetails{Subtasks: []FlinkSubtaskCheckpointStatistics{
+		{Index: 0, Status: flinkSubtaskCheckpointCompleted, EndToEndDuration: 1000},
+		{Index: 1, Status: "pending_or_failed"},
+		{Index: 2, Status: "pending_or_failed"},
+	}}, details.TriggerTimestamp, 3000)
+
+	sink := CheckpointVertexTiming{VertexId: "sink", Name: "Sink", NumSubtasks: 1, Error: "subtask statistics unavailable"}
+
+	if source.Slowest == nil || source.Slowest.Subtask != 1 || !slices.Equal(source.PendingSubtasks, []int{}) {
+		t.Fatalf("expected subtask 1 to be the slowest of the source without pending subtasks, got %+v", source)
+	}
+
+	if window.Slowest == nil || window.Slowest.Subtask != 1 || !slices.Equal(window.PendingSubtasks, []int{1, 2}) {
+		t.Fatalf("expected the first pending subtask to be the slowest of the window, got %+v", window)
+	}
+
+	drilldown := buildCheckpointDrilldown(details, []CheckpointVertexTiming{sink, window, source})
+
+	if drilldown.SlowestVertexId != "window" || drilldown.SlowestVertexName != "Window" || drilldown.SlowestSubtask.Subtask != 1 {
+		t.Errorf("expected subtask 1 of the window to be the slowest, got %s/%+v", drilldown.SlowestVertexId, drilldown.SlowestSubtask)
+	}
+
+	if drilldown.Checkpoint.Id != 42 || drilldown.FailureMessage != details.FailureMessage {
+		t.Errorf("unexpected checkpoint %+v", drilldown)
+	}
+
+	vertexIds := make([]string, 0, len(drilldown.Vertices))
+	for _, vertex := range drilldown.Vertices {
+		vertexIds = append(vertexIds, vertex.VertexId)
+	}
+
+	if expected := []string{"source", "window", "sink"}; !slices.Equal(vertexIds, expected) {
+		t.Errorf("expected the vertices to be sorted by duration %v, got %v", expected, vertexIds)
+	}
+
+	expected := "subtask 1 of Window did not acknowledge checkpoint 42 after 2s, 2 of 3 subtasks of the vertex are pending"
+	if drilldown.Summary != expected {
+		t.Errorf("expected summary %q, got %q", expected, drilldown.Summary)
+	}
+}
+
+func TestBuildCheckpointDrilldownCompleted(t *testing.T) {
+	details := &FlinkCheckpointDetails{FlinkCheckpointDetail: FlinkCheckpointDetail{Id: 7, Status: "COMPLETED", TriggerTimestamp: 1000}}
+
+	vertex := CheckpointVertexTiming{VertexId: "window", Name: "Window", NumSubtasks: 1}
+	vertex.applySubtasks(&FlinkTaskCheckpointDetails{Subtasks: []FlinkSubtaskCheckpointStatistics{
+		{Index: 0, Status: flinkSubtaskCheckpointCompleted, EndToEndDuration: 3000, StartDelay: 100, Checkpoint: FlinkSubtaskCheckpointDuration{Sync: 400, Async: 2000}},
+	}}, details.TriggerTimestamp, 5000)
+
+	drilldown := buildCheckpointDrilldown(details, []CheckpointVertexTiming{vertex})
+
+	if drilldown.SlowestSubtask == nil || drilldown.SlowestSubtask.DominantPhase != CheckpointPhaseAsync {
+		t.Fatalf("expected the async phase to dominate, got %+v", drilldown.SlowestSubtask)
+	}
+
+	if !strings.HasPrefix(drilldown.Summary, "subtask 0 of Window was the slowest with 3s, mostly spent in async") {
+		t.Errorf("unexpected summary %q", drilldown.Summary)
+	}
+}
+
+func TestBuildCheckpointDrilldownWithoutSubtaskStatistics(t *testing.T) {
+	details := &FlinkCheckpointDetails{FlinkCheckpointDetail: FlinkCheckpointDetail{Id: 7}}
+	vertices := []CheckpointVertexTiming{{VertexId: "window", Name: "Window", Error: "subtask statistics unavailable"}}
+
+	drilldown := buildCheckpointDrilldown(details, vertices)
+
+	if drilldown.SlowestSubtask != nil || drilldown.SlowestVertexId != "" {
+		t.Errorf("expected no slowest subtask, got %+v", drilldown)
+	}
+
+	if expected := "checkpoint 7 has no subtask statistics"; drilldown.Summary != expected {
+		t.Errorf("expected summary %q, got %q", expected, drilldown.Summary)
+	}
+}
@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	ConsistencySourceFlinkHistory     = "flink-history"
+	ConsistencySourceSavepointHistory = "savepoint-history"
+	ConsistencySourceStorage          = "storage"
+)
+
+// ConsistencyReport compares the checkpoints and savepoints Flink and the operator know about with what is in storage.
+type ConsistencyReport struct {
+	Namespace        string               `json:"namespace"`
+	Name             string               `json:"name"`
+	JobId            string               `json:"jobId,omitempty"`
+	CheckedAt        time.Time            `json:"checkedAt"`
+	MissingMetadata  []ConsistencyFinding `json:"missingMetadata"`
+	Orphaned         []ConsistencyFinding `json:"orphaned"`
+	DiscardedPresent []ConsistencyFinding `json:"discardedPresent"`
+	Errors           []string             `json:"errors,omitempty"`
+}
+
+// ConsistencyFinding is a single checkpoint or savepoint directory for which Flink and storage disagree.
+type ConsistencyFinding struct {
+	Path         string     `json:"path"`
+	Source       string     `json:"source"`
+	JobId        string     `json:"jobId,omitempty"`
+	CheckpointId *int64     `json:"checkpointId,omitempty"`
+	IsSavepoint  bool       `json:"isSavepoint"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Size         *int64     `json:"size,omitempty"`
+}
+
+// consistencyReference is a checkpoint or savepoint location known to Flink or the operator.
+type consistencyReference struct {
+	path         string
+	source       string
+	checkpointId *int64
+	isSavepoint  bool
+	completed    bool
+	discarded    bool
+}
+
+// collectConsistencyReferences gathers all locations from the Flink checkpoint statistics and the savepoint history.
+func collectConsistencyReferences(stats *FlinkCheckpointStatistics, savepointInfo *FlinkSavepointInfo) []consistencyReference {
+	var references []consistencyReference
+
+	if stats != nil {
+		details := stats.History
+		if stats.Latest != nil {
+			details = append(details, *stats.Latest)
+		}
+
+		for _, detail := range details {
+			if detail.ExternalPath == "" {
+				continue
+			}
+
+			id := detail.Id
+			references = append(references, consistencyReference{
+				path:         detail.ExternalPath,
+				source:       ConsistencySourceFlinkHistory,
+				checkpointId: &id,
+				isSavepoint:  detail.IsSavepoint,
+				completed:    detail.Status == "COMPLETED",
+				discarded:    detail.Discarded,
+			})
+		}
+
+		if stats.Restored != nil && stats.Restored.ExternalPath != "" {
+			id := stats.Restored.Id
+			references = append(references, consistencyReference{
+				path:         stats.Restored.ExternalPath,
+				source:       ConsistencySourceFlinkHistory,
+				checkpointId: &id,
+				isSavepoint:  stats.Restored.IsSavepoint,
+			})
+		}
+	}
+
+	if savepointInfo != nil {
+		for _, savepoint := range savepointInfo.SavepointHistory {
+			if savepoint.Location == "" {
+				continue
+			}
+
+			references = append(references, consistencyReference{
+				path:        savepoint.Location,
+				source:      ConsistencySourceSavepointHistory,
+				isSavepoint: true,
+				completed:   true,
+			})
+		}
+	}
+
+	return references
+}
+
+// addReference records a referenced location whose _metadata disagrees with the reference: a completed location
+// without it is missing in storage, a discarded one which still has it was not cleaned up.
+func (r *ConsistencyReport) addReference(path string, reference consistencyReference, metadataInfo *MetadataInfo) {
+	finding := ConsistencyFinding{
+		Path:         path,
+		Source:       reference.source,
+		CheckpointId: reference.checkpointId,
+		IsSavepoint:  reference.isSavepoint,
+		LastModified: metadataInfo.LastModified,
+		Size:         metadataInfo.Size,
+	}
+
+	switch {
+	case reference.discarded && metadataInfo.Exists:
+		r.DiscardedPresent = append(r.DiscardedPresent, finding)
+	case !reference.discarded && !metadataInfo.Exists:
+		r.MissingMetadata = append(r.MissingMetadata, finding)
+	}
+}
+
+// addOrphans records the directories of a job in storage which are not referenced by Flink or the operator.
+func (r *ConsistencyReport) addOrphans(jobId string, entries []StorageEntry, referenced map[string]bool) {
+	for _, entry := range entries {
+		// shared and task owned state is part of the checkpoints of the job, not a checkpoint on its own
+		if entry.Name == "shared" || entry.Name == "taskowned" || referenced[normalizeStoragePath(entry.Path)] {
+			continue
+		}
+
+		r.Orphaned = append(r.Orphaned, ConsistencyFinding{
+			Path:         entry.Path,
+			Source:       ConsistencySourceStorage,
+			JobId:        jobId,
+			CheckpointId: parseCheckpointId(entry.Name),
+			IsSavepoint:  !strings.HasPrefix(entry.Name, "chk-"),
+		})
+	}
+}
+
+// normalizeStoragePath maps the different S3 file system schemes of Flink to "s3://" and strips a trailing slash,
+// so locations reported by Flink can be compared with the directories listed from storage.
+func normalizeStoragePath(path string) string {
+	for _, scheme := range []string{"s3a://", "s3p://", "s3n://"} {
+		if strings.HasPrefix(path, scheme) {
+			path = "s3://" + strings.TrimPrefix(path, scheme)
+
+			break
+		}
+	}
+
+	return strings.TrimSuffix(path, "/")
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestCollectConsistencyReferences(t *testing.T) {
+	stats := &FlinkCheckpointStatistics{
+		Latest: &FlinkCheckpointDetail{Id: 12, Status: "COMPLETED", ExternalPath: "s3a://bucket/checkpoints/job/chk-12"},
+		History: []FlinkCheckpointDetail{
+			{Id: 11, Status: "COMPLETED", ExternalPath: "s3a://bucket/checkpoints/job/chk-11", Discarded: true},
+			{Id: 10, Status: "FAILED"},
+			{Id: 9, Status: "COMPLETED", IsSavepoint: true, ExternalPath: "s3://bucket/savepoints/savepoint-9"},
+		},
+		Restored: &FlinkRestoredCheckpoint{Id: 5, IsSavepoint: true, ExternalPath: "s3://bucket/savepoints/savepoint-5"},
+	}
+
+	var savepointInfo FlinkSavepointInfo
+	data := `{"savepointHistory": [{"timeStamp": 1700000000000, "location": "s3://bucket/savepoints/savepoint-7", "triggerType": "MANUAL"}, "s3://bucket/savepoints/savepoint-8", ""]}`
+	if err := json.Unmarshal([]byte(data), &savepointInfo); err != nil {
+		t.Fatalf("failed to unmarshal the savepoint info: %v", err)
+	}
+
+	type referenceKey struct {
+		path      string
+		source    string
+		savepoint bool
+		completed bool
+		discarded bool
+	}
+
+	keys := make([]referenceKey, 0)
+	for _, reference := range collectConsistencyReferences(stats, &savepointInfo) {
+		keys = append(keys, referenceKey{reference.path, reference.source, reference.isSavepoint, reference.completed, reference.discarded})
+
+		if reference.source == ConsistencySourceFlinkHistory && reference.checkpointId == nil {
+			t.Errorf("expected a checkpoint id for %s", reference.path)
+		}
+	}
+
+	expected := []referenceKey{
+		{"s3a://bucket/checkpoints/job/chk-11", ConsistencySourceFlinkHistory, false, true, true},
+		{"s3://bucket/savepoints/savepoint-9", ConsistencySourceFlinkHistory, true, true, false},
+		{"s3a://bucket/checkpoints/job/chk-12", ConsistencySourceFlinkHistory, false, true, false},
+		{"s3://bucket/savepoints/savepoint-5", ConsistencySourceFlinkHistory, true, false, false},
+		{"s3://bucket/savepoints/savepoint-7", ConsistencySourceSavepointHistory, true, true, false},
+		{"s3://bucket/savepoints/savepoint-8", ConsistencySourceSavepointHistory, true, true, false},
+	}
+
+	if !slices.Equal(keys, expected) {
+		t.Errorf("expected references\n%v\ngot\n%v", expected, keys)
+	}
+
+	if references := collectConsistencyReferences(nil, nil); len(references) != 0 {
+		t.Errorf("expected no references, got %v", references)
+	}
+}
+
+func TestFlinkSavepointUnmarshalJSON(t *testing.T) {
+	cases := map[string]struct {
+		data     string
+		expected FlinkSavepoint
+	}{
+		"object": {
+			data:     `{"timeStamp": 1700000000000, "location": "s3://bucket/savepoints/savepoint-1", "triggerType": "PERIODIC", "formatType": "CANONICAL"}`,
+			expected: FlinkSavepoint{TimeStamp: 1700000000000, Location: "s3://bucket/savepoints/savepoint-1", TriggerType: "PERIODIC", FormatType: "CANONICAL"},
+		},
+		"location": {
+			data:     `"s3://bucket/savepoints/savepoint-1"`,
+			expected: FlinkSavepoint{Location: "s3://bucket/savepoints/savepoint-1"},
+		},
+	}
+
+	for name, tc := range cases {
+		var savepoint FlinkSavepoint
+		if err := json.Unmarshal([]byte(tc.data), &savepoint); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+
+			continue
+		}
+
+		if savepoint != tc.expected {
+			t.Errorf("%s: expected %+v, got %+v", name, tc.expected, savepoint)
+		}
+	}
+
+	var savepoint FlinkSavepoint
+	if err := json.Unmarshal([]byte(`42`), &savepoint); err == nil {
+		t.Errorf("expected an error for a number, got %+v", savepoint)
+	}
+}
+
+func TestConsistencyReportAddReference(t *testing.T) {
+	report := &ConsistencyReport{MissingMetadata: []ConsistencyFinding{}, DiscardedPresent: []ConsistencyFinding{}}
+	id := int64(12)
+
+	// completed in Flink but missing in storage
+	report.addReference("s3://bucket/checkpoints/job/chk-12", consistencyReference{source: ConsistencySourceFlinkHistory, checkpointId: &id, completed: true}, &MetadataInfo{})
+	// completed and present
+	report.addReference("s3://bucket/checkpoints/job/chk-13", consistencyReference{source: ConsistencySourceFlinkHistory, completed: true}, &MetadataInfo{Exists: true})
+	// discarded by Flink but still in storage
+	report.addReference("s3://bucket/checkpoints/job/chk-11", consistencyReference{source: ConsistencySourceFlinkHistory, completed: true, discarded: true}, &MetadataInfo{Exists: true})
+	// discarded and removed
+	report.addReference("s3://bucket/checkpoints/job/chk-10", consistencyReference{source: ConsistencySourceFlinkHistory, discarded: true}, &MetadataInfo{})
+
+	if len(report.MissingMetadata) != 1 || report.MissingMetadata[0].Path != "s3://bucket/checkpoints/job/chk-12" || *report.MissingMetadata[0].CheckpointId != 12 {
+		t.Errorf("expected chk-12 to miss its metadata, got %+v", report.MissingMetadata)
+	}
+
+	if len(report.DiscardedPresent) != 1 || report.DiscardedPresent[0].Path != "s3://bucket/checkpoints/job/chk-11" {
+		t.Errorf("expected chk-11 to be discarded but present, got %+v", report.DiscardedPresent)
+	}
+}
+
+func TestConsistencyReportAddOrphans(t *testing.T) {
+	report := &ConsistencyReport{Orphaned: []ConsistencyFinding{}}
+	referenced := map[string]bool{
+		"s3://bucket/checkpoints/job/chk-12":         true,
+		"s3://bucket/checkpoints/job/savepoint-ab12": true,
+	}
+
+	entries := []StorageEntry{
+		{Name: "chk-12", Path: "s3://bucket/checkpoints/job/chk-12/"},
+		{Name: "chk-13", Path: "s3://bucket/checkpoints/job/chk-13/"},
+		{Name: "savepoint-ab12", Path: "s3a://bucket/checkpoints/job/savepoint-ab12"},
+		{Name: "savepoint-cd34", Path: "s3://bucket/checkpoints/job/savepoint-cd34"},
+		{Name: "shared", Path: "s3://bucket/checkpoints/job/shared/"},
+		{Name: "taskowned", Path: "s3://bucket/checkpoints/job/taskowned/"},
+	}
+
+	// only the directories unknown to Flink and the operator are orphaned
+	report.addOrphans("job", entries, referenced)
+
+	if len(report.Orphaned) != 2 {
+		t.Fatalf("expected 2 orphans, got %+v", report.Orphaned)
+	}
+
+	checkpoint, savepoint := report.Orphaned[0], report.Orphaned[1]
+	if checkpoint.Path != "s3://bucket/checkpoints/job/chk-13/" || checkpoint.JobId != "job" || checkpoint.Source != ConsistencySourceStorage ||
+		checkpoint.CheckpointId == nil || *checkpoint.CheckpointId != 13 || checkpoint.IsSavepoint {
+		t.Errorf("unexpected orphaned checkpoint %+v", checkpoint)
+	}
+
+	if savepoint.Path != "s3://bucket/checkpoints/job/savepoint-cd34" || savepoint.CheckpointId != nil || !savepoint.IsSavepoint {
+		t.Errorf("unexpected orphaned savepoint %+v", savepoint)
+	}
+}
@@ -14,6 +14,11 @@ type DeploymentEvent struct {
 	Deployment *FlinkDeployment `json:"deployment"`
 }
 
+type SessionJobEvent struct {
+	Type       watch.EventType  `json:"type"`
+	SessionJob *FlinkSessionJob `json:"sessionJob"`
+}
+
 func NewDeploymentWatcher(ctx context.Context, config cfg.Config, logger log.Logger) (*DeploymentWatcher, error) {
 	var err error
 	var k8sService *K8sService
@@ -23,26 +28,61 @@ func NewDeploymentWatcher(ctx context.Context, config cfg.Config, logger log.Log
 	}
 
 	return &DeploymentWatcher{
-		k8sService: k8sService,
-		resultChan: make(chan DeploymentEvent),
+		k8sService:           k8sService,
+		resultChan:           make(chan DeploymentEvent),
+		sessionJobResultChan: make(chan SessionJobEvent),
 	}, nil
 }
 
 type DeploymentWatcher struct {
-	k8sService *K8sService
-	resultChan chan DeploymentEvent
+	k8sService           *K8sService
+	resultChan           chan DeploymentEvent
+	sessionJobResultChan chan SessionJobEvent
 }
 
 func (s *DeploymentWatcher) Watch(ctx context.Context) error {
+	return s.watch(ctx, "deployments", s.k8sService.WatchDeployments, func(event watch.Event) error {
+		fd, err := FromUnstructured(event.Object)
+		if err != nil {
+			return fmt.Errorf("could not convert unstructured to FlinkDeployment: %w", err)
+		}
+
+		s.resultChan <- DeploymentEvent{
+			Type:       event.Type,
+			Deployment: fd,
+		}
+
+		return nil
+	})
+}
+
+// WatchSessionJobs watches the FlinkSessionJob resources the same way Watch watches the deployments.
+func (s *DeploymentWatcher) WatchSessionJobs(ctx context.Context) error {
+	return s.watch(ctx, "session jobs", s.k8sService.WatchSessionJobs, func(event watch.Event) error {
+		sessionJob, err := SessionJobFromUnstructured(event.Object)
+		if err != nil {
+			return fmt.Errorf("could not convert unstructured to FlinkSessionJob: %w", err)
+		}
+
+		s.sessionJobResultChan <- SessionJobEvent{
+			Type:       event.Type,
+			SessionJob: sessionJob,
+		}
+
+		return nil
+	})
+}
+
+func (s *DeploymentWatcher) watch(ctx context.Context, resource string, open func(ctx context.Context) (watch.Interface, error), handle func(event watch.Event) error) error {
 	var err error
 	var watcher watch.Interface
 
 	for {
-		if watcher, err = s.k8sService.WatchDeployments(ctx); err != nil {
-			return fmt.Errorf("could not watch deployments: %w", err)
+		if watcher, err = open(ctx); err != nil {
+			return fmt.Errorf("could not watch %s: %w", resource, err)
 		}
 
-		if err := s.processEvents(ctx, watcher); err != nil {
+		if err := s.processEvents(ctx, watcher, handle); err != nil {
 			return err
 		}
 	}
@@ -51,12 +91,9 @@ func (s *DeploymentWatcher) Watch(ctx context.Context) error {
 // processEvents reads events from a K8s watcher until the channel closes or the context is cancelled.
 // Returns nil when the watch channel closes (signaling the caller to reconnect) or the context is done.
 // Returns an error only on unrecoverable failures.
-func (s *DeploymentWatcher) processEvents(ctx context.Context, watcher watch.Interface) error {
+func (s *DeploymentWatcher) processEvents(ctx context.Context, watcher watch.Interface, handle func(event watch.Event) error) error {
 	defer watcher.Stop()
 
-	var err error
-	var fd *FlinkDeployment
-
 	for {
 		select {
 		case <-ctx.Done():
@@ -66,13 +103,8 @@ func (s *DeploymentWatcher) processEvents(ctx context.Context, watcher watch.Int
 				return nil
 			}
 
-			if fd, err = FromUnstructured(event.Object); err != nil {
-				return fmt.Errorf("could not convert unstructured to FlinkDeployment: %w", err)
-			}
-
-			s.resultChan <- DeploymentEvent{
-				Type:       event.Type,
-				Deployment: fd,
+			if err := handle(event); err != nil {
+				return err
 			}
 		}
 	}
@@ -81,3 +113,7 @@ func (s *DeploymentWatcher) processEvents(ctx context.Context, watcher watch.Int
 func (w *DeploymentWatcher) ResultChan() <-chan DeploymentEvent {
 	return w.resultChan
 }
+
+func (w *DeploymentWatcher) SessionJobResultChan() <-chan SessionJobEvent {
+	return w.sessionJobResultChan
+}
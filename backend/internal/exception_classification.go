@@ -0,0 +1,339 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/justtrackio/gosoline/pkg/appctx"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+)
+
+const exceptionCategoriesConfigKey = "exceptions.classification.categories"
+
+// ExceptionCategorySettings configures a category of exceptions. An exception belongs to the category if one
+// exception of its cause chain matches the exception and message patterns and all failure labels match. Failure
+// label values are regular expressions as well. Categories with a higher priority are checked first.
+type ExceptionCategorySettings struct {
+	Enabled       bool              `cfg:"enabled" default:"true"`
+	Name          string            `cfg:"name"`
+	Priority      int               `cfg:"priority"`
+	Exception     string            `cfg:"exception"`
+	Message       string            `cfg:"message"`
+	FailureLabels map[string]string `cfg:"failure_labels"`
+	Hint          string            `cfg:"hint"`
+	Runbook       string            `cfg:"runbook"`
+}
+
+// ExceptionCategory is the category assigned to an exception together with what to do about it.
+type ExceptionCategory struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Hint    string `json:"hint,omitempty"`
+	Runbook string `json:"runbook,omitempty"`
+}
+
+// ExceptionCategoryCount counts the exceptions of a category. Concurrent exceptions are included in the count.
+type ExceptionCategoryCount struct {
+	ExceptionCategory
+	Count           int       `json:"count"`
+	ConcurrentCount int       `json:"concurrentCount"`
+	FirstSeen       time.Time `json:"firstSeen"`
+	LastSeen        time.Time `json:"lastSeen"`
+	Tasks           []string  `json:"tasks"`
+}
+
+// ExceptionCategories aggregates the exception history of a job by category, the most frequent category first.
+type ExceptionCategories struct {
+	TotalExceptions int                      `json:"totalExceptions"`
+	Uncategorized   int                      `json:"uncategorized"`
+	Truncated       bool                     `json:"truncated"`
+	Categories      []ExceptionCategoryCount `json:"categories"`
+}
+
+type exceptionRule struct {
+	category      ExceptionCategory
+	priority      int
+	exception     *regexp.Regexp
+	message       *regexp.Regexp
+	failureLabels map[string]*regexp.Regexp
+}
+
+type exceptionClassifierCtxKey struct{}
+
+// ExceptionClassifier assigns the exceptions of a job to the configured categories.
+type ExceptionClassifier struct {
+	rules []exceptionRule
+}
+
+func ProvideExceptionClassifier(ctx context.Context, config cfg.Config) (*ExceptionClassifier, error) {
+	return appctx.Provide(ctx, exceptionClassifierCtxKey{}, func() (*ExceptionClassifier, error) {
+		categories, err := config.GetStringMap(exceptionCategoriesConfigKey, map[string]any{})
+		if err != nil {
+			return nil, fmt.Errorf("could not read exception categories: %w", err)
+		}
+
+		classifier := &ExceptionClassifier{
+			rules: make([]exceptionRule, 0, len(categories)),
+		}
+
+		for id := range categories {
+			settings := &ExceptionCategorySettings{}
+			if err = config.UnmarshalKey(exceptionCategoriesConfigKey+"."+id, settings); err != nil {
+				return nil, fmt.Errorf("could not unmarshal exception category %s: %w", id, err)
+			}
+
+			if !settings.Enabled {
+				continue
+			}
+
+			rule, err := newExceptionRule(id, settings)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exception category %s: %w", id, err)
+			}
+
+			classifier.rules = append(classifier.rules, rule)
+		}
+
+		sortExceptionRules(classifier.rules)
+
+		return classifier, nil
+	})
+}
+
+func newExceptionRule(id string, settings *ExceptionCategorySettings) (rule exceptionRule, err error) {
+	if settings.Exception == "" && settings.Message == "" && len(settings.FailureLabels) == 0 {
+		return rule, fmt.Errorf("at least one of exception, message or failure_labels has to be set")
+	}
+
+	name := settings.Name
+	if name == "" {
+		name = id
+	}
+
+	rule = exceptionRule{
+		category: ExceptionCategory{
+			Id:      id,
+			Name:    name,
+			Hint:    settings.Hint,
+			Runbook: settings.Runbook,
+		},
+		priority:      settings.Priority,
+		failureLabels: make(map[string]*regexp.Regexp, len(settings.FailureLabels)),
+	}
+
+	if rule.exception, err = compileOptionalPattern(settings.Exception); err != nil {
+		return rule, fmt.Errorf("invalid exception pattern: %w", err)
+	}
+
+	if rule.message, err = compileOptionalPattern(settings.Message); err != nil {
+		return rule, fmt.Errorf("invalid message pattern: %w", err)
+	}
+
+	for label, value := range settings.FailureLabels {
+		if rule.failureLabels[label], err = regexp.Compile(value); err != nil {
+			return rule, fmt.Errorf("invalid pattern of failure label %s: %w", label, err)
+		}
+	}
+
+	return rule, nil
+}
+
+func compileOptionalPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	return regexp.Compile(pattern)
+}
+
+func sortExceptionRules(rules []exceptionRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].priority != rules[j].priority {
+			return rules[i].priority > rules[j].priority
+		}
+
+		return rules[i].category.Id < rules[j].category.Id
+	})
+}
+
+// Classify returns the category of the first matching rule or nil if no rule matches the exception.
+func (c *ExceptionClassifier) Classify(entry FlinkExceptionEntry) *ExceptionCategory {
+	var causes []exceptionCause
+
+	for i := range c.rules {
+		if !c.rules[i].matchesFailureLabels(entry.FailureLabels) {
+			continue
+		}
+
+		if causes == nil {
+			causes = parseExceptionCauses(entry)
+		}
+
+		if c.rules[i].matchesCauses(causes) {
+			category := c.rules[i].category
+
+			return &category
+		}
+	}
+
+	return nil
+}
+
+// ClassifyHistory assigns the category to all exceptions of the history including their concurrent exceptions.
+func (c *ExceptionClassifier) ClassifyHistory(history *FlinkExceptionHistory) {
+	for i := range history.Entries {
+		c.ClassifyEntry(&history.Entries[i])
+	}
+}
+
+// ClassifyEntry assigns the category to the exception and its concurrent exceptions.
+func (c *ExceptionClassifier) ClassifyEntry(entry *FlinkExceptionEntry) {
+	entry.Category = c.Classify(*entry)
+
+	for i := range entry.ConcurrentExceptions {
+		entry.ConcurrentExceptions[i].Category = c.Classify(entry.ConcurrentExceptions[i])
+	}
+}
+
+func (r *exceptionRule) matchesFailureLabels(labels map[string]string) bool {
+	for label, pattern := range r.failureLabels {
+		value, ok := labels[label]
+		if !ok || !pattern.MatchString(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *exceptionRule) matchesCauses(causes []exceptionCause) bool {
+	if r.exception == nil && r.message == nil {
+		return true
+	}
+
+	for _, cause := range causes {
+		if (r.exception == nil || r.exception.MatchString(cause.className)) && (r.message == nil || r.message.MatchString(cause.message)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type exceptionCause struct {
+	className string
+	message   string
+}
+
+// parseExceptionCauses returns the class name and message of the exception and of every cause and suppressed
+// exception in its stack trace. Only the first line of multi line messages is kept.
+func parseExceptionCauses(entry FlinkExceptionEntry) []exceptionCause {
+	causes := make([]exceptionCause, 0)
+	header := true
+
+	for _, line := range strings.Split(entry.Stacktrace, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			continue
+		case header:
+			causes = append(causes, parseExceptionHeader(trimmed))
+			header = false
+		case stackSectionHeader.MatchString(trimmed):
+			causes = append(causes, parseExceptionHeader(stackSectionHeader.ReplaceAllString(trimmed, "")))
+		}
+	}
+
+	if len(causes) == 0 && entry.ExceptionName != "" {
+		causes = append(causes, exceptionCause{className: entry.ExceptionName})
+	}
+
+	return causes
+}
+
+func parseExceptionHeader(line string) exceptionCause {
+	className, message, _ := strings.Cut(line, ": ")
+
+	return exceptionCause{
+		className: strings.TrimSuffix(className, ":"),
+		message:   message,
+	}
+}
+
+// summarizeExceptionCategories counts the classified exceptions of the history by category.
+func summarizeExceptionCategories(history *FlinkExceptionHistory) *ExceptionCategories {
+	result := &ExceptionCategories{
+		Truncated:  history.Truncated,
+		Categories: []ExceptionCategoryCount{},
+	}
+
+	counts := map[string]*ExceptionCategoryCount{}
+	ids := make([]string, 0)
+
+	add := func(entry FlinkExceptionEntry, concurrent bool) {
+		result.TotalExceptions++
+
+		if entry.Category == nil {
+			result.Uncategorized++
+
+			return
+		}
+
+		count, ok := counts[entry.Category.Id]
+		if !ok {
+			count = &ExceptionCategoryCount{
+				ExceptionCategory: *entry.Category,
+				Tasks:             []string{},
+			}
+			counts[entry.Category.Id] = count
+			ids = append(ids, entry.Category.Id)
+		}
+
+		count.add(entry, concurrent)
+	}
+
+	for _, entry := range history.Entries {
+		add(entry, false)
+
+		for _, concurrentEntry := range entry.ConcurrentExceptions {
+			add(concurrentEntry, true)
+		}
+	}
+
+	for _, id := range ids {
+		result.Categories = append(result.Categories, *counts[id])
+	}
+
+	sort.SliceStable(result.Categories, func(i, j int) bool {
+		if result.Categories[i].Count != result.Categories[j].Count {
+			return result.Categories[i].Count > result.Categories[j].Count
+		}
+
+		return result.Categories[i].LastSeen.After(result.Categories[j].LastSeen)
+	})
+
+	return result
+}
+
+func (c *ExceptionCategoryCount) add(entry FlinkExceptionEntry, concurrent bool) {
+	c.Count++
+	if concurrent {
+		c.ConcurrentCount++
+	}
+
+	seen := time.UnixMilli(entry.Timestamp).UTC()
+	if c.FirstSeen.IsZero() || seen.Before(c.FirstSeen) {
+		c.FirstSeen = seen
+	}
+
+	if seen.After(c.LastSeen) {
+		c.LastSeen = seen
+	}
+
+	c.Tasks = appendUnique(c.Tasks, entry.TaskName)
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestExceptionClassifier(t *testing.T) {
+	settings := map[string]*ExceptionCategorySettings{
+		"oom":         {Priority: 100, Exception: `^java\.lang\.OutOfMemoryError$`},
+		"s3":          {Priority: 60, Message: `(?i)slow ?down`},
+		"npe":         {Priority: 10, Exception: `^java\.lang\.NullPointerException$`},
+		"user":        {Priority: 5, FailureLabels: map[string]string{"type": "^User$"}},
+		"kafka_state": {Priority: 50, Exception: `KafkaException$`, Message: `^Failed to construct`},
+	}
+
+	classifier := &ExceptionClassifier{}
+	for id, category := range settings {
+		rule, err := newExceptionRule(id, category)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", id, err)
+		}
+
+		classifier.rules = append(classifier.rules, rule)
+	}
+
+	sortExceptionRules(classifier.rules)
+
+	cases := map[string]FlinkExceptionEntry{
+		"oom": {
+			Stacktrace: "org.apache.flink.runtime.JobException: Recovery is suppressed\n\tat Foo.bar(Foo.java:1)\n" +
+				"Caused by: java.lang.NullPointerException: value\n\tat Foo.baz(Foo.java:2)\n" +
+				"Suppressed: java.lang.OutOfMemoryError: Java heap space\n",
+		},
+		"s3": {
+			Stacktrace: "java.io.IOException: upload failed\nCaused by: com.amazonaws.services.s3.model.AmazonS3Exception: Please reduce your request rate. (Service: Amazon S3; Status Code: 503; Error Code: SlowDown)\n",
+		},
+		"kafka_state": {
+			Stacktrace: "org.apache.kafka.common.KafkaException: Failed to construct kafka consumer\n",
+		},
+		"user": {
+			ExceptionName: "java.lang.IllegalStateException",
+			FailureLabels: map[string]string{"type": "User"},
+		},
+		"": {
+			// the exception and message patterns of a category have to match the same exception of the chain
+			Stacktrace:    "org.apache.kafka.common.KafkaException: unexpected\nCaused by: java.io.IOException: Failed to construct\n",
+			FailureLabels: map[string]string{"type": "System"},
+		},
+	}
+
+	for expected, entry := range cases {
+		category := classifier.Classify(entry)
+
+		switch {
+		case expected == "" && category != nil:
+			t.Errorf("expected no category, got %s", category.Id)
+		case expected != "" && (category == nil || category.Id != expected):
+			t.Errorf("expected category %s, got %+v", expected, category)
+		}
+	}
+
+	if _, err := newExceptionRule("empty", &ExceptionCategorySettings{Hint: "nothing to match"}); err == nil {
+		t.Errorf("expected an error for a category without patterns")
+	}
+}
+
+func TestSummarizeExceptionCategories(t *testing.T) {
+	oom := &ExceptionCategory{Id: "oom", Name: "Out of memory"}
+	npe := &ExceptionCategory{Id: "npe", Name: "NullPointerException"}
+
+	history := &FlinkExceptionHistory{
+		Entries: []FlinkExceptionEntry{
+			{Timestamp: 3000, TaskName: "Map (1/2)", Category: npe},
+			{Timestamp: 2000, TaskName: "Map (2/2)", Category: oom, ConcurrentExceptions: []FlinkExceptionEntry{
+				{Timestamp: 2000, TaskName: "Sink (1/1)", Category: oom},
+				{Timestamp: 2000, TaskName: "Source (1/1)"},
+			}},
+		},
+	}
+
+	summary := summarizeExceptionCategories(history)
+	if summary.TotalExceptions != 4 || summary.Uncategorized != 1 || len(summary.Categories) != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	first := summary.Categories[0]
+	if first.Id != "oom" || first.Count != 2 || first.ConcurrentCount != 1 || len(first.Tasks) != 2 {
+		t.Fatalf("unexpected first category: %+v", first)
+	}
+}
@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	lambdaClassName    = regexp.MustCompile(`\$\$Lambda(\$\d+)?(/0x[0-9a-f]+)?`)
+	generatedAccessor  = regexp.MustCompile(`(Generated\w*Accessor)\d+`)
+	proxyClassName     = regexp.MustCompile(`\$Proxy\d+`)
+	enhancedClassName  = regexp.MustCompile(`\$\$(EnhancerBy\w+|FastClassBy\w+|ByteBuddy)\$\$[0-9a-f]+`)
+	numberedClassName  = regexp.MustCompile(`\$\d+`)
+	frameModulePrefix  = regexp.MustCompile(`^(?:[\w.-]+(?:@[\w.-]+)?/)+`)
+	frameLineNumber    = regexp.MustCompile(`:\d+\)$`)
+	stackSectionHeader = regexp.MustCompile(`^(Caused by|Suppressed): `)
+)
+
+// ExceptionGroup are the exceptions of the history which share the same root cause after normalization. Concurrent
+// exceptions happened while the job was already failing and are often only a consequence of the root exception.
+type ExceptionGroup struct {
+	Fingerprint     string              `json:"fingerprint"`
+	ExceptionName   string              `json:"exceptionName"`
+	RootCause       string              `json:"rootCause"`
+	Category        *ExceptionCategory  `json:"category,omitempty"`
+	Count           int                 `json:"count"`
+	ConcurrentCount int                 `json:"concurrentCount"`
+	FirstSeen       time.Time           `json:"firstSeen"`
+	LastSeen        time.Time           `json:"lastSeen"`
+	Tasks           []string            `json:"tasks"`
+	TaskManagers    []string            `json:"taskManagers"`
+	Normalized      string              `json:"normalized"`
+	Example         FlinkExceptionEntry `json:"example"`
+}
+
+// ExceptionGroups is the exception history of a job grouped by root cause, the most frequent group first.
+type ExceptionGroups struct {
+	TotalExceptions int              `json:"totalExceptions"`
+	Truncated       bool             `json:"truncated"`
+	Groups          []ExceptionGroup `json:"groups"`
+}
+
+// normalizeStacktrace strips everything from a stack trace which differs between occurrences of the same failure:
+// exception messages, line numbers, module prefixes and the numbers of lambda, proxy and other generated classes.
+// Only the exception class names and the frames are kept. Java indents the sections of suppressed exceptions and
+// their causes by one tab per level, which is kept as one tab per level, while the frames of the top level aren't
+// indented anymore.
+func normalizeStacktrace(stacktrace string) string {
+	lines := make([]string, 0)
+	header := true
+
+	for _, line := range strings.Split(stacktrace, "\n") {
+		trimmed := strings.TrimSpace(line)
+		depth := len(line) - len(strings.TrimLeft(line, "\t"))
+
+		switch {
+		case trimmed == "":
+			continue
+		case header:
+			lines = append(lines, normalizeExceptionHeader(trimmed))
+			header = false
+		case strings.HasPrefix(trimmed, "at "):
+			// the frames are indented one level deeper than the header of their section
+			lines = append(lines, strings.Repeat("\t", max(depth-1, 0))+"at "+normalizeFrame(strings.TrimPrefix(trimmed, "at ")))
+		case stackSectionHeader.MatchString(trimmed):
+			lines = append(lines, strings.Repeat("\t", depth)+normalizeExceptionHeader(trimmed))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// normalizeExceptionHeader drops the message of lines like "Caused by: java.io.IOException: connection reset".
+func normalizeExceptionHeader(line string) string {
+	prefix := ""
+	if match := stackSectionHeader.FindString(line); match != "" {
+		prefix = match
+		line = strings.TrimPrefix(line, match)
+	}
+
+	if index := strings.Index(line, ": "); index >= 0 {
+		line = line[:index]
+	}
+
+	return prefix + normalizeClassName(strings.TrimSuffix(line, ":"))
+}
+
+func normalizeFrame(frame string) string {
+	frame = normalizeClassName(frame)
+	frame = frameModulePrefix.ReplaceAllString(frame, "")
+
+	return frameLineNumber.ReplaceAllString(frame, ")")
+}
+
+func normalizeClassName(name string) string {
+	name = lambdaClassName.ReplaceAllString(name, "$$$$Lambda")
+	name = generatedAccessor.ReplaceAllString(name, "$1")
+	name = proxyClassName.ReplaceAllString(name, "$$Proxy")
+	name = enhancedClassName.ReplaceAllString(name, "$$$$$1")
+
+	return numberedClassName.ReplaceAllString(name, "$$N")
+}
+
+// rootCauseSection returns the innermost cause of a normalized stack trace, which is the last "Caused by" section
+// on the top level, or the whole trace if the exception has no cause. The causes of suppressed exceptions are
+// indented and neither count as root cause nor belong to the section.
+func rootCauseSection(normalized string) string {
+	lines := strings.Split(normalized, "\n")
+
+	start := 0
+	for i, line := range lines {
+		if strings.HasPrefix(line, "Caused by: ") {
+			start = i
+		}
+	}
+
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "\t") {
+			end = i
+
+			break
+		}
+	}
+
+	return strings.Join(lines[start:end], "\n")
+}
+
+func fingerprintStacktrace(stacktrace string) (fingerprint string, rootCause string, normalized string) {
+	normalized = normalizeStacktrace(stacktrace)
+	section := rootCauseSection(normalized)
+	rootCause, _, _ = strings.Cut(strings.TrimPrefix(section, "Caused by: "), "\n")
+
+	sum := sha256.Sum256([]byte(section))
+
+	return hex.EncodeToString(sum[:8]), rootCause, normalized
+}
+
+// groupExceptions groups the root exceptions of the history and their concurrent exceptions by the fingerprint of their root cause.
+func groupExceptions(history *FlinkExceptionHistory) *ExceptionGroups {
+	result := &ExceptionGroups{
+		Truncated: history.Truncated,
+		Groups:    []ExceptionGroup{},
+	}
+
+	groups := map[string]*ExceptionGroup{}
+	fingerprints := make([]string, 0)
+
+	add := func(entry FlinkExceptionEntry, concurrent bool) {
+		result.TotalExceptions++
+
+		stacktrace := entry.Stacktrace
+		if stacktrace == "" {
+			stacktrace = entry.ExceptionName
+		}
+
+		fingerprint, rootCause, normalized := fingerprintStacktrace(stacktrace)
+		group, ok := groups[fingerprint]
+		if !ok {
+			group = &ExceptionGroup{
+				Fingerprint:   fingerprint,
+				ExceptionName: entry.ExceptionName,
+				RootCause:     rootCause,
+				Category:      entry.Category,
+				Normalized:    normalized,
+				Tasks:         []string{},
+				TaskManagers:  []string{},
+			}
+			groups[fingerprint] = group
+			fingerprints = append(fingerprints, fingerprint)
+		}
+
+		group.add(entry, concurrent)
+	}
+
+	for _, entry := range history.Entries {
+		add(entry, false)
+
+		for _, concurrentEntry := range entry.ConcurrentExceptions {
+			add(concurrentEntry, true)
+		}
+	}
+
+	for _, fingerprint := range fingerprints {
+		result.Groups = append(result.Groups, *groups[fingerprint])
+	}
+
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		if result.Groups[i].Count != result.Groups[j].Count {
+			return result.Groups[i].Count > result.Groups[j].Count
+		}
+
+		return result.Groups[i].LastSeen.After(result.Groups[j].LastSeen)
+	})
+
+	return result
+}
+
+func (g *ExceptionGroup) add(entry FlinkExceptionEntry, concurrent bool) {
+	g.Count++
+	if concurrent {
+		g.ConcurrentCount++
+	}
+
+	seen := time.UnixMilli(entry.Timestamp).UTC()
+	if g.FirstSeen.IsZero() || seen.Before(g.FirstSeen) {
+		g.FirstSeen = seen
+	}
+
+	if seen.After(g.LastSeen) {
+		g.LastSeen = seen
+		// the example is the latest root exception of the group, concurrent ones only if there is nothing else
+		if !concurrent || g.Count == g.ConcurrentCount {
+			g.Example = entry
+			g.Example.ConcurrentExceptions = nil
+		}
+	}
+
+	g.Tasks = appendUnique(g.Tasks, entry.TaskName)
+
+	taskManager := entry.TaskManagerId
+	if taskManager == "" {
+		taskManager = entry.Endpoint
+	}
+
+	g.TaskManagers = appendUnique(g.TaskManagers, taskManager)
+}
+
+func appendUnique(values []string, value string) []string {
+	if value == "" || slices.Contains(values, value) {
+		return values
+	}
+
+	values = append(values, value)
+	slices.Sort(values)
+
+	return values
+}
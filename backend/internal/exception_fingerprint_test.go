@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestNormalizeStacktrace(t *testing.T) {
+	stacktrace := "org.apache.flink.runtime.JobException: Recovery is suppressed by FixedDelayRestartBackoffTimeStrategy(maxNumberRestartAttempts=3)\n" +
+		"\tat org.apache.flink.runtime.executiongraph.failover.ExecutionFailureHandler.handleFailure(ExecutionFailureHandler.java:180)\n" +
+		"Caused by: java.lang.IllegalStateException: unexpected record 4711\n" +
+		"  with a second line\n" +
+		"\tat com.example.Job$$Lambda$1234/0x0000000800c0b040.map(Unknown Source)\n" +
+		"\tat com.example.Job$3.process(Job.java:87)\n" +
+		"\tat jdk.internal.reflect.GeneratedMethodAccessor42.invoke(Unknown Source)\n" +
+		"\tat java.base/java.lang.Thread.run(Thread.java:829)\n" +
+		"\t... 12 more\n"
+
+	expected := "org.apache.flink.runtime.JobException\n" +
+		"at org.apache.flink.runtime.executiongraph.failover.ExecutionFailureHandler.handleFailure(ExecutionFailureHandler.java)\n" +
+		"Caused by: java.lang.IllegalStateException\n" +
+		"at com.example.Job$$Lambda.map(Unknown Source)\n" +
+		"at com.example.Job$N.process(Job.java)\n" +
+		"at jdk.internal.reflect.GeneratedMethodAccessor.invoke(Unknown Source)\n" +
+		"at java.lang.Thread.run(Thread.java)"
+
+	if normalized := normalizeStacktrace(stacktrace); normalized != expected {
+		t.Fatalf("unexpected normalized stack trace:\n%s", normalized)
+	}
+}
+
+func TestGroupExceptions(t *testing.T) {
+	timeout := func(message string, line int, timestamp int64, taskManager string) FlinkExceptionEntry {
+		return FlinkExceptionEntry{
+			ExceptionName: "java.util.concurrent.TimeoutException",
+			Stacktrace:    "java.util.concurrent.TimeoutException: " + message + "\n\tat com.example.Client.call(Client.java:" + strconv.Itoa(line) + ")\n",
+			Timestamp:     timestamp,
+			TaskName:      "Enrich (1/2)",
+			TaskManagerId: taskManager,
+		}
+	}
+
+	history := &FlinkExceptionHistory{Entries: []FlinkExceptionEntry{
+		timeout("after 30s", 1, 3000, "tm-1"),
+		{
+			ExceptionName: "java.lang.NullPointerException",
+			Stacktrace:    "java.lang.NullPointerException\n\tat com.example.Parser.parse(Parser.java:12)\n",
+			Timestamp:     2000,
+			TaskName:      "Parse (2/2)",
+			TaskManagerId: "tm-2",
+			ConcurrentExceptions: []FlinkExceptionEntry{
+				timeout("after 31s", 2, 2000, "tm-2"),
+			},
+		},
+		timeout("after 29s", 3, 1000, "tm-1"),
+	}}
+
+	groups := groupExceptions(history)
+	if groups.TotalExceptions != 4 || len(groups.Groups) != 2 {
+		t.Fatalf("expected 4 exceptions in 2 groups, got %d in %d", groups.TotalExceptions, len(groups.Groups))
+	}
+
+	group := groups.Groups[0]
+	if group.Count != 3 || group.ConcurrentCount != 1 || group.RootCause != "java.util.concurrent.TimeoutException" {
+		t.Fatalf("unexpected group %+v", group)
+	}
+	if group.FirstSeen.UnixMilli() != 1000 || group.LastSeen.UnixMilli() != 3000 || group.Example.Timestamp != 3000 {
+		t.Fatalf("unexpected first and last seen %s and %s", group.FirstSeen, group.LastSeen)
+	}
+	if len(group.TaskManagers) != 2 || len(group.Tasks) != 1 {
+		t.Fatalf("unexpected task managers %v and tasks %v", group.TaskManagers, group.Tasks)
+	}
+}
+
+func TestRootCauseSectionIgnoresSuppressedCauses(t *testing.T) {
+	stacktrace := "org.apache.flink.runtime.JobException: Recovery is suppressed\n" +
+		"\tat org.apache.flink.runtime.scheduler.DefaultScheduler.handleTaskFailure(DefaultScheduler.java:250)\n" +
+		"Caused by: java.io.IOException: could not flush\n" +
+		"\tat com.example.Sink.flush(Sink.java:51)\n" +
+		"\tSuppressed: java.lang.IllegalStateException: close failed\n" +
+		"\t\tat com.example.Sink.close(Sink.java:70)\n" +
+		"\tCaused by: java.net.SocketTimeoutException: read timed out\n" +
+		"\t\tat java.base/java.net.SocketInputStream.read(SocketInputStream.java:168)\n" +
+		"\t\t... 4 more\n"
+
+	fingerprint, rootCause, normalized := fingerprintStacktrace(stacktrace)
+	if rootCause != "java.io.IOException" {
+		t.Errorf("expected the top level cause as root cause, got %s in\n%s", rootCause, normalized)
+	}
+
+	expected := "Caused by: java.io.IOException\nat com.example.Sink.flush(Sink.java)"
+	if section := rootCauseSection(normalized); section != expected {
+		t.Errorf("unexpected root cause section:\n%s", section)
+	}
+
+	withoutSuppressed, _, _ := fingerprintStacktrace("org.apache.flink.runtime.JobException: Recovery is suppressed\n" +
+		"Caused by: java.io.IOException: could not flush again\n" +
+		"\tat com.example.Sink.flush(Sink.java:52)\n")
+	if fingerprint != withoutSuppressed {
+		t.Errorf("expected suppressed exceptions to not change the fingerprint")
+	}
+}
@@ -0,0 +1,395 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxStoredExceptionLine limits the size of a single stored exception, stack traces of deeply nested causes can get large.
+const maxStoredExceptionLine = 16 * 1024 * 1024
+
+// StoredException is a root exception of the exception history of a job together with its concurrent exceptions
+// as persisted by the exception collector.
+type StoredException struct {
+	Namespace   string              `json:"namespace"`
+	Name        string              `json:"name"`
+	SessionJob  string              `json:"sessionJob,omitempty"`
+	JobId       string              `json:"jobId"`
+	Fingerprint string              `json:"fingerprint"`
+	RootCause   string              `json:"rootCause"`
+	Timestamp   time.Time           `json:"timestamp"`
+	CollectedAt time.Time           `json:"collectedAt"`
+	Exception   FlinkExceptionEntry `json:"exception"`
+}
+
+// exceptionStore persists exceptions as JSON lines in one file per deployment and job below its directory, e.g.
+// <directory>/<namespace>/<name>/<jobId>.jsonl, the session jobs of a session cluster are stored below
+// <directory>/<namespace>/<name>/session-jobs/<sessionJob>. Files are only appended to, except when old exceptions are pruned.
+// Every file has its own lock, so queries only wait for writes to the files they read.
+type exceptionStore struct {
+	lck       sync.Mutex
+	directory string
+	files     map[string]*exceptionFile
+}
+
+type exceptionFile struct {
+	lck sync.RWMutex
+	// seen are the keys of the exceptions stored in the file, loaded when the file is appended to the first time
+	seen map[string]bool
+}
+
+func newExceptionStore(directory string) *exceptionStore {
+	return &exceptionStore{
+		directory: directory,
+		files:     map[string]*exceptionFile{},
+	}
+}
+
+func (s *exceptionStore) file(path string) *exceptionFile {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	file, ok := s.files[path]
+	if !ok {
+		file = &exceptionFile{}
+		s.files[path] = file
+	}
+
+	return file
+}
+
+// exceptionPath returns the components of the directory of a deployment or session job below the store directory.
+func exceptionPath(namespace string, name string, sessionJob string, components ...string) []string {
+	path := []string{namespace, name}
+	if sessionJob != "" {
+		path = append(path, "session-jobs", sessionJob)
+	}
+
+	return append(path, components...)
+}
+
+func storedExceptionKey(timestamp time.Time, fingerprint string) string {
+	return strconv.FormatInt(timestamp.UnixMilli(), 10) + "/" + fingerprint
+}
+
+// Add stores the exceptions of a job which are not stored yet. Exceptions are identified by their timestamp and
+// the fingerprint of their root cause. It returns the number of added exceptions.
+func (s *exceptionStore) Add(namespace string, name string, sessionJob string, jobID string, entries []FlinkExceptionEntry, now time.Time) (added int, err error) {
+	path, err := storagePath(s.directory, exceptionPath(namespace, name, sessionJob, jobID+".jsonl")...)
+	if err != nil {
+		return 0, err
+	}
+
+	stored := s.file(path)
+	stored.lck.Lock()
+	defer stored.lck.Unlock()
+
+	seen, err := stored.loadSeen(path)
+	if err != nil {
+		return 0, err
+	}
+
+	records := make([]StoredException, 0)
+	for _, entry := range entries {
+		record := toStoredException(namespace, name, jobID, entry, now)
+		record.SessionJob = sessionJob
+		key := storedExceptionKey(record.Timestamp, record.Fingerprint)
+
+		if !seen[key] {
+			seen[key] = true
+			records = append(records, record)
+		}
+	}
+
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("could not create exception directory of %s: %w", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("could not open exception file %s: %w", path, err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("could not close exception file %s: %w", path, cerr)
+		}
+	}()
+
+	if err = terminateLastLine(file); err == nil {
+		err = writeStoredExceptions(file, records)
+	}
+
+	if err != nil {
+		// the keys are reloaded from the file on the next call, as it is unknown which records made it to the file
+		stored.seen = nil
+
+		return 0, fmt.Errorf("could not write exception file %s: %w", path, err)
+	}
+
+	return len(records), nil
+}
+
+func toStoredException(namespace string, name string, jobID string, entry FlinkExceptionEntry, now time.Time) StoredException {
+	stacktrace := entry.Stacktrace
+	if stacktrace == "" {
+		stacktrace = entry.ExceptionName
+	}
+
+	fingerprint, rootCause, _ := fingerprintStacktrace(stacktrace)
+
+	return StoredException{
+		Namespace:   namespace,
+		Name:        name,
+		JobId:       jobID,
+		Fingerprint: fingerprint,
+		RootCause:   rootCause,
+		Timestamp:   time.UnixMilli(entry.Timestamp).UTC(),
+		CollectedAt: now,
+		Exception:   entry,
+	}
+}
+
+// terminateLastLine completes the last line of the file if the process died while appending to it,
+// otherwise the first appended exception would end up on the same line as the incomplete one.
+func terminateLastLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	last := make([]byte, 1)
+	if _, err = file.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+
+	if last[0] == '\n' {
+		return nil
+	}
+
+	_, err = file.Write([]byte{'\n'})
+
+	return err
+}
+
+func writeStoredExceptions(file *os.File, records []StoredException) error {
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+func (f *exceptionFile) loadSeen(path string) (map[string]bool, error) {
+	if f.seen != nil {
+		return f.seen, nil
+	}
+
+	records, err := readStoredExceptions(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(records))
+	for _, record := range records {
+		seen[storedExceptionKey(record.Timestamp, record.Fingerprint)] = true
+	}
+
+	f.seen = seen
+
+	return seen, nil
+}
+
+// Query returns the stored exceptions of a deployment or session job in the time range, the latest first. Only the
+// file of the job is read, without a job id the files of all jobs of the deployment or session job are read. A zero
+// from or to leaves the range open on that side.
+func (s *exceptionStore) Query(namespace string, name string, sessionJob string, jobID string, from time.Time, to time.Time) ([]StoredException, error) {
+	dir, err := storagePath(s.directory, exceptionPath(namespace, name, sessionJob)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if jobID == "" {
+		if paths, err = filepath.Glob(filepath.Join(dir, "*.jsonl")); err != nil {
+			return nil, fmt.Errorf("could not list exception files of %s/%s: %w", namespace, name, err)
+		}
+	} else {
+		path, err := storagePath(dir, jobID+".jsonl")
+		if err != nil {
+			return nil, err
+		}
+
+		paths = []string{path}
+	}
+
+	result := make([]StoredException, 0)
+	for _, path := range paths {
+		records, err := s.read(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			if (from.IsZero() || !record.Timestamp.Before(from)) && (to.IsZero() || record.Timestamp.Before(to)) {
+				result = append(result, record)
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+
+	return result, nil
+}
+
+// read reads a file without creating a lock for it, as the paths of queries come from requests. Files without a
+// lock weren't written since the start, a write starting while reading them at most leaves an incomplete last
+// line, which is skipped.
+func (s *exceptionStore) read(path string) ([]StoredException, error) {
+	s.lck.Lock()
+	stored, ok := s.files[path]
+	s.lck.Unlock()
+
+	if !ok {
+		return readStoredExceptions(path)
+	}
+
+	stored.lck.RLock()
+	defer stored.lck.RUnlock()
+
+	return readStoredExceptions(path)
+}
+
+// Prune drops all exceptions older than the given time and removes files which end up empty.
+func (s *exceptionStore) Prune(before time.Time) (pruned int, err error) {
+	paths, err := filepath.Glob(filepath.Join(s.directory, "*", "*", "*.jsonl"))
+	if err != nil {
+		return 0, fmt.Errorf("could not list exception files: %w", err)
+	}
+
+	sessionJobPaths, err := filepath.Glob(filepath.Join(s.directory, "*", "*", "session-jobs", "*", "*.jsonl"))
+	if err != nil {
+		return 0, fmt.Errorf("could not list exception files of session jobs: %w", err)
+	}
+
+	paths = append(paths, sessionJobPaths...)
+
+	for _, path := range paths {
+		count, err := s.prunePath(path, before)
+		if err != nil {
+			return pruned, err
+		}
+
+		pruned += count
+	}
+
+	return pruned, nil
+}
+
+func (s *exceptionStore) prunePath(path string, before time.Time) (int, error) {
+	stored := s.file(path)
+	stored.lck.Lock()
+	defer stored.lck.Unlock()
+
+	records, err := readStoredExceptions(path)
+	if err != nil {
+		return 0, err
+	}
+
+	kept := make([]StoredException, 0, len(records))
+	for _, record := range records {
+		if !record.Timestamp.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+
+	if len(kept) == len(records) {
+		return 0, nil
+	}
+
+	stored.seen = nil
+
+	if len(kept) == 0 {
+		if err = os.Remove(path); err != nil {
+			return 0, fmt.Errorf("could not remove exception file %s: %w", path, err)
+		}
+
+		return len(records), nil
+	}
+
+	if err = rewriteStoredExceptions(path, kept); err != nil {
+		return 0, err
+	}
+
+	return len(records) - len(kept), nil
+}
+
+// rewriteStoredExceptions replaces the file atomically, so a crash while pruning doesn't lose the kept exceptions.
+func rewriteStoredExceptions(path string, records []StoredException) error {
+	return writeFileAtomic(path, func(file *os.File) error {
+		return writeStoredExceptions(file, records)
+	})
+}
+
+// readStoredExceptions reads all exceptions of a file. Lines which can't be decoded are skipped, as the last line
+// is incomplete if the process died while appending to the file.
+func readStoredExceptions(path string) (records []StoredException, err error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not open exception file %s: %w", path, err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("could not close exception file %s: %w", path, cerr)
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStoredExceptionLine)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record StoredException
+		if json.Unmarshal([]byte(line), &record) == nil {
+			records = append(records, record)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read exception file %s: %w", path, err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExceptionStore(t *testing.T) {
+	directory := t.TempDir()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	entry := func(message string, timestamp time.Time) FlinkExceptionEntry {
+		return FlinkExceptionEntry{
+			ExceptionName: "java.io.IOException",
+			Stacktrace:    "java.io.IOException: " + message + "\n\tat com.example.Sink.write(Sink.java:12)\n",
+			Timestamp:     timestamp.UnixMilli(),
+		}
+	}
+
+	first := entry("connection reset", now.Add(-2*time.Hour))
+	second := entry("broken pipe", now.Add(-time.Hour))
+
+	store := newExceptionStore(directory)
+	if added, err := store.Add("flink", "orders", "", "job-a", []FlinkExceptionEntry{first, second}, now); err != nil || added != 2 {
+		t.Fatalf("expected 2 added exceptions, got %d: %v", added, err)
+	}
+
+	// a new store has to load the already stored exceptions from the file to deduplicate them
+	store = newExceptionStore(directory)
+	if added, err := store.Add("flink", "orders", "", "job-a", []FlinkExceptionEntry{second, entry("timeout", now)}, now); err != nil || added != 1 {
+		t.Fatalf("expected 1 added exception, got %d: %v", added, err)
+	}
+
+	if _, err := store.Add("flink", "orders", "", "job-b", []FlinkExceptionEntry{entry("refused", now.Add(-30*time.Minute))}, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the session jobs of a session cluster keep their exceptions apart from the ones of the cluster
+	if _, err := store.Add("flink", "orders", "enrichment", "job-c", []FlinkExceptionEntry{entry("refused", now)}, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sessionJob, err := store.Query("flink", "orders", "enrichment", "", time.Time{}, time.Time{})
+	if err != nil || len(sessionJob) != 1 || sessionJob[0].SessionJob != "enrichment" || sessionJob[0].JobId != "job-c" {
+		t.Fatalf("unexpected exceptions of the session job: %+v, %v", sessionJob, err)
+	}
+
+	all, err := store.Query("flink", "orders", "", "", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(all) != 4 || all[0].JobId != "job-a" || all[1].JobId != "job-b" || !all[3].Timestamp.Equal(now.Add(-2*time.Hour)) {
+		t.Fatalf("unexpected exceptions: %+v", all)
+	}
+
+	ranged, err := store.Query("flink", "orders", "", "job-a", now.Add(-90*time.Minute), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ranged) != 1 || ranged[0].Exception.Stacktrace != second.Stacktrace {
+		t.Fatalf("unexpected exceptions in range: %+v", ranged)
+	}
+
+	pruned, err := store.Prune(now.Add(-45 * time.Minute))
+	if err != nil || pruned != 2 {
+		t.Fatalf("expected 2 pruned exceptions, got %d: %v", pruned, err)
+	}
+
+	if _, err = os.Stat(filepath.Join(directory, "flink", "orders", "job-b.jsonl")); err != nil {
+		t.Fatalf("expected the file of job-b to be kept: %v", err)
+	}
+
+	if _, err = store.Query("flink", "..", "", "", time.Time{}, time.Time{}); !errors.Is(err, errInvalidStoragePath) {
+		t.Fatalf("expected an invalid path error, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FlameGraph is the normalized flame graph of a vertex. Root is the synthetic root frame of all samples.
+type FlameGraph struct {
+	VertexId     string          `json:"vertexId"`
+	Type         string          `json:"type"`
+	Subtask      *int            `json:"subtask,omitempty"`
+	EndTimestamp int64           `json:"endTimestamp"`
+	TotalSamples int64           `json:"totalSamples"`
+	MaxDepth     int             `json:"maxDepth"`
+	Root         *FlameGraphNode `json:"root"`
+}
+
+// FlameGraphNode is a frame of the flame graph. Value is the number of samples containing the frame and Self the
+// number of samples in which it was the top most frame. Children are sorted by their value, the largest first.
+type FlameGraphNode struct {
+	Name     string            `json:"name"`
+	Value    int64             `json:"value"`
+	Self     int64             `json:"self"`
+	Children []*FlameGraphNode `json:"children,omitempty"`
+}
+
+func toFlameGraph(vertexID string, flameGraphType string, subtask *int, flinkFlameGraph *FlinkFlameGraph) *FlameGraph {
+	flameGraph := &FlameGraph{
+		VertexId:     vertexID,
+		Type:         flameGraphType,
+		Subtask:      subtask,
+		EndTimestamp: flinkFlameGraph.EndTimestamp,
+		Root:         &FlameGraphNode{Name: "root"},
+	}
+
+	if flinkFlameGraph.Data != nil {
+		flameGraph.Root = toFlameGraphNode(flinkFlameGraph.Data, 0, &flameGraph.MaxDepth)
+	}
+
+	flameGraph.TotalSamples = flameGraph.Root.Value
+
+	return flameGraph
+}
+
+// toFlameGraphNode copies the frame and its children and derives the self value of the frame.
+func toFlameGraphNode(node *FlinkFlameGraphNode, depth int, maxDepth *int) *FlameGraphNode {
+	*maxDepth = max(*maxDepth, depth)
+
+	result := &FlameGraphNode{
+		Name:     node.Name,
+		Value:    node.Value,
+		Children: make([]*FlameGraphNode, 0, len(node.Children)),
+	}
+
+	var childValue int64
+	for _, child := range node.Children {
+		normalized := toFlameGraphNode(child, depth+1, maxDepth)
+		childValue += normalized.Value
+		result.Children = append(result.Children, normalized)
+	}
+
+	sort.SliceStable(result.Children, func(i, j int) bool {
+		return result.Children[i].Value > result.Children[j].Value
+	})
+
+	result.Self = max(result.Value-childValue, 0)
+
+	return result
+}
+
+// Collapsed renders the flame graph in the collapsed stack format of flamegraph.pl and similar tools: one line
+// per stack with its frames from the bottom to the top separated by semicolons, followed by the number of samples.
+// The synthetic root frame is omitted.
+func (g *FlameGraph) Collapsed() string {
+	builder := &strings.Builder{}
+	for _, child := range g.Root.Children {
+		writeCollapsedStacks(builder, child, nil)
+	}
+
+	return builder.String()
+}
+
+func writeCollapsedStacks(builder *strings.Builder, node *FlameGraphNode, stack []string) {
+	// frames may contain spaces but not semicolons, as these separate the frames
+	stack = append(stack, strings.ReplaceAll(node.Name, ";", ":"))
+
+	if node.Self > 0 {
+		fmt.Fprintf(builder, "%s %d\n", strings.Join(stack, ";"), node.Self)
+	}
+
+	for _, child := range node.Children {
+		writeCollapsedStacks(builder, child, stack)
+	}
+}
@@ -0,0 +1,131 @@
+package internal
+
+import "testing"
+
+func TestToFlameGraph(t *testing.T) {
+	subtask := 1
+	flinkFlameGraph := &FlinkFlameGraph{
+		EndTimestamp: 1700000000000,
+		Data: &FlinkFlameGraphNode{
+			Name:  "root",
+			Value: 10,
+			Children: []*FlinkFlameGraphNode{
+				{Name: "Thread.run", Value: 3},
+				{
+					Name:  "StreamTask.invoke",
+					Value: 7,
+					Children: []*FlinkFlameGraphNode{
+						{Name: "Map.map", Value: 2},
+						{Name: "Window.process", Value: 4, Children: []*FlinkFlameGraphNode{{Name: "HashMap.get", Value: 4}}},
+					},
+				},
+			},
+		},
+	}
+
+	flameGraph := toFlameGraph("vertex", FlameGraphTypeOnCpu, &subtask, flinkFlameGraph)
+
+	if flameGraph.VertexId != "vertex" || flameGraph.Type != FlameGraphTypeOnCpu || *flameGraph.Subtask != 1 || flameGraph.EndTimestamp != 1700000000000 {
+		t.Errorf("unexpected flame graph %+v", flameGraph)
+	}
+
+	if flameGraph.TotalSamples != 10 || flameGraph.MaxDepth != 3 {
+		t.Errorf("expected 10 samples with a depth of 3, got %d and %d", flameGraph.TotalSamples, flameGraph.MaxDepth)
+	}
+
+	// children are sorted by their value, the largest first
+	invoke := flameGraph.Root.Children[0]
+	if invoke.Name != "StreamTask.invoke" || invoke.Children[0].Name != "Window.process" || flameGraph.Root.Children[1].Name != "Thread.run" {
+		t.Fatalf("expected the children to be sorted by value, got %+v", flameGraph.Root.Children)
+	}
+
+	selfValues := map[string]int64{
+		"root":              0,
+		"Thread.run":        3,
+		"StreamTask.invoke": 1,
+		"Window.process":    0,
+		"HashMap.get":       4,
+		"Map.map":           2,
+	}
+
+	var check func(node *FlameGraphNode)
+	check = func(node *FlameGraphNode) {
+		if node.Self != selfValues[node.Name] {
+			t.Errorf("expected a self value of %d for %s, got %d", selfValues[node.Name], node.Name, node.Self)
+		}
+
+		for _, child := range node.Children {
+			check(child)
+		}
+	}
+	check(flameGraph.Root)
+}
+
+func TestToFlameGraphSelfValue(t *testing.T) {
+	cases := map[string]struct {
+		node     *FlinkFlameGraphNode
+		expected int64
+	}{
+		"leaf": {
+			node:     &FlinkFlameGraphNode{Name: "leaf", Value: 5},
+			expected: 5,
+		},
+		"children cover all samples": {
+			node:     &FlinkFlameGraphNode{Name: "parent", Value: 5, Children: []*FlinkFlameGraphNode{{Name: "a", Value: 2}, {Name: "b", Value: 3}}},
+			expected: 0,
+		},
+		"children exceed the samples": {
+			node:     &FlinkFlameGraphNode{Name: "parent", Value: 2, Children: []*FlinkFlameGraphNode{{Name: "a", Value: 2}, {Name: "b", Value: 3}}},
+			expected: 0,
+		},
+	}
+
+	for name, tc := range cases {
+		flameGraph := toFlameGraph("vertex", FlameGraphTypeMixed, nil, &FlinkFlameGraph{Data: tc.node})
+		if flameGraph.Root.Self != tc.expected {
+			t.Errorf("%s: expected a self value of %d, got %d", name, tc.expected, flameGraph.Root.Self)
+		}
+	}
+}
+
+func TestToFlameGraphWithoutData(t *testing.T) {
+	flameGraph := toFlameGraph("vertex", FlameGraphTypeOffCpu, nil, &FlinkFlameGraph{EndTimestamp: flinkFlameGraphWaiting})
+
+	if flameGraph.Root == nil || flameGraph.Root.Name != "root" || flameGraph.TotalSamples != 0 || flameGraph.MaxDepth != 0 {
+		t.Errorf("expected an empty flame graph, got %+v", flameGraph)
+	}
+
+	if collapsed := flameGraph.Collapsed(); collapsed != "" {
+		t.Errorf("expected no collapsed stacks, got %q", collapsed)
+	}
+}
+
+func TestFlameGraphCollapsed(t *testing.T) {
+	flameGraph := toFlameGraph("vertex", FlameGraphTypeOnCpu, nil, &FlinkFlameGraph{
+		Data: &FlinkFlameGraphNode{
+			Name:  "root",
+			Value: 9,
+			Children: []*FlinkFlameGraphNode{
+				{Name: "Thread.run", Value: 2},
+				{
+					Name:  "StreamTask.invoke",
+					Value: 7,
+					Children: []*FlinkFlameGraphNode{
+						{Name: "Map.map", Value: 2},
+						{Name: "Lambda$1;apply", Value: 4},
+					},
+				},
+			},
+		},
+	})
+
+	// the root is omitted, frames without self samples have no line and semicolons in names are replaced
+	expected := "StreamTask.invoke 1\n" +
+		"StreamTask.invoke;Lambda$1:apply 4\n" +
+		"StreamTask.invoke;Map.map 2\n" +
+		"Thread.run 2\n"
+
+	if collapsed := flameGraph.Collapsed(); collapsed != expected {
+		t.Errorf("expected collapsed stacks\n%s\ngot\n%s", expected, collapsed)
+	}
+}
@@ -0,0 +1,31 @@
+package internal
+
+// FlinkAccumulator is an accumulator as returned by the accumulator endpoints. The value is the string
+// representation of the accumulator, e.g. the count of a LongCounter.
+type FlinkAccumulator struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// FlinkJobAccumulators is the response from GET /jobs/:jobid/accumulators. The user task accumulators are
+// already merged over all tasks of the job.
+type FlinkJobAccumulators struct {
+	JobAccumulators      []FlinkAccumulator `json:"job-accumulators"`
+	UserTaskAccumulators []FlinkAccumulator `json:"user-task-accumulators"`
+}
+
+// FlinkSubtasksAccumulators is the response from GET /jobs/:jobid/vertices/:vertexid/subtasks/accumulators
+type FlinkSubtasksAccumulators struct {
+	Id          string                     `json:"id"`
+	Parallelism int                        `json:"parallelism"`
+	Subtasks    []FlinkSubtaskAccumulators `json:"subtasks"`
+}
+
+// FlinkSubtaskAccumulators are the user accumulators of the current attempt of a subtask.
+type FlinkSubtaskAccumulators struct {
+	Subtask          int                `json:"subtask"`
+	Attempt          int                `json:"attempt"`
+	Endpoint         string             `json:"endpoint"`
+	UserAccumulators []FlinkAccumulator `json:"user-accumulators"`
+}
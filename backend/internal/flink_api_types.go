@@ -1,6 +1,7 @@
 package internal
 
-// FlinkCheckpointStatistics represents checkpoint statistics from /jobs/:jobid/checkpoints
+// FlinkCheckpointStatistics represents checkpoint statistics from /jobs/:jobid/checkpoints. It is decoded by
+// decodeCheckpointStatistics, which maps the responses of all supported Flink versions onto it.
 type FlinkCheckpointStatistics struct {
 	Counts   FlinkCheckpointCounts    `json:"counts"`
 	Summary  FlinkCheckpointSummary   `json:"summary"`
@@ -20,6 +21,7 @@ type FlinkCheckpointCounts struct {
 
 // FlinkCheckpointSummary contains summary statistics for checkpoints
 type FlinkCheckpointSummary struct {
+	CheckpointedSize  FlinkCheckpointSummaryStats `json:"checkpointed_size"`
 	StateSize         FlinkCheckpointSummaryStats `json:"state_size"`
 	EndToEndDuration  FlinkCheckpointSummaryStats `json:"end_to_end_duration"`
 	AlignmentBuffered FlinkCheckpointSummaryStats `json:"alignment_buffered"`
@@ -41,6 +43,7 @@ type FlinkCheckpointDetail struct {
 	IsSavepoint             bool   `json:"is_savepoint"`
 	TriggerTimestamp        int64  `json:"trigger_timestamp"`
 	LatestAckTimestamp      int64  `json:"latest_ack_timestamp"`
+	CheckpointedSize        int64  `json:"checkpointed_size"`
 	StateSize               int64  `json:"state_size"`
 	EndToEndDuration        int64  `json:"end_to_end_duration"`
 	AlignmentBuffered       int64  `json:"alignment_buffered"`
@@ -60,3 +63,85 @@ type FlinkRestoredCheckpoint struct {
 	IsSavepoint      bool   `json:"is_savepoint"`
 	ExternalPath     string `json:"external_path"`
 }
+
+// FlinkCheckpointDetails is the response from GET /jobs/:jobid/checkpoints/details/:checkpointid
+type FlinkCheckpointDetails struct {
+	FlinkCheckpointDetail
+	FailureTimestamp int64                                    `json:"failure_timestamp,omitempty"`
+	FailureMessage   string                                   `json:"failure_message,omitempty"`
+	Tasks            map[string]FlinkTaskCheckpointStatistics `json:"tasks"`
+}
+
+// FlinkTaskCheckpointStatistics are the checkpoint statistics of a single vertex summed over its subtasks.
+type FlinkTaskCheckpointStatistics struct {
+	Id                      int64  `json:"id"`
+	Status                  string `json:"status"`
+	LatestAckTimestamp      int64  `json:"latest_ack_timestamp"`
+	CheckpointedSize        int64  `json:"checkpointed_size"`
+	StateSize               int64  `json:"state_size"`
+	EndToEndDuration        int64  `json:"end_to_end_duration"`
+	AlignmentBuffered       int64  `json:"alignment_buffered"`
+	ProcessedData           int64  `json:"processed_data"`
+	PersistedData           int64  `json:"persisted_data"`
+	NumSubtasks             int    `json:"num_subtasks"`
+	NumAcknowledgedSubtasks int    `json:"num_acknowledged_subtasks"`
+}
+
+// FlinkTaskCheckpointDetails is the response from GET /jobs/:jobid/checkpoints/details/:checkpointid/subtasks/:vertexid
+type FlinkTaskCheckpointDetails struct {
+	FlinkTaskCheckpointStatistics
+	Subtasks []FlinkSubtaskCheckpointStatistics `json:"subtasks"`
+}
+
+// FlinkSubtaskCheckpointStatistics are the checkpoint statistics of a single subtask. Subtasks which did not
+// acknowledge the checkpoint yet only have the index and the status "pending_or_failed".
+type FlinkSubtaskCheckpointStatistics struct {
+	Index               int                             `json:"index"`
+	Status              string                          `json:"status"`
+	AckTimestamp        int64                           `json:"ack_timestamp"`
+	EndToEndDuration    int64                           `json:"end_to_end_duration"`
+	StateSize           int64                           `json:"state_size"`
+	CheckpointedSize    int64                           `json:"checkpointed_size"`
+	Checkpoint          FlinkSubtaskCheckpointDuration  `json:"checkpoint"`
+	Alignment           FlinkSubtaskCheckpointAlignment `json:"alignment"`
+	StartDelay          int64                           `json:"start_delay"`
+	UnalignedCheckpoint bool                            `json:"unaligned_checkpoint"`
+	Aborted             bool                            `json:"aborted"`
+}
+
+// FlinkSubtaskCheckpointDuration are the durations of the synchronous and asynchronous part of a subtask checkpoint.
+type FlinkSubtaskCheckpointDuration struct {
+	Sync  int64 `json:"sync"`
+	Async int64 `json:"async"`
+}
+
+// FlinkSubtaskCheckpointAlignment describes the barrier alignment of a subtask.
+type FlinkSubtaskCheckpointAlignment struct {
+	Buffered  int64 `json:"buffered"`
+	Processed int64 `json:"processed"`
+	Persisted int64 `json:"persisted"`
+	Duration  int64 `json:"duration"`
+}
+
+// FlinkCheckpointConfig is the effective checkpoint configuration of a job as returned by GET /jobs/:jobid/checkpoints/config
+type FlinkCheckpointConfig struct {
+	Mode                        string                         `json:"mode"`
+	Interval                    int64                          `json:"interval"`
+	Timeout                     int64                          `json:"timeout"`
+	MinPause                    int64                          `json:"min_pause"`
+	MaxConcurrent               int                            `json:"max_concurrent"`
+	Externalization             FlinkCheckpointExternalization `json:"externalization"`
+	StateBackend                string                         `json:"state_backend"`
+	CheckpointStorage           string                         `json:"checkpoint_storage"`
+	UnalignedCheckpoints        bool                           `json:"unaligned_checkpoints"`
+	TolerableFailedCheckpoints  int                            `json:"tolerable_failed_checkpoints"`
+	AlignedCheckpointTimeout    int64                          `json:"aligned_checkpoint_timeout"`
+	CheckpointsAfterTasksFinish bool                           `json:"checkpoints_after_tasks_finish"`
+	StateChangelogEnabled       bool                           `json:"state_changelog_enabled"`
+}
+
+// FlinkCheckpointExternalization describes whether checkpoints are retained when the job is cancelled.
+type FlinkCheckpointExternalization struct {
+	Enabled              bool `json:"enabled"`
+	DeleteOnCancellation bool `json:"delete_on_cancellation"`
+}
@@ -0,0 +1,18 @@
+package internal
+
+// FlinkVertexBackpressure is the response from GET /jobs/:jobid/vertices/:vertexid/backpressure
+type FlinkVertexBackpressure struct {
+	Status            string                     `json:"status"`
+	BackpressureLevel string                     `json:"backpressureLevel"`
+	EndTimestamp      int64                      `json:"end-timestamp"`
+	Subtasks          []FlinkSubtaskBackpressure `json:"subtasks"`
+}
+
+// FlinkSubtaskBackpressure contains the backpressure ratios of a single subtask.
+type FlinkSubtaskBackpressure struct {
+	Subtask           int     `json:"subtask"`
+	BackpressureLevel string  `json:"backpressureLevel"`
+	Ratio             float64 `json:"ratio"`
+	IdleRatio         float64 `json:"idleRatio"`
+	BusyRatio         float64 `json:"busyRatio"`
+}
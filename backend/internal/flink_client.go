@@ -1,70 +1,650 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/justtrackio/gosoline/pkg/appctx"
 	"github.com/justtrackio/gosoline/pkg/cfg"
 	"github.com/justtrackio/gosoline/pkg/log"
+	"golang.org/x/sync/singleflight"
 )
 
 type flinkCtxKey struct{}
 
 func ProvideFlinkClient(ctx context.Context, config cfg.Config, logger log.Logger) (*FlinkClient, error) {
 	return appctx.Provide(ctx, flinkCtxKey{}, func() (*FlinkClient, error) {
+		settings := &FlinkClientSettings{}
+		if err := config.UnmarshalKey("flink.client", settings); err != nil {
+			return nil, fmt.Errorf("could not unmarshal flink client settings: %w", err)
+		}
+
+		resolver, err := ProvideFlinkEndpointResolver(ctx, config, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize flink endpoint resolver: %w", err)
+		}
+
 		httpClient := &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   settings.Timeout,
+			Transport: resolver.Transport(),
 		}
 
 		return &FlinkClient{
 			httpClient: httpClient,
-			logger:     logger.WithChannel("flink_client"),
+			// streamed responses like log files can take longer than the timeout of the http client,
+			// so they are only bound by the context of the request
+			streamClient: &http.Client{Transport: resolver.Transport()},
+			logger:       logger.WithChannel("flink_client"),
+			settings:     settings,
+			breakers:     map[string]*circuitBreaker{},
+			versions:     map[string]clusterVersion{},
+			cache:        &responseCache{ttl: settings.CacheTtl, entries: map[string]cachedResponse{}},
 		}, nil
 	})
 }
 
+// FlinkClient calls the REST API of Flink clusters. GET requests are retried if the cluster is unavailable,
+// identical concurrent GET requests are coalesced and their responses are cached for a short time. Every cluster
+// has its own circuit breaker, so a dead job manager fails requests fast instead of tying up the handlers.
+// Responses whose shape differs between Flink versions are decoded according to the version of the cluster.
 type FlinkClient struct {
-	httpClient *http.Client
-	logger     log.Logger
+	httpClient   *http.Client
+	streamClient *http.Client
+	logger       log.Logger
+	settings     *FlinkClientSettings
+	requests     singleflight.Group
+	cache        *responseCache
+	lck          sync.Mutex
+	breakers     map[string]*circuitBreaker
+	versions     map[string]clusterVersion
+}
+
+// SetClusterVersion records the version of a cluster as reported by the operator, which saves detecting it. A
+// version known already is kept until its ttl passed, so a cluster is only looked at once per ttl.
+func (c *FlinkClient) SetClusterVersion(clusterURL string, version string) {
+	c.lck.Lock()
+	defer c.lck.Unlock()
+
+	if known, ok := c.versions[clusterURL]; ok && time.Since(known.detectedAt) < c.settings.VersionTtl {
+		return
+	}
+
+	if parsed, ok := ParseFlinkVersion(version); ok {
+		c.versions[clusterURL] = clusterVersion{version: parsed, detectedAt: time.Now()}
+	}
+}
+
+// GetClusterVersion returns the version of a cluster, which is detected from the /config endpoint if it is not
+// known yet. The version is unknown if the detection fails, responses are then decoded tolerating all versions.
+func (c *FlinkClient) GetClusterVersion(ctx context.Context, clusterURL string) FlinkVersion {
+	c.lck.Lock()
+	known, ok := c.versions[clusterURL]
+	c.lck.Unlock()
+
+	if ok && time.Since(known.detectedAt) < c.settings.VersionTtl {
+		return known.version
+	}
+
+	// a failed detection keeps the last known version until the ttl passed again instead of retrying on every request
+	version := known.version
+
+	var config FlinkDashboardConfig
+	if err := c.get(ctx, clusterURL, "/config", &config); err != nil {
+		c.logger.Warn(ctx, "could not detect the flink version of %s: %s", clusterURL, err)
+	} else if parsed, ok := ParseFlinkVersion(config.FlinkVersion); ok {
+		version = parsed
+	} else {
+		c.logger.Warn(ctx, "could not parse the flink version %q of %s", config.FlinkVersion, clusterURL)
+	}
+
+	c.lck.Lock()
+	defer c.lck.Unlock()
+
+	c.versions[clusterURL] = clusterVersion{version: version, detectedAt: time.Now()}
+
+	return version
 }
 
 // GetCheckpoints fetches checkpoint statistics from /jobs/:jobid/checkpoints endpoint
 func (c *FlinkClient) GetCheckpoints(ctx context.Context, clusterURL string, jobID string) (*FlinkCheckpointStatistics, error) {
-	var stats FlinkCheckpointStatistics
-	if err := c.get(ctx, clusterURL+"/jobs/"+jobID+"/checkpoints", &stats); err != nil {
+	body, err := c.getBody(ctx, clusterURL, "/jobs/"+jobID+"/checkpoints")
+	if err != nil {
 		return nil, fmt.Errorf("could not get checkpoints: %w", err)
 	}
 
-	return &stats, nil
+	stats, err := decodeCheckpointStatistics(body, c.GetClusterVersion(ctx, clusterURL))
+	if err != nil {
+		return nil, fmt.Errorf("could not get checkpoints: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetCheckpointConfig fetches the effective checkpoint configuration of a job from /jobs/:jobid/checkpoints/config endpoint
+func (c *FlinkClient) GetCheckpointConfig(ctx context.Context, clusterURL string, jobID string) (*FlinkCheckpointConfig, error) {
+	body, err := c.getBody(ctx, clusterURL, "/jobs/"+jobID+"/checkpoints/config")
+	if err != nil {
+		return nil, fmt.Errorf("could not get checkpoint config: %w", err)
+	}
+
+	config, err := decodeCheckpointConfig(body)
+	if err != nil {
+		return nil, fmt.Errorf("could not get checkpoint config: %w", err)
+	}
+
+	return config, nil
+}
+
+// GetCheckpointDetails fetches the per vertex statistics of a checkpoint from /jobs/:jobid/checkpoints/details/:checkpointid endpoint
+func (c *FlinkClient) GetCheckpointDetails(ctx context.Context, clusterURL string, jobID string, checkpointID int64) (*FlinkCheckpointDetails, error) {
+	body, err := c.getBody(ctx, clusterURL, "/jobs/"+jobID+"/checkpoints/details/"+strconv.FormatInt(checkpointID, 10))
+	if err != nil {
+		return nil, fmt.Errorf("could not get checkpoint details: %w", err)
+	}
+
+	details, err := decodeCheckpointDetails(body, c.GetClusterVersion(ctx, clusterURL))
+	if err != nil {
+		return nil, fmt.Errorf("could not get checkpoint details: %w", err)
+	}
+
+	return details, nil
 }
 
-// GetExceptions fetches the job exception history from /jobs/:jobid/exceptions endpoint
-func (c *FlinkClient) GetExceptions(ctx context.Context, clusterURL string, jobID string) (*FlinkJobExceptions, error) {
-	var exceptions FlinkJobExceptions
-	if err := c.get(ctx, clusterURL+"/jobs/"+jobID+"/exceptions?maxExceptions=50", &exceptions); err != nil {
+// GetCheckpointSubtasks fetches the per subtask statistics of a checkpoint for a vertex from
+// /jobs/:jobid/checkpoints/details/:checkpointid/subtasks/:vertexid endpoint
+func (c *FlinkClient) GetCheckpointSubtasks(ctx context.Context, clusterURL string, jobID string, checkpointID int64, vertexID string) (*FlinkTaskCheckpointDetails, error) {
+	body, err := c.getBody(ctx, clusterURL, "/jobs/"+jobID+"/checkpoints/details/"+strconv.FormatInt(checkpointID, 10)+"/subtasks/"+url.PathEscape(vertexID))
+	if err != nil {
+		return nil, fmt.Errorf("could not get checkpoint subtasks: %w", err)
+	}
+
+	details, err := decodeTaskCheckpointDetails(body, c.GetClusterVersion(ctx, clusterURL))
+	if err != nil {
+		return nil, fmt.Errorf("could not get checkpoint subtasks: %w", err)
+	}
+
+	return details, nil
+}
+
+// GetExceptions fetches the latest entries of the job exception history from /jobs/:jobid/exceptions endpoint
+func (c *FlinkClient) GetExceptions(ctx context.Context, clusterURL string, jobID string, maxExceptions int) (*FlinkJobExceptions, error) {
+	body, err := c.getBody(ctx, clusterURL, "/jobs/"+jobID+"/exceptions?maxExceptions="+strconv.Itoa(maxExceptions))
+	if err != nil {
+		return nil, fmt.Errorf("could not get exceptions: %w", err)
+	}
+
+	exceptions, err := decodeJobExceptions(body, c.GetClusterVersion(ctx, clusterURL))
+	if err != nil {
 		return nil, fmt.Errorf("could not get exceptions: %w", err)
 	}
 
-	return &exceptions, nil
+	return exceptions, nil
 }
 
-// get is a helper method for GET requests with JSON response
-func (c *FlinkClient) get(ctx context.Context, url string, target any) (err error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+// GetJob fetches the job details including the vertices and their IO metrics from /jobs/:jobid endpoint
+func (c *FlinkClient) GetJob(ctx context.Context, clusterURL string, jobID string) (*FlinkJobDetails, error) {
+	body, err := c.getBody(ctx, clusterURL, "/jobs/"+jobID)
 	if err != nil {
-		return fmt.Errorf("could not create request: %w", err)
+		return nil, fmt.Errorf("could not get job: %w", err)
+	}
+
+	details, err := decodeJobDetails(body)
+	if err != nil {
+		return nil, fmt.Errorf("could not get job: %w", err)
+	}
+
+	return details, nil
+}
+
+// GetJobPlan fetches the dataflow plan of a job from /jobs/:jobid/plan endpoint
+func (c *FlinkClient) GetJobPlan(ctx context.Context, clusterURL string, jobID string) (*FlinkJobPlan, error) {
+	var response FlinkJobPlanResponse
+	if err := c.get(ctx, clusterURL, "/jobs/"+jobID+"/plan", &response); err != nil {
+		return nil, fmt.Errorf("could not get job plan: %w", err)
+	}
+
+	return &response.Plan, nil
+}
+
+// GetJobConfig fetches the execution config and the global job parameters of a job from /jobs/:jobid/config endpoint
+func (c *FlinkClient) GetJobConfig(ctx context.Context, clusterURL string, jobID string) (*FlinkJobConfig, error) {
+	var config FlinkJobConfig
+	if err := c.get(ctx, clusterURL, "/jobs/"+jobID+"/config", &config); err != nil {
+		return nil, fmt.Errorf("could not get job config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// GetJobAccumulators fetches the accumulators of a job merged over all tasks from /jobs/:jobid/accumulators endpoint
+func (c *FlinkClient) GetJobAccumulators(ctx context.Context, clusterURL string, jobID string) (*FlinkJobAccumulators, error) {
+	var accumulators FlinkJobAccumulators
+	if err := c.get(ctx, clusterURL, "/jobs/"+jobID+"/accumulators", &accumulators); err != nil {
+		return nil, fmt.Errorf("could not get job accumulators: %w", err)
+	}
+
+	return &accumulators, nil
+}
+
+// GetSubtaskAccumulators fetches the user accumulators of all subtasks of a vertex from /jobs/:jobid/vertices/:vertexid/subtasks/accumulators endpoint
+func (c *FlinkClient) GetSubtaskAccumulators(ctx context.Context, clusterURL string, jobID string, vertexID string) (*FlinkSubtasksAccumulators, error) {
+	var accumulators FlinkSubtasksAccumulators
+	if err := c.get(ctx, clusterURL, "/jobs/"+jobID+"/vertices/"+url.PathEscape(vertexID)+"/subtasks/accumulators", &accumulators); err != nil {
+		return nil, fmt.Errorf("could not get subtask accumulators: %w", err)
+	}
+
+	return &accumulators, nil
+}
+
+// GetVertexBackpressure fetches the backpressure of all subtasks of a vertex from /jobs/:jobid/vertices/:vertexid/backpressure endpoint
+func (c *FlinkClient) GetVertexBackpressure(ctx context.Context, clusterURL string, jobID string, vertexID string) (*FlinkVertexBackpressure, error) {
+	var backpressure FlinkVertexBackpressure
+	if err := c.get(ctx, clusterURL, "/jobs/"+jobID+"/vertices/"+url.PathEscape(vertexID)+"/backpressure", &backpressure); err != nil {
+		return nil, fmt.Errorf("could not get vertex backpressure: %w", err)
+	}
+
+	return &backpressure, nil
+}
+
+// GetAggregatedSubtaskMetrics fetches metrics aggregated over all subtasks of a vertex from /jobs/:jobid/vertices/:vertexid/subtasks/metrics endpoint
+func (c *FlinkClient) GetAggregatedSubtaskMetrics(ctx context.Context, clusterURL string, jobID string, vertexID string, metrics []string) ([]FlinkMetric, error) {
+	names, requested := translateMetricNames(metrics, c.GetClusterVersion(ctx, clusterURL))
+
+	var aggregated []FlinkMetric
+	if err := c.get(ctx, clusterURL, "/jobs/"+jobID+"/vertices/"+url.PathEscape(vertexID)+"/subtasks/metrics?get="+metricsQuery(names), &aggregated); err != nil {
+		return nil, fmt.Errorf("could not get subtask metrics: %w", err)
+	}
+
+	return renameMetrics(aggregated, requested), nil
+}
+
+// GetVertexWatermarks fetches the current input watermark of all subtasks of a vertex from /jobs/:jobid/vertices/:vertexid/watermarks endpoint
+func (c *FlinkClient) GetVertexWatermarks(ctx context.Context, clusterURL string, jobID string, vertexID string) ([]FlinkMetric, error) {
+	var watermarks []FlinkMetric
+	if err := c.get(ctx, clusterURL, "/jobs/"+jobID+"/vertices/"+url.PathEscape(vertexID)+"/watermarks", &watermarks); err != nil {
+		return nil, fmt.Errorf("could not get vertex watermarks: %w", err)
+	}
+
+	return watermarks, nil
+}
+
+// GetMetrics fetches metrics of a job, vertex, subtask, task manager or job manager from the metric endpoint of the scope.
+// Aggregations are only applied by the aggregating endpoints, which default to all of them if none are given.
+// Without metric names, the ids of all available metrics are returned. Metrics Flink renamed are requested by the
+// name the version of the cluster knows and returned by the requested name.
+func (c *FlinkClient) GetMetrics(ctx context.Context, clusterURL string, jobID string, scope FlinkMetricScope, metrics []string, aggregations []string) ([]FlinkMetric, error) {
+	path, aggregating, err := scope.path(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var requested map[string][]string
+
+	query := url.Values{}
+	if len(metrics) > 0 {
+		var names []string
+		names, requested = translateMetricNames(metrics, c.GetClusterVersion(ctx, clusterURL))
+		query.Set("get", strings.Join(names, ","))
+	}
+
+	if aggregating && len(aggregations) > 0 {
+		query.Set("agg", strings.Join(aggregations, ","))
+	}
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var result []FlinkMetric
+	if err := c.get(ctx, clusterURL, path, &result); err != nil {
+		return nil, fmt.Errorf("could not get %s metrics: %w", scope.Scope, err)
+	}
+
+	return renameMetrics(result, requested), nil
+}
+
+// GetTaskManagers fetches the overview of all task managers from /taskmanagers endpoint
+func (c *FlinkClient) GetTaskManagers(ctx context.Context, clusterURL string) ([]FlinkTaskManager, error) {
+	var response FlinkTaskManagersResponse
+	if err := c.get(ctx, clusterURL, "/taskmanagers", &response); err != nil {
+		return nil, fmt.Errorf("could not get task managers: %w", err)
+	}
+
+	return response.TaskManagers, nil
+}
+
+// GetTaskManager fetches the details of a task manager including its slots and memory metrics from /taskmanagers/:taskmanagerid endpoint
+func (c *FlinkClient) GetTaskManager(ctx context.Context, clusterURL string, taskManagerID string) (*FlinkTaskManager, error) {
+	var taskManager FlinkTaskManager
+	if err := c.get(ctx, clusterURL, "/taskmanagers/"+url.PathEscape(taskManagerID), &taskManager); err != nil {
+		return nil, fmt.Errorf("could not get task manager: %w", err)
+	}
+
+	return &taskManager, nil
+}
+
+// GetTaskManagerLogs fetches the list of log files of a task manager from /taskmanagers/:taskmanagerid/logs endpoint
+func (c *FlinkClient) GetTaskManagerLogs(ctx context.Context, clusterURL string, taskManagerID string) ([]FlinkLogInfo, error) {
+	var response FlinkLogListResponse
+	if err := c.get(ctx, clusterURL, "/taskmanagers/"+url.PathEscape(taskManagerID)+"/logs", &response); err != nil {
+		return nil, fmt.Errorf("could not get task manager logs: %w", err)
+	}
+
+	return response.Logs, nil
+}
+
+// GetJobManagerConfig fetches the cluster configuration from /jobmanager/config endpoint
+func (c *FlinkClient) GetJobManagerConfig(ctx context.Context, clusterURL string) ([]FlinkConfigEntry, error) {
+	var config []FlinkConfigEntry
+	if err := c.get(ctx, clusterURL, "/jobmanager/config", &config); err != nil {
+		return nil, fmt.Errorf("could not get job manager config: %w", err)
+	}
+
+	return config, nil
+}
+
+// GetJobManagerEnvironment fetches the JVM options and the classpath of the job manager from /jobmanager/environment endpoint
+func (c *FlinkClient) GetJobManagerEnvironment(ctx context.Context, clusterURL string) (*FlinkJobManagerEnvironment, error) {
+	var environment FlinkJobManagerEnvironment
+	if err := c.get(ctx, clusterURL, "/jobmanager/environment", &environment); err != nil {
+		return nil, fmt.Errorf("could not get job manager environment: %w", err)
+	}
+
+	return &environment, nil
+}
+
+// GetJobManagerLogs fetches the list of log files of the job manager from /jobmanager/logs endpoint
+func (c *FlinkClient) GetJobManagerLogs(ctx context.Context, clusterURL string) ([]FlinkLogInfo, error) {
+	var response FlinkLogListResponse
+	if err := c.get(ctx, clusterURL, "/jobmanager/logs", &response); err != nil {
+		return nil, fmt.Errorf("could not get job manager logs: %w", err)
+	}
+
+	return response.Logs, nil
+}
+
+// GetTaskManagerThreadDump fetches a thread dump of a task manager from /taskmanagers/:taskmanagerid/thread-dump endpoint.
+// Every call takes a new thread dump, it is neither cached nor shared with concurrent callers.
+func (c *FlinkClient) GetTaskManagerThreadDump(ctx context.Context, clusterURL string, taskManagerID string) (*FlinkThreadDump, error) {
+	var threadDump FlinkThreadDump
+	if err := c.getUncached(ctx, clusterURL, "/taskmanagers/"+url.PathEscape(taskManagerID)+"/thread-dump", &threadDump); err != nil {
+		return nil, fmt.Errorf("could not get task manager thread dump: %w", err)
+	}
+
+	return &threadDump, nil
+}
+
+// GetJobManagerThreadDump fetches a new thread dump of the job manager from /jobmanager/thread-dump endpoint
+func (c *FlinkClient) GetJobManagerThreadDump(ctx context.Context, clusterURL string) (*FlinkThreadDump, error) {
+	var threadDump FlinkThreadDump
+	if err := c.getUncached(ctx, clusterURL, "/jobmanager/thread-dump", &threadDump); err != nil {
+		return nil, fmt.Errorf("could not get job manager thread dump: %w", err)
+	}
+
+	return &threadDump, nil
+}
+
+// FlinkLogFile is an opened log file. If Flink answered a range request with partial content, Partial is set and
+// Start and End are the positions of the body within the file, Size is -1 if the size of the file is unknown.
+type FlinkLogFile struct {
+	Body    io.ReadCloser
+	Size    int64
+	Partial bool
+	Start   int64
+	End     int64
+}
+
+// OpenLogFile opens a log or stdout file like /jobmanager/log or /taskmanagers/:taskmanagerid/logs/:logname for streaming.
+// The byte range is forwarded to Flink, which is free to ignore it and send the whole file. The caller has to close the body.
+func (c *FlinkClient) OpenLogFile(ctx context.Context, clusterURL string, path string, byteRange string) (*FlinkLogFile, error) {
+	header := http.Header{}
+	if byteRange != "" {
+		header.Set("Range", byteRange)
+	}
+
+	resp, err := c.getStream(ctx, clusterURL, path, header)
+	if err != nil {
+		return nil, fmt.Errorf("could not open log file: %w", err)
+	}
+
+	file := &FlinkLogFile{
+		Body: resp.Body,
+		Size: resp.ContentLength,
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		if file.Start, file.End, file.Size, file.Partial = parseContentRange(resp.Header.Get("Content-Range")); !file.Partial {
+			if cerr := resp.Body.Close(); cerr != nil {
+				c.logger.Warn(ctx, "could not close log file %s: %v", path, cerr)
+			}
+
+			return nil, fmt.Errorf("could not open log file: invalid content range %q", resp.Header.Get("Content-Range"))
+		}
+	}
+
+	return file, nil
+}
+
+// parseContentRange parses a Content-Range header like "bytes 0-1023/4096" or "bytes 0-1023/*".
+func parseContentRange(header string) (start int64, end int64, size int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+
+	byteRange, total, found := strings.Cut(spec, "/")
+	first, last, rangeFound := strings.Cut(byteRange, "-")
+	if !found || !rangeFound {
+		return 0, 0, 0, false
+	}
+
+	var err error
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, 0, false
+	}
+
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+		return 0, 0, 0, false
+	}
+
+	size = -1
+	if total != "*" {
+		if size, err = strconv.ParseInt(total, 10, 64); err != nil {
+			return 0, 0, 0, false
+		}
+	}
+
+	return start, end, size, true
+}
+
+// TriggerSavepoint triggers an asynchronous savepoint via POST /jobs/:jobid/savepoints and returns the trigger id
+func (c *FlinkClient) TriggerSavepoint(ctx context.Context, clusterURL string, jobID string, request FlinkSavepointTriggerRequest) (string, error) {
+	var response FlinkTriggerResponse
+	if err := c.post(ctx, clusterURL, "/jobs/"+jobID+"/savepoints", request, &response); err != nil {
+		return "", fmt.Errorf("could not trigger savepoint: %w", err)
+	}
+
+	return response.RequestId, nil
+}
+
+// GetSavepointStatus fetches the status of a triggered savepoint from /jobs/:jobid/savepoints/:triggerid endpoint
+func (c *FlinkClient) GetSavepointStatus(ctx context.Context, clusterURL string, jobID string, triggerID string) (*FlinkSavepointStatus, error) {
+	var status FlinkSavepointStatus
+	if err := c.getUncached(ctx, clusterURL, "/jobs/"+jobID+"/savepoints/"+url.PathEscape(triggerID), &status); err != nil {
+		return nil, fmt.Errorf("could not get savepoint status: %w", err)
+	}
+
+	return &status, nil
+}
+
+// GetFlameGraph fetches the flame graph of a vertex from /jobs/:jobid/vertices/:vertexid/flamegraph endpoint.
+// The first request triggers the sampling, so the flame graph is only available after polling for a while. The
+// response is not cached, as it changes while polling.
+func (c *FlinkClient) GetFlameGraph(ctx context.Context, clusterURL string, jobID string, vertexID string, flameGraphType string, subtask *int) (*FlinkFlameGraph, error) {
+	query := url.Values{}
+	query.Set("type", flameGraphType)
+	if subtask != nil {
+		query.Set("subtaskindex", strconv.Itoa(*subtask))
+	}
+
+	var flameGraph FlinkFlameGraph
+	if err := c.getUncached(ctx, clusterURL, "/jobs/"+jobID+"/vertices/"+url.PathEscape(vertexID)+"/flamegraph?"+query.Encode(), &flameGraph); err != nil {
+		return nil, fmt.Errorf("could not get flame graph: %w", err)
+	}
+
+	return &flameGraph, nil
+}
+
+// metricsQuery escapes metric names and joins them for the "get" query parameter of the metric endpoints
+func metricsQuery(metrics []string) string {
+	escaped := make([]string, len(metrics))
+	for i, metric := range metrics {
+		escaped[i] = url.QueryEscape(metric)
+	}
+
+	return strings.Join(escaped, ",")
+}
+
+// get is a helper method for GET requests with JSON response, which is decoded tolerating the differences between
+// Flink versions
+func (c *FlinkClient) get(ctx context.Context, clusterURL string, path string, target any) error {
+	body, err := c.getBody(ctx, clusterURL, path)
+	if err != nil {
+		return err
+	}
+
+	return decodeFlinkResponse(body, target)
+}
+
+// getUncached is get for responses which change between requests, like the status of a savepoint which is polled.
+// It skips the response cache and doesn't share the request with concurrent callers.
+func (c *FlinkClient) getUncached(ctx context.Context, clusterURL string, path string, target any) error {
+	body, err := c.getWithRetries(ctx, clusterURL, clusterURL+path)
+	if err != nil {
+		return err
+	}
+
+	return decodeFlinkResponse(body, target)
+}
+
+// getBody is a helper method for GET requests whose JSON response is decoded by the caller
+func (c *FlinkClient) getBody(ctx context.Context, clusterURL string, path string) ([]byte, error) {
+	return c.getCoalesced(ctx, clusterURL, clusterURL+path)
+}
+
+// post is a helper method for POST requests with JSON request and response
+func (c *FlinkClient) post(ctx context.Context, clusterURL string, path string, payload any, target any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode request: %w", err)
+	}
+
+	body, err := c.do(ctx, clusterURL, http.MethodPost, clusterURL+path, data)
+	if err != nil {
+		return err
+	}
+
+	return decodeFlinkResponse(body, target)
+}
+
+// getCoalesced returns the cached response of the url or shares a single request to Flink with all concurrent
+// callers. The shared request is not canceled if the caller which started it goes away, but it isn't retried then.
+func (c *FlinkClient) getCoalesced(ctx context.Context, clusterURL string, url string) ([]byte, error) {
+	if body, ok := c.cache.get(url, time.Now()); ok {
+		return body, nil
+	}
+
+	results := c.requests.DoChan(url, func() (any, error) {
+		body, err := c.getWithRetries(ctx, clusterURL, url)
+		if err == nil {
+			c.cache.set(url, body, time.Now())
+		}
+
+		return body, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.([]byte), nil
+	}
+}
+
+// getWithRetries retries GET requests which failed because the cluster was unavailable. A started request isn't
+// canceled with the context, as its response may be shared with other callers, but no further attempt is made once
+// the context is done and the error of the last attempt is returned instead.
+func (c *FlinkClient) getWithRetries(ctx context.Context, clusterURL string, url string) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		body, err := c.do(context.WithoutCancel(ctx), clusterURL, http.MethodGet, url, nil)
+		if err == nil || attempt >= c.settings.Retry.MaxAttempts || !isRetryableFlinkError(err) {
+			return body, err
+		}
+
+		backoff := c.settings.Retry.backoff(attempt)
+		c.logger.Warn(ctx, "retrying GET %s in %s after attempt %d failed: %s", url, backoff, attempt, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// do executes a request guarded by the circuit breaker of the cluster and returns the body of the response
+func (c *FlinkClient) do(ctx context.Context, clusterURL string, method string, url string, payload []byte) ([]byte, error) {
+	breaker := c.breaker(clusterURL)
+	allowed, probe := breaker.allow(time.Now())
+	if !allowed {
+		return nil, &FlinkError{Kind: ErrFlinkUnavailable, Method: method, URL: url, Err: errFlinkCircuitOpen}
+	}
+
+	body, err := c.roundTrip(ctx, method, url, payload)
+	c.recordResult(ctx, breaker, probe, clusterURL, err)
+
+	return body, err
+}
+
+func (c *FlinkClient) roundTrip(ctx context.Context, method string, url string, payload []byte) (body []byte, err error) {
+	var reqBody io.Reader = http.NoBody
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, newFlinkTransportError(method, url, err)
 	}
 	defer func() {
 		if cerr := resp.Body.Close(); cerr != nil && err == nil {
@@ -72,18 +652,92 @@ func (c *FlinkClient) get(ctx context.Context, url string, target any) (err erro
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		body, readErr := io.ReadAll(resp.Body)
-		if readErr != nil {
-			return fmt.Errorf("read error response body: %w", readErr)
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		return nil, newFlinkTransportError(method, url, fmt.Errorf("read response body: %w", err))
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return nil, newFlinkStatusError(method, url, resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+func (c *FlinkClient) breaker(clusterURL string) *circuitBreaker {
+	c.lck.Lock()
+	defer c.lck.Unlock()
+
+	breaker, ok := c.breakers[clusterURL]
+	if !ok {
+		breaker = &circuitBreaker{settings: c.settings.CircuitBreaker}
+		c.breakers[clusterURL] = breaker
+	}
+
+	return breaker
+}
+
+// recordResult counts requests which failed because the cluster is unreachable as failures of the circuit breaker.
+// Requests canceled by the caller say nothing about the cluster.
+func (c *FlinkClient) recordResult(ctx context.Context, breaker *circuitBreaker, probe bool, clusterURL string, err error) {
+	if ctx.Err() != nil {
+		breaker.release(probe)
+
+		return
+	}
+
+	if opened := breaker.record(!isUnreachableFlinkError(err), probe, time.Now()); opened {
+		c.logger.Warn(ctx, "opened circuit breaker of %s for %s after %d consecutive failures: %s",
+			clusterURL, c.settings.CircuitBreaker.OpenDuration, c.settings.CircuitBreaker.FailureThreshold, err)
+	}
+}
+
+// getStream is a helper method for GET requests whose response body is streamed to the caller, who has to close it
+func (c *FlinkClient) getStream(ctx context.Context, clusterURL string, path string, header http.Header) (*http.Response, error) {
+	url := clusterURL + path
+
+	breaker := c.breaker(clusterURL)
+	allowed, probe := breaker.allow(time.Now())
+	if !allowed {
+		return nil, &FlinkError{Kind: ErrFlinkUnavailable, Method: http.MethodGet, URL: url, Err: errFlinkCircuitOpen}
+	}
+
+	resp, err := c.openStream(ctx, url, header)
+	c.recordResult(ctx, breaker, probe, clusterURL, err)
+
+	return resp, err
+}
+
+func (c *FlinkClient) openStream(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.streamClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
 
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newFlinkTransportError(http.MethodGet, url, err)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
-		return fmt.Errorf("could not decode response: %w", err)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if cerr := resp.Body.Close(); cerr != nil && readErr == nil {
+			readErr = cerr
+		}
+
+		if readErr != nil {
+			return nil, fmt.Errorf("read error response body: %w", readErr)
+		}
+
+		return nil, newFlinkStatusError(http.MethodGet, url, resp.StatusCode, string(body))
 	}
 
-	return nil
+	return resp, nil
 }
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+var (
+	// ErrFlinkNotFound is returned if Flink does not know the requested resource, e.g. an unknown job id.
+	ErrFlinkNotFound = errors.New("not found in flink")
+	// ErrFlinkUnavailable is returned if Flink could not be reached, answered with a gateway error or its circuit breaker is open.
+	ErrFlinkUnavailable = errors.New("flink is unavailable")
+	// ErrFlinkTimeout is returned if Flink did not answer in time.
+	ErrFlinkTimeout = errors.New("flink did not respond in time")
+	// ErrDeploymentNotFound is returned if the deployment is not known to the deployment watcher.
+	ErrDeploymentNotFound = errors.New("deployment not found")
+	// ErrSessionJobNotFound is returned if the session job is not known to the deployment watcher or doesn't
+	// run on the session cluster of the deployment.
+	ErrSessionJobNotFound = errors.New("session job not found")
+
+	errFlinkCircuitOpen = errors.New("circuit breaker is open")
+)
+
+// FlinkError is returned by the FlinkClient for failed requests. Kind is one of the ErrFlink* errors or nil if the
+// request failed for another reason, like Flink answering with an internal server error.
+type FlinkError struct {
+	Kind       error
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *FlinkError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%s %s failed: %s", e.Method, e.URL, e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("%s %s failed with status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+	default:
+		return fmt.Sprintf("%s %s failed: %s", e.Method, e.URL, e.Kind)
+	}
+}
+
+func (e *FlinkError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+func newFlinkStatusError(method string, url string, statusCode int, body string) *FlinkError {
+	err := &FlinkError{
+		Method:     method,
+		URL:        url,
+		StatusCode: statusCode,
+		Body:       body,
+	}
+
+	switch statusCode {
+	case http.StatusNotFound:
+		err.Kind = ErrFlinkNotFound
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		err.Kind = ErrFlinkUnavailable
+	case http.StatusGatewayTimeout:
+		err.Kind = ErrFlinkTimeout
+	}
+
+	return err
+}
+
+func newFlinkTransportError(method string, url string, err error) *FlinkError {
+	kind := ErrFlinkUnavailable
+	if isTimeoutError(err) {
+		kind = ErrFlinkTimeout
+	}
+
+	return &FlinkError{
+		Kind:   kind,
+		Method: method,
+		URL:    url,
+		Err:    err,
+	}
+}
+
+func isTimeoutError(err error) bool {
+	var timeoutErr interface{ Timeout() bool }
+
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &timeoutErr) && timeoutErr.Timeout())
+}
+
+// isUnreachableFlinkError reports whether the request failed because the cluster could not be reached.
+func isUnreachableFlinkError(err error) bool {
+	return errors.Is(err, ErrFlinkUnavailable) || errors.Is(err, ErrFlinkTimeout)
+}
+
+func isRetryableFlinkError(err error) bool {
+	return isUnreachableFlinkError(err) && !errors.Is(err, errFlinkCircuitOpen)
+}
+
+// FlinkErrorStatus returns the http status code which should be returned to the client for the error.
+func FlinkErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, ErrFlinkNotFound), errors.Is(err, ErrDeploymentNotFound), errors.Is(err, ErrSessionJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrFlinkTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrDataDirectoryMissing):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrFlinkUnavailable):
+		return http.StatusBadGateway
+	default:
+		return fallback
+	}
+}
+
+// NewFlinkErrorMiddleware answers requests whose handler failed because of an unknown deployment or job, an
+// unreachable Flink cluster or a missing data directory with the matching status code instead of a generic
+// internal server error.
+// All other errors are left to the error middleware of the server.
+func NewFlinkErrorMiddleware(_ context.Context, _ cfg.Config, logger log.Logger) (gin.HandlerFunc, error) {
+	logger = logger.WithChannel("flink_errors")
+
+	return func(ginCtx *gin.Context) {
+		ginCtx.Next()
+
+		if len(ginCtx.Errors) == 0 || ginCtx.Writer.Written() {
+			return
+		}
+
+		err := ginCtx.Errors.Last().Err
+		status := FlinkErrorStatus(err, http.StatusInternalServerError)
+
+		if status == http.StatusInternalServerError {
+			return
+		}
+
+		// the errors are consumed here, otherwise the error middleware of the server would answer with a 500
+		logger.Warn(ginCtx, "%s %s failed with status %d: %s", ginCtx.Request.Method, ginCtx.Request.URL.Path, status, err)
+		ginCtx.Errors = ginCtx.Errors[:0]
+		ginCtx.JSON(status, gin.H{"err": err.Error()})
+	}, nil
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+// FlinkClientSettings configures the timeouts, retries, circuit breaker and response cache of the FlinkClient.
+// The version of a cluster is detected again after the version ttl, as a cluster keeps its URL during an upgrade.
+type FlinkClientSettings struct {
+	Timeout        time.Duration               `cfg:"timeout" default:"30s"`
+	Retry          FlinkClientRetrySettings    `cfg:"retry"`
+	CircuitBreaker FlinkCircuitBreakerSettings `cfg:"circuit_breaker"`
+	CacheTtl       time.Duration               `cfg:"cache_ttl" default:"2s"`
+	VersionTtl     time.Duration               `cfg:"version_ttl" default:"10m"`
+}
+
+// FlinkClientRetrySettings configures the retries of GET requests which failed because Flink was unavailable.
+type FlinkClientRetrySettings struct {
+	MaxAttempts    int           `cfg:"max_attempts" default:"3"`
+	InitialBackoff time.Duration `cfg:"initial_backoff" default:"200ms"`
+	MaxBackoff     time.Duration `cfg:"max_backoff" default:"2s"`
+}
+
+// FlinkCircuitBreakerSettings configures the circuit breaker of a cluster. It opens after the given number of
+// consecutive failures and lets a single request through after the open duration to probe the cluster again.
+type FlinkCircuitBreakerSettings struct {
+	Enabled          bool          `cfg:"enabled" default:"true"`
+	FailureThreshold int           `cfg:"failure_threshold" default:"5"`
+	OpenDuration     time.Duration `cfg:"open_duration" default:"30s"`
+}
+
+func (s FlinkClientRetrySettings) backoff(attempt int) time.Duration {
+	backoff := s.InitialBackoff
+	for i := 1; i < attempt && backoff < s.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, s.MaxBackoff)
+}
+
+type circuitBreaker struct {
+	lck      sync.Mutex
+	settings FlinkCircuitBreakerSettings
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a request to the cluster may be made and whether it is the probe request. Once the open
+// duration passed, only a single probe request is allowed until its result was recorded.
+func (b *circuitBreaker) allow(now time.Time) (allowed bool, probe bool) {
+	b.lck.Lock()
+	defer b.lck.Unlock()
+
+	if !b.settings.Enabled || b.failures < b.settings.FailureThreshold {
+		return true, false
+	}
+
+	if b.probing || now.Sub(b.openedAt) < b.settings.OpenDuration {
+		return false, false
+	}
+
+	b.probing = true
+
+	return true, true
+}
+
+// record records the result of a request and reports whether the circuit breaker opened because of it. Requests
+// which were allowed before the circuit breaker opened don't end the probe.
+func (b *circuitBreaker) record(success bool, probe bool, now time.Time) bool {
+	b.lck.Lock()
+	defer b.lck.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	if success || !b.settings.Enabled {
+		b.failures = 0
+
+		return false
+	}
+
+	b.failures++
+	if b.failures < b.settings.FailureThreshold {
+		return false
+	}
+
+	b.openedAt = now
+
+	return true
+}
+
+// release gives up the probe of a request whose result says nothing about the cluster.
+func (b *circuitBreaker) release(probe bool) {
+	b.lck.Lock()
+	defer b.lck.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+}
+
+type cachedResponse struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+// responseCache keeps the bodies of successful GET requests for a short time, so the many viewers of a deployment
+// in the UI don't cause a request to Flink each.
+type responseCache struct {
+	lck     sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedResponse
+}
+
+func (c *responseCache) get(key string, now time.Time) ([]byte, bool) {
+	c.lck.Lock()
+	defer c.lck.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || now.After(entry.expiresAt) {
+		return nil, false
+	}
+
+	return entry.body, true
+}
+
+func (c *responseCache) set(key string, body []byte, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.lck.Lock()
+	defer c.lck.Unlock()
+
+	for existing, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, existing)
+		}
+	}
+
+	c.entries[key] = cachedResponse{body: body, expiresAt: now.Add(c.ttl)}
+}
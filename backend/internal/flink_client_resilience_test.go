@@ -0,0 +1,241 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+func newTestFlinkClient(settings FlinkClientSettings) *FlinkClient {
+	return &FlinkClient{
+		httpClient:   &http.Client{Timeout: time.Second},
+		streamClient: &http.Client{},
+		logger:       log.NewLogger(),
+		settings:     &settings,
+		breakers:     map[string]*circuitBreaker{},
+		versions:     map[string]clusterVersion{},
+		cache:        &responseCache{ttl: settings.CacheTtl, entries: map[string]cachedResponse{}},
+	}
+}
+
+func TestFlinkClientRetryStopsWithContext(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newTestFlinkClient(FlinkClientSettings{
+		Retry: FlinkClientRetrySettings{MaxAttempts: 5, InitialBackoff: time.Minute, MaxBackoff: time.Minute},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := client.getWithRetries(ctx, server.URL, server.URL+"/jobs")
+
+	if elapsed := time.Since(started); elapsed > 10*time.Second {
+		t.Errorf("expected the backoff to end with the context, took %s", elapsed)
+	}
+
+	if !errors.Is(err, ErrFlinkUnavailable) || attempts.Load() != 1 {
+		t.Errorf("expected the error of the single attempt, got %v after %d attempts", err, attempts.Load())
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	settings := FlinkCircuitBreakerSettings{Enabled: true, FailureThreshold: 2, OpenDuration: 30 * time.Second}
+
+	type step struct {
+		name    string
+		at      time.Duration
+		action  string // allow, success, failure, release
+		probe   bool
+		allowed bool
+		opened  bool
+	}
+
+	cases := map[string][]step{
+		"opens after the failure threshold": {
+			{name: "first failure", action: "failure"},
+			{name: "closed", action: "allow", allowed: true},
+			{name: "second failure", action: "failure", opened: true},
+			{name: "open", at: time.Second, action: "allow"},
+		},
+		"success resets the failures": {
+			{name: "failure", action: "failure"},
+			{name: "success", action: "success"},
+			{name: "failure after success", action: "failure"},
+			{name: "closed", action: "allow", allowed: true},
+		},
+		"a single probe after the open duration": {
+			{name: "first failure", action: "failure"},
+			{name: "second failure", action: "failure", opened: true},
+			{name: "probe", at: 31 * time.Second, action: "allow", allowed: true, probe: true},
+			{name: "while probing", at: 32 * time.Second, action: "allow"},
+			{name: "probe succeeded", at: 33 * time.Second, action: "success", probe: true},
+			{name: "closed", at: 34 * time.Second, action: "allow", allowed: true},
+		},
+		"a failed probe opens the circuit breaker again": {
+			{name: "first failure", action: "failure"},
+			{name: "second failure", action: "failure", opened: true},
+			{name: "probe", at: 31 * time.Second, action: "allow", allowed: true, probe: true},
+			{name: "probe failed", at: 32 * time.Second, action: "failure", probe: true, opened: true},
+			{name: "open", at: 33 * time.Second, action: "allow"},
+			{name: "next probe", at: 63 * time.Second, action: "allow", allowed: true, probe: true},
+		},
+		"other requests don't end the probe": {
+			{name: "first failure", action: "failure"},
+			{name: "second failure", action: "failure", opened: true},
+			{name: "probe", at: 31 * time.Second, action: "allow", allowed: true, probe: true},
+			{name: "earlier request failed", at: 32 * time.Second, action: "failure", opened: true},
+			{name: "still probing", at: 63 * time.Second, action: "allow"},
+			{name: "earlier request released", at: 64 * time.Second, action: "release"},
+			{name: "probing after release", at: 65 * time.Second, action: "allow"},
+		},
+		"a released probe lets the next request probe": {
+			{name: "first failure", action: "failure"},
+			{name: "second failure", action: "failure", opened: true},
+			{name: "probe", at: 31 * time.Second, action: "allow", allowed: true, probe: true},
+			{name: "probe canceled", at: 32 * time.Second, action: "release", probe: true},
+			{name: "next probe", at: 33 * time.Second, action: "allow", allowed: true, probe: true},
+		},
+	}
+
+	for name, steps := range cases {
+		t.Run(name, func(t *testing.T) {
+			breaker := &circuitBreaker{settings: settings}
+
+			for _, step := range steps {
+				now := start.Add(step.at)
+
+				switch step.action {
+				case "allow":
+					if allowed, probe := breaker.allow(now); allowed != step.allowed || probe != step.probe {
+						t.Errorf("%s: expected allowed %t and probe %t, got %t and %t", step.name, step.allowed, step.probe, allowed, probe)
+					}
+				case "success", "failure":
+					if opened := breaker.record(step.action == "success", step.probe, now); opened != step.opened {
+						t.Errorf("%s: expected opened %t, got %t", step.name, step.opened, opened)
+					}
+				case "release":
+					breaker.release(step.probe)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := &circuitBreaker{settings: FlinkCircuitBreakerSettings{FailureThreshold: 1, OpenDuration: time.Minute}}
+	now := time.Now()
+
+	for range 3 {
+		if opened := breaker.record(false, false, now); opened {
+			t.Fatalf("expected a disabled circuit breaker to never open")
+		}
+
+		if allowed, _ := breaker.allow(now); !allowed {
+			t.Fatalf("expected a disabled circuit breaker to allow all requests")
+		}
+	}
+}
+
+func TestFlinkClientRetryBackoff(t *testing.T) {
+	settings := FlinkClientRetrySettings{InitialBackoff: 200 * time.Millisecond, MaxBackoff: time.Second}
+
+	cases := map[int]time.Duration{
+		1:  200 * time.Millisecond,
+		2:  400 * time.Millisecond,
+		3:  800 * time.Millisecond,
+		4:  time.Second,
+		10: time.Second,
+		64: time.Second,
+	}
+
+	for attempt, expected := range cases {
+		if backoff := settings.backoff(attempt); backoff != expected {
+			t.Errorf("expected a backoff of %s after attempt %d, got %s", expected, attempt, backoff)
+		}
+	}
+
+	if backoff := (FlinkClientRetrySettings{InitialBackoff: 3 * time.Second, MaxBackoff: time.Second}).backoff(1); backoff != time.Second {
+		t.Errorf("expected the initial backoff to be limited to the max backoff, got %s", backoff)
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	cache := &responseCache{ttl: 2 * time.Second, entries: map[string]cachedResponse{}}
+	cache.set("/jobs", []byte("jobs"), now)
+
+	cases := map[string]struct {
+		key   string
+		at    time.Duration
+		found bool
+	}{
+		"fresh":        {key: "/jobs", at: time.Second, found: true},
+		"at the ttl":   {key: "/jobs", at: 2 * time.Second, found: true},
+		"expired":      {key: "/jobs", at: 3 * time.Second},
+		"unknown path": {key: "/overview", at: time.Second},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			body, found := cache.get(c.key, now.Add(c.at))
+			if found != c.found || (found && string(body) != "jobs") {
+				t.Errorf("expected found %t, got %t with %q", c.found, found, body)
+			}
+		})
+	}
+
+	cache.set("/overview", []byte("overview"), now.Add(3*time.Second))
+	if _, ok := cache.entries["/jobs"]; ok {
+		t.Errorf("expected expired entries to be removed")
+	}
+
+	disabled := &responseCache{entries: map[string]cachedResponse{}}
+	disabled.set("/jobs", []byte("jobs"), now)
+	if _, found := disabled.get("/jobs", now); found {
+		t.Errorf("expected a cache without ttl to keep nothing")
+	}
+}
+
+func TestFlinkErrorStatus(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		expected int
+	}{
+		"flink not found":      {err: newFlinkStatusError(http.MethodGet, "/jobs/1", http.StatusNotFound, ""), expected: http.StatusNotFound},
+		"flink bad gateway":    {err: newFlinkStatusError(http.MethodGet, "/jobs/1", http.StatusBadGateway, ""), expected: http.StatusBadGateway},
+		"flink unavailable":    {err: newFlinkStatusError(http.MethodGet, "/jobs/1", http.StatusServiceUnavailable, ""), expected: http.StatusBadGateway},
+		"flink timeout":        {err: newFlinkStatusError(http.MethodGet, "/jobs/1", http.StatusGatewayTimeout, ""), expected: http.StatusGatewayTimeout},
+		"flink internal error": {err: newFlinkStatusError(http.MethodGet, "/jobs/1", http.StatusInternalServerError, ""), expected: http.StatusInternalServerError},
+		"transport error":      {err: newFlinkTransportError(http.MethodGet, "/jobs/1", errors.New("connection refused")), expected: http.StatusBadGateway},
+		"transport timeout":    {err: newFlinkTransportError(http.MethodGet, "/jobs/1", context.DeadlineExceeded), expected: http.StatusGatewayTimeout},
+		"circuit open":         {err: &FlinkError{Kind: ErrFlinkUnavailable, Err: errFlinkCircuitOpen}, expected: http.StatusBadGateway},
+		"wrapped not found":    {err: fmt.Errorf("could not get job: %w", newFlinkStatusError(http.MethodGet, "/jobs/1", http.StatusNotFound, "")), expected: http.StatusNotFound},
+		"deployment not found": {err: fmt.Errorf("%w: default/orders", ErrDeploymentNotFound), expected: http.StatusNotFound},
+		"session job":          {err: ErrSessionJobNotFound, expected: http.StatusNotFound},
+		"data directory":       {err: ErrDataDirectoryMissing, expected: http.StatusServiceUnavailable},
+		"other error":          {err: errors.New("boom"), expected: http.StatusInternalServerError},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if status := FlinkErrorStatus(c.err, http.StatusInternalServerError); status != c.expected {
+				t.Errorf("expected status %d, got %d", c.expected, status)
+			}
+		})
+	}
+}
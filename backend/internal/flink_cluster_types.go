@@ -0,0 +1,142 @@
+package internal
+
+// FlinkTaskManagersResponse is the response from GET /taskmanagers
+type FlinkTaskManagersResponse struct {
+	TaskManagers []FlinkTaskManager `json:"taskmanagers"`
+}
+
+// FlinkTaskManager is the overview of a single task manager.
+type FlinkTaskManager struct {
+	Id                     string                         `json:"id"`
+	Path                   string                         `json:"path"`
+	DataPort               int                            `json:"dataPort"`
+	JmxPort                int                            `json:"jmxPort"`
+	TimeSinceLastHeartbeat int64                          `json:"timeSinceLastHeartbeat"`
+	SlotsNumber            int                            `json:"slotsNumber"`
+	FreeSlots              int                            `json:"freeSlots"`
+	TotalResource          FlinkResourceProfile           `json:"totalResource"`
+	FreeResource           FlinkResourceProfile           `json:"freeResource"`
+	Hardware               FlinkHardwareDescription       `json:"hardware"`
+	MemoryConfiguration    FlinkTaskExecutorMemoryConfig  `json:"memoryConfiguration"`
+	Blocked                bool                           `json:"blocked,omitempty"`
+	AllocatedSlots         []FlinkSlotInfo                `json:"allocatedSlots,omitempty"`
+	Metrics                *FlinkTaskManagerMetricsDetail `json:"metrics,omitempty"`
+}
+
+// FlinkResourceProfile describes the resources of a task manager or slot, memory sizes are in bytes.
+type FlinkResourceProfile struct {
+	CpuCores          float64            `json:"cpuCores"`
+	TaskHeapMemory    int64              `json:"taskHeapMemory"`
+	TaskOffHeapMemory int64              `json:"taskOffHeapMemory"`
+	ManagedMemory     int64              `json:"managedMemory"`
+	NetworkMemory     int64              `json:"networkMemory"`
+	ExtendedResources map[string]float64 `json:"extendedResources,omitempty"`
+}
+
+// FlinkHardwareDescription is the hardware of the host a task manager runs on.
+type FlinkHardwareDescription struct {
+	CpuCores       int   `json:"cpuCores"`
+	PhysicalMemory int64 `json:"physicalMemory"`
+	FreeMemory     int64 `json:"freeMemory"`
+	ManagedMemory  int64 `json:"managedMemory"`
+}
+
+// FlinkTaskExecutorMemoryConfig is the configured memory model of a task manager in bytes.
+type FlinkTaskExecutorMemoryConfig struct {
+	FrameworkHeap      int64 `json:"frameworkHeap"`
+	TaskHeap           int64 `json:"taskHeap"`
+	FrameworkOffHeap   int64 `json:"frameworkOffHeap"`
+	TaskOffHeap        int64 `json:"taskOffHeap"`
+	NetworkMemory      int64 `json:"networkMemory"`
+	ManagedMemory      int64 `json:"managedMemory"`
+	JvmMetaspace       int64 `json:"jvmMetaspace"`
+	JvmOverhead        int64 `json:"jvmOverhead"`
+	TotalFlinkMemory   int64 `json:"totalFlinkMemory"`
+	TotalProcessMemory int64 `json:"totalProcessMemory"`
+}
+
+// FlinkSlotInfo is a slot of a task manager which is allocated to a job.
+type FlinkSlotInfo struct {
+	Index    int                  `json:"index"`
+	JobId    string               `json:"jobId"`
+	Resource FlinkResourceProfile `json:"resource"`
+}
+
+// FlinkTaskManagerMetricsDetail are the JVM and network memory metrics returned with GET /taskmanagers/:taskmanagerid
+type FlinkTaskManagerMetricsDetail struct {
+	HeapUsed                            int64                   `json:"heapUsed"`
+	HeapCommitted                       int64                   `json:"heapCommitted"`
+	HeapMax                             int64                   `json:"heapMax"`
+	NonHeapUsed                         int64                   `json:"nonHeapUsed"`
+	NonHeapCommitted                    int64                   `json:"nonHeapCommitted"`
+	NonHeapMax                          int64                   `json:"nonHeapMax"`
+	DirectCount                         int64                   `json:"directCount"`
+	DirectUsed                          int64                   `json:"directUsed"`
+	DirectMax                           int64                   `json:"directMax"`
+	MappedCount                         int64                   `json:"mappedCount"`
+	MappedUsed                          int64                   `json:"mappedUsed"`
+	MappedMax                           int64                   `json:"mappedMax"`
+	NettyShuffleMemorySegmentsAvailable int64                   `json:"nettyShuffleMemorySegmentsAvailable"`
+	NettyShuffleMemorySegmentsUsed      int64                   `json:"nettyShuffleMemorySegmentsUsed"`
+	NettyShuffleMemorySegmentsTotal     int64                   `json:"nettyShuffleMemorySegmentsTotal"`
+	NettyShuffleMemoryAvailable         int64                   `json:"nettyShuffleMemoryAvailable"`
+	NettyShuffleMemoryUsed              int64                   `json:"nettyShuffleMemoryUsed"`
+	NettyShuffleMemoryTotal             int64                   `json:"nettyShuffleMemoryTotal"`
+	GarbageCollectors                   []FlinkGarbageCollector `json:"garbageCollectors"`
+}
+
+// FlinkGarbageCollector are the collection count and time of a garbage collector of a task manager.
+type FlinkGarbageCollector struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+	Time  int64  `json:"time"`
+}
+
+// FlinkLogListResponse is the response from GET /jobmanager/logs and GET /taskmanagers/:taskmanagerid/logs
+type FlinkLogListResponse struct {
+	Logs []FlinkLogInfo `json:"logs"`
+}
+
+// FlinkLogInfo is a log file available for download.
+type FlinkLogInfo struct {
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	Mtime int64  `json:"mtime"`
+}
+
+// FlinkDashboardConfig is the response from GET /config, which tells the version of the cluster.
+type FlinkDashboardConfig struct {
+	FlinkVersion  string `json:"flink-version"`
+	FlinkRevision string `json:"flink-revision"`
+}
+
+// FlinkConfigEntry is a single entry of the cluster configuration as returned by GET /jobmanager/config
+type FlinkConfigEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// FlinkJobManagerEnvironment is the response from GET /jobmanager/environment
+type FlinkJobManagerEnvironment struct {
+	Jvm       FlinkJvmInfo `json:"jvm"`
+	Classpath []string     `json:"classpath"`
+}
+
+// FlinkJvmInfo describes the JVM of the job manager including the options it was started with.
+type FlinkJvmInfo struct {
+	Version string   `json:"version"`
+	Arch    string   `json:"arch"`
+	Options []string `json:"options"`
+}
+
+// FlinkThreadDump is the response from GET /jobmanager/thread-dump and GET /taskmanagers/:taskmanagerid/thread-dump
+type FlinkThreadDump struct {
+	ThreadInfos []FlinkThreadInfo `json:"threadInfos"`
+}
+
+// FlinkThreadInfo is a single thread of a thread dump. The stringified thread info has the format of
+// java.lang.management.ThreadInfo#toString, but without its limit of eight stack frames.
+type FlinkThreadInfo struct {
+	ThreadName            string `json:"threadName"`
+	StringifiedThreadInfo string `json:"stringifiedThreadInfo"`
+}
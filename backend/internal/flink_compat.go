@@ -0,0 +1,695 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// flinkObject gives tolerant access to the fields of a JSON object returned by Flink. Fields can be looked up by
+// several names, missing fields, null and values of an unexpected type decode to the zero value and numbers are
+// also accepted as strings. A field Flink renamed or dropped therefore can't fail the whole response.
+type flinkObject map[string]json.RawMessage
+
+func decodeFlinkObject(data []byte) (flinkObject, error) {
+	object := flinkObject{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+
+	return object, nil
+}
+
+// decodeFlinkResponse decodes a response of Flink into one of our types with the same tolerance as flinkObject.
+// Fields are matched by their json name, missing fields, null and values of an unexpected type keep the zero value
+// and numbers are also accepted as strings. Only a response which is no JSON at all fails. Responses whose shape
+// differs between Flink versions have their own decoders instead.
+func decodeFlinkResponse(data []byte, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("could not decode response into %T", target)
+	}
+
+	if !json.Valid(data) {
+		return fmt.Errorf("could not decode response: invalid JSON")
+	}
+
+	decodeFlinkValue(data, value.Elem())
+
+	return nil
+}
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+func decodeFlinkValue(data json.RawMessage, value reflect.Value) {
+	if isJSONNull(data) {
+		return
+	}
+
+	// types decoding themselves, like json.RawMessage, are left to encoding/json
+	if reflect.PointerTo(value.Type()).Implements(jsonUnmarshalerType) {
+		decoded := reflect.New(value.Type())
+		if json.Unmarshal(data, decoded.Interface()) == nil {
+			value.Set(decoded.Elem())
+		}
+
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		decoded := reflect.New(value.Type().Elem())
+		decodeFlinkValue(data, decoded.Elem())
+		value.Set(decoded)
+	case reflect.Struct:
+		var object flinkObject
+		if json.Unmarshal(data, &object) == nil {
+			decodeFlinkStruct(object, value)
+		}
+	case reflect.Slice:
+		decodeFlinkSlice(data, value)
+	case reflect.Map:
+		decodeFlinkMap(data, value)
+	default:
+		decodeFlinkScalar(data, value)
+	}
+}
+
+func decodeFlinkStruct(object flinkObject, value reflect.Value) {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			decodeFlinkStruct(object, value.Field(i))
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if raw, ok := object.raw(name); ok {
+			decodeFlinkValue(raw, value.Field(i))
+		}
+	}
+}
+
+func decodeFlinkSlice(data json.RawMessage, value reflect.Value) {
+	var elements []json.RawMessage
+	if json.Unmarshal(data, &elements) != nil {
+		return
+	}
+
+	slice := reflect.MakeSlice(value.Type(), len(elements), len(elements))
+	for i, element := range elements {
+		decodeFlinkValue(element, slice.Index(i))
+	}
+
+	value.Set(slice)
+}
+
+func decodeFlinkMap(data json.RawMessage, value reflect.Value) {
+	var entries map[string]json.RawMessage
+	if value.Type().Key().Kind() != reflect.String || json.Unmarshal(data, &entries) != nil {
+		return
+	}
+
+	result := reflect.MakeMapWithSize(value.Type(), len(entries))
+	for key, entry := range entries {
+		element := reflect.New(value.Type().Elem()).Elem()
+		decodeFlinkValue(entry, element)
+		result.SetMapIndex(reflect.ValueOf(key).Convert(value.Type().Key()), element)
+	}
+
+	value.Set(result)
+}
+
+func decodeFlinkScalar(data json.RawMessage, value reflect.Value) {
+	scalar := flinkObject{"value": data}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(scalar.string("value"))
+	case reflect.Bool:
+		value.SetBool(scalar.bool("value"))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(scalar.int64("value"))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number := scalar.int64("value"); number > 0 {
+			value.SetUint(uint64(number))
+		}
+	case reflect.Float32, reflect.Float64:
+		if number, ok := scalar.number("value"); ok {
+			float, _ := number.Float64()
+			value.SetFloat(float)
+		}
+	default:
+		decoded := reflect.New(value.Type())
+		if json.Unmarshal(data, decoded.Interface()) == nil {
+			value.Set(decoded.Elem())
+		}
+	}
+}
+
+func isJSONNull(data json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+// raw returns the value of the first of the names which is present and not null.
+func (o flinkObject) raw(names ...string) (json.RawMessage, bool) {
+	for _, name := range names {
+		if value, ok := o[name]; ok && !isJSONNull(value) {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+func (o flinkObject) has(names ...string) bool {
+	_, ok := o.raw(names...)
+
+	return ok
+}
+
+func (o flinkObject) number(names ...string) (json.Number, bool) {
+	value, ok := o.raw(names...)
+	if !ok {
+		return "", false
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(value, &number); err != nil {
+		return "", false
+	}
+
+	return number, true
+}
+
+func (o flinkObject) int64(names ...string) int64 {
+	number, ok := o.number(names...)
+	if !ok {
+		return 0
+	}
+
+	if value, err := number.Int64(); err == nil {
+		return value
+	}
+
+	value, _ := number.Float64()
+
+	return int64(value)
+}
+
+func (o flinkObject) int(names ...string) int {
+	return int(o.int64(names...))
+}
+
+func (o flinkObject) string(names ...string) string {
+	value, ok := o.raw(names...)
+	if !ok {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		return text
+	}
+
+	if number, ok := o.number(names...); ok {
+		return number.String()
+	}
+
+	return ""
+}
+
+func (o flinkObject) bool(names ...string) bool {
+	value, ok := o.raw(names...)
+	if !ok {
+		return false
+	}
+
+	var flag bool
+	if err := json.Unmarshal(value, &flag); err == nil {
+		return flag
+	}
+
+	flag, _ = strconv.ParseBool(o.string(names...))
+
+	return flag
+}
+
+// object returns the first of the names which is an object, nil otherwise. All lookups on nil return zero values.
+func (o flinkObject) object(names ...string) flinkObject {
+	for _, name := range names {
+		var object flinkObject
+		if value, ok := o.raw(name); ok && json.Unmarshal(value, &object) == nil {
+			return object
+		}
+	}
+
+	return nil
+}
+
+// objects returns the objects of the first of the names which is an array, skipping elements which are no objects.
+func (o flinkObject) objects(names ...string) []flinkObject {
+	for _, name := range names {
+		var elements []json.RawMessage
+		if value, ok := o.raw(name); !ok || json.Unmarshal(value, &elements) != nil {
+			continue
+		}
+
+		objects := make([]flinkObject, 0, len(elements))
+		for _, element := range elements {
+			var object flinkObject
+			if json.Unmarshal(element, &object) == nil && object != nil {
+				objects = append(objects, object)
+			}
+		}
+
+		return objects
+	}
+
+	return nil
+}
+
+// objectMap returns the values of an object whose values are objects themselves, e.g. the tasks of a checkpoint.
+func (o flinkObject) objectMap(name string) map[string]flinkObject {
+	values := o.object(name)
+	objects := make(map[string]flinkObject, len(values))
+
+	for key := range values {
+		if object := values.object(key); object != nil {
+			objects[key] = object
+		}
+	}
+
+	return objects
+}
+
+func (o flinkObject) intMap(name string) map[string]int {
+	values := o.object(name)
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := make(map[string]int, len(values))
+	for key := range values {
+		result[key] = values.int(key)
+	}
+
+	return result
+}
+
+func (o flinkObject) stringMap(name string) map[string]string {
+	values := o.object(name)
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(values))
+	for key := range values {
+		result[key] = values.string(key)
+	}
+
+	return result
+}
+
+// decodeCheckpointStatistics decodes the response of GET /jobs/:jobid/checkpoints. Flink reports the latest
+// completed and the restored checkpoint below "latest", which are also accepted at the top level.
+func decodeCheckpointStatistics(data []byte, version FlinkVersion) (*FlinkCheckpointStatistics, error) {
+	object, err := decodeFlinkObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := object.object("counts")
+	latest := object.object("latest")
+
+	stats := &FlinkCheckpointStatistics{
+		Counts: FlinkCheckpointCounts{
+			Restored:   counts.int64("restored"),
+			Total:      counts.int64("total"),
+			InProgress: counts.int64("in_progress"),
+			Completed:  counts.int64("completed"),
+			Failed:     counts.int64("failed"),
+		},
+		Summary: decodeCheckpointSummary(object.object("summary"), version),
+		History: make([]FlinkCheckpointDetail, 0),
+	}
+
+	switch {
+	case latest.has("completed"):
+		completed := decodeCheckpointDetail(latest.object("completed"), version)
+		stats.Latest = &completed
+	case latest.has("id"):
+		completed := decodeCheckpointDetail(latest, version)
+		stats.Latest = &completed
+	}
+
+	if restored := latest.object("restored"); restored != nil {
+		stats.Restored = decodeRestoredCheckpoint(restored)
+	} else if restored = object.object("restored"); restored != nil {
+		stats.Restored = decodeRestoredCheckpoint(restored)
+	}
+
+	for _, checkpoint := range object.objects("history") {
+		stats.History = append(stats.History, decodeCheckpointDetail(checkpoint, version))
+	}
+
+	return stats, nil
+}
+
+// decodeCheckpointDetails decodes the response of GET /jobs/:jobid/checkpoints/details/:checkpointid
+func decodeCheckpointDetails(data []byte, version FlinkVersion) (*FlinkCheckpointDetails, error) {
+	object, err := decodeFlinkObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &FlinkCheckpointDetails{
+		FlinkCheckpointDetail: decodeCheckpointDetail(object, version),
+		FailureTimestamp:      object.int64("failure_timestamp"),
+		FailureMessage:        object.string("failure_message"),
+		Tasks:                 map[string]FlinkTaskCheckpointStatistics{},
+	}
+
+	for vertexId, task := range object.objectMap("tasks") {
+		details.Tasks[vertexId] = decodeTaskCheckpointStatistics(task, version)
+	}
+
+	return details, nil
+}
+
+// decodeTaskCheckpointDetails decodes the response of GET /jobs/:jobid/checkpoints/details/:checkpointid/subtasks/:vertexid
+func decodeTaskCheckpointDetails(data []byte, version FlinkVersion) (*FlinkTaskCheckpointDetails, error) {
+	object, err := decodeFlinkObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &FlinkTaskCheckpointDetails{
+		FlinkTaskCheckpointStatistics: decodeTaskCheckpointStatistics(object, version),
+		Subtasks:                      make([]FlinkSubtaskCheckpointStatistics, 0),
+	}
+
+	for _, subtask := range object.objects("subtasks") {
+		checkpoint := subtask.object("checkpoint")
+		alignment := subtask.object("alignment")
+
+		details.Subtasks = append(details.Subtasks, FlinkSubtaskCheckpointStatistics{
+			Index:            subtask.int("index"),
+			Status:           subtask.string("status"),
+			AckTimestamp:     subtask.int64("ack_timestamp"),
+			EndToEndDuration: subtask.int64("end_to_end_duration"),
+			StateSize:        subtask.int64("state_size"),
+			CheckpointedSize: checkpointedSize(subtask, version),
+			Checkpoint: FlinkSubtaskCheckpointDuration{
+				Sync:  checkpoint.int64("sync"),
+				Async: checkpoint.int64("async"),
+			},
+			Alignment: FlinkSubtaskCheckpointAlignment{
+				Buffered:  alignment.int64("buffered"),
+				Processed: alignment.int64("processed"),
+				Persisted: alignment.int64("persisted"),
+				Duration:  alignment.int64("duration"),
+			},
+			StartDelay:          subtask.int64("start_delay"),
+			UnalignedCheckpoint: subtask.bool("unaligned_checkpoint"),
+			Aborted:             subtask.bool("aborted"),
+		})
+	}
+
+	return details, nil
+}
+
+func decodeTaskCheckpointStatistics(task flinkObject, version FlinkVersion) FlinkTaskCheckpointStatistics {
+	return FlinkTaskCheckpointStatistics{
+		Id:                      task.int64("id"),
+		Status:                  task.string("status"),
+		LatestAckTimestamp:      task.int64("latest_ack_timestamp"),
+		CheckpointedSize:        checkpointedSize(task, version),
+		StateSize:               task.int64("state_size"),
+		EndToEndDuration:        task.int64("end_to_end_duration"),
+		AlignmentBuffered:       task.int64("alignment_buffered"),
+		ProcessedData:           task.int64("processed_data"),
+		PersistedData:           task.int64("persisted_data"),
+		NumSubtasks:             task.int("num_subtasks"),
+		NumAcknowledgedSubtasks: task.int("num_acknowledged_subtasks"),
+	}
+}
+
+// decodeCheckpointConfig decodes the response of GET /jobs/:jobid/checkpoints/config. The checkpoint storage, the
+// aligned checkpoint timeout and the changelog were added over the 1.x versions and stay empty for older clusters.
+func decodeCheckpointConfig(data []byte) (*FlinkCheckpointConfig, error) {
+	object, err := decodeFlinkObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	externalization := object.object("externalization")
+
+	return &FlinkCheckpointConfig{
+		Mode:          object.string("mode"),
+		Interval:      object.int64("interval"),
+		Timeout:       object.int64("timeout"),
+		MinPause:      object.int64("min_pause"),
+		MaxConcurrent: object.int("max_concurrent"),
+		Externalization: FlinkCheckpointExternalization{
+			Enabled:              externalization.bool("enabled"),
+			DeleteOnCancellation: externalization.bool("delete_on_cancellation"),
+		},
+		StateBackend:                object.string("state_backend"),
+		CheckpointStorage:           object.string("checkpoint_storage"),
+		UnalignedCheckpoints:        object.bool("unaligned_checkpoints"),
+		TolerableFailedCheckpoints:  object.int("tolerable_failed_checkpoints"),
+		AlignedCheckpointTimeout:    object.int64("aligned_checkpoint_timeout"),
+		CheckpointsAfterTasksFinish: object.bool("checkpoints_after_tasks_finish"),
+		StateChangelogEnabled:       object.bool("state_changelog_enabled"),
+	}, nil
+}
+
+// decodeJobDetails decodes the response of GET /jobs/:jobid. Older clusters don't report the job type next to the
+// job, it is taken from the plan included in the response then.
+func decodeJobDetails(data []byte) (*FlinkJobDetails, error) {
+	object, err := decodeFlinkObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &FlinkJobDetails{
+		Jid:            object.string("jid"),
+		Name:           object.string("name"),
+		State:          object.string("state"),
+		JobType:        object.string("job-type"),
+		StartTime:      object.int64("start-time"),
+		EndTime:        object.int64("end-time"),
+		Duration:       object.int64("duration"),
+		MaxParallelism: object.int("maxParallelism"),
+		Now:            object.int64("now"),
+		Vertices:       make([]FlinkJobVertex, 0),
+		StatusCounts:   object.intMap("status-counts"),
+	}
+
+	if details.JobType == "" {
+		details.JobType = object.object("plan").string("type")
+	}
+
+	for _, vertex := range object.objects("vertices") {
+		metrics := vertex.object("metrics")
+
+		details.Vertices = append(details.Vertices, FlinkJobVertex{
+			Id:             vertex.string("id"),
+			Name:           vertex.string("name"),
+			Parallelism:    vertex.int("parallelism"),
+			MaxParallelism: vertex.int("maxParallelism"),
+			Status:         vertex.string("status"),
+			StartTime:      vertex.int64("start-time"),
+			EndTime:        vertex.int64("end-time"),
+			Duration:       vertex.int64("duration"),
+			Tasks:          vertex.intMap("tasks"),
+			Metrics: FlinkJobVertexMetrics{
+				ReadBytes:                    metrics.int64("read-bytes"),
+				ReadBytesComplete:            metrics.bool("read-bytes-complete"),
+				WriteBytes:                   metrics.int64("write-bytes"),
+				WriteBytesComplete:           metrics.bool("write-bytes-complete"),
+				ReadRecords:                  metrics.int64("read-records"),
+				ReadRecordsComplete:          metrics.bool("read-records-complete"),
+				WriteRecords:                 metrics.int64("write-records"),
+				WriteRecordsComplete:         metrics.bool("write-records-complete"),
+				AccumulatedBackpressuredTime: metrics.int64("accumulated-backpressured-time"),
+				AccumulatedIdleTime:          metrics.int64("accumulated-idle-time"),
+				AccumulatedBusyTime:          metrics.int64("accumulated-busy-time"),
+			},
+		})
+	}
+
+	return details, nil
+}
+
+func decodeCheckpointSummary(summary flinkObject, version FlinkVersion) FlinkCheckpointSummary {
+	stats := func(object flinkObject) FlinkCheckpointSummaryStats {
+		return FlinkCheckpointSummaryStats{
+			Min: object.int64("min"),
+			Max: object.int64("max"),
+			Avg: object.int64("avg"),
+		}
+	}
+
+	checkpointed := summary.object("checkpointed_size")
+	if version.Before(flinkVersion1_15) || checkpointed == nil {
+		checkpointed = summary.object("state_size")
+	}
+
+	return FlinkCheckpointSummary{
+		CheckpointedSize:  stats(checkpointed),
+		StateSize:         stats(summary.object("state_size")),
+		EndToEndDuration:  stats(summary.object("end_to_end_duration")),
+		AlignmentBuffered: stats(summary.object("alignment_buffered")),
+		ProcessedData:     stats(summary.object("processed_data")),
+		PersistedData:     stats(summary.object("persisted_data")),
+	}
+}
+
+func decodeCheckpointDetail(checkpoint flinkObject, version FlinkVersion) FlinkCheckpointDetail {
+	checkpointType := checkpoint.string("checkpoint_type")
+
+	isSavepoint := checkpoint.bool("is_savepoint")
+	if !checkpoint.has("is_savepoint") {
+		isSavepoint = strings.Contains(checkpointType, "SAVEPOINT")
+	}
+
+	return FlinkCheckpointDetail{
+		Id:                      checkpoint.int64("id"),
+		Status:                  checkpoint.string("status"),
+		IsSavepoint:             isSavepoint,
+		TriggerTimestamp:        checkpoint.int64("trigger_timestamp"),
+		LatestAckTimestamp:      checkpoint.int64("latest_ack_timestamp"),
+		CheckpointedSize:        checkpointedSize(checkpoint, version),
+		StateSize:               checkpoint.int64("state_size"),
+		EndToEndDuration:        checkpoint.int64("end_to_end_duration"),
+		AlignmentBuffered:       checkpoint.int64("alignment_buffered"),
+		ProcessedData:           checkpoint.int64("processed_data"),
+		PersistedData:           checkpoint.int64("persisted_data"),
+		NumSubtasks:             checkpoint.int("num_subtasks"),
+		NumAcknowledgedSubtasks: checkpoint.int("num_acknowledged_subtasks"),
+		CheckpointType:          checkpointType,
+		ExternalPath:            checkpoint.string("external_path"),
+		Discarded:               checkpoint.bool("discarded"),
+	}
+}
+
+func decodeRestoredCheckpoint(restored flinkObject) *FlinkRestoredCheckpoint {
+	return &FlinkRestoredCheckpoint{
+		Id:               restored.int64("id"),
+		RestoreTimestamp: restored.int64("restore_timestamp"),
+		IsSavepoint:      restored.bool("is_savepoint"),
+		ExternalPath:     restored.string("external_path"),
+	}
+}
+
+// checkpointedSize returns the size of the data uploaded for a checkpoint. Before Flink 1.15 this was reported as
+// state_size, which is the full size of the state since then.
+func checkpointedSize(object flinkObject, version FlinkVersion) int64 {
+	if version.Before(flinkVersion1_15) {
+		return object.int64("state_size")
+	}
+
+	return object.int64("checkpointed_size", "state_size")
+}
+
+// decodeJobExceptions decodes the response of GET /jobs/:jobid/exceptions. Flink 2.0 dropped the legacy root
+// exception fields, responses without an exception history fall back to them.
+func decodeJobExceptions(data []byte, version FlinkVersion) (*FlinkJobExceptions, error) {
+	object, err := decodeFlinkObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions := &FlinkJobExceptions{
+		ExceptionHistory: FlinkExceptionHistory{Entries: make([]FlinkExceptionEntry, 0)},
+	}
+
+	if history := object.object("exceptionHistory"); history != nil {
+		for _, entry := range history.objects("entries") {
+			exceptions.ExceptionHistory.Entries = append(exceptions.ExceptionHistory.Entries, decodeExceptionEntry(entry, version))
+		}
+
+		exceptions.ExceptionHistory.Truncated = history.bool("truncated")
+
+		return exceptions, nil
+	}
+
+	if rootException := object.string("root-exception"); rootException != "" {
+		exceptions.ExceptionHistory.Entries = append(exceptions.ExceptionHistory.Entries, FlinkExceptionEntry{
+			ExceptionName: exceptionNameOf(rootException),
+			Stacktrace:    rootException,
+			Timestamp:     object.int64("timestamp"),
+		})
+		exceptions.ExceptionHistory.Truncated = object.bool("truncated")
+	}
+
+	return exceptions, nil
+}
+
+// decodeExceptionEntry decodes an entry of the exception history.
+func decodeExceptionEntry(entry flinkObject, version FlinkVersion) FlinkExceptionEntry {
+	location, endpoint := decodeExceptionAddresses(entry, version)
+
+	decoded := FlinkExceptionEntry{
+		ExceptionName: entry.string("exceptionName"),
+		Stacktrace:    entry.string("stacktrace"),
+		Timestamp:     entry.int64("timestamp"),
+		TaskName:      entry.string("taskName"),
+		Location:      location,
+		Endpoint:      endpoint,
+		TaskManagerId: entry.string("taskManagerId"),
+		FailureLabels: entry.stringMap("failureLabels"),
+	}
+
+	for _, concurrent := range entry.objects("concurrentExceptions") {
+		decoded.ConcurrentExceptions = append(decoded.ConcurrentExceptions, decodeExceptionEntry(concurrent, version))
+	}
+
+	return decoded
+}
+
+// decodeExceptionAddresses returns the location and endpoint of the task manager of an exception. Flink reports the
+// location until 2.0, which removed it, and the endpoint since 1.19. The field a version doesn't report is filled
+// from the other one, so both are set for every version. For an unknown version both fields fall back to each other.
+func decodeExceptionAddresses(entry flinkObject, version FlinkVersion) (location string, endpoint string) {
+	switch {
+	case !version.IsKnown():
+		return entry.string("location", "endpoint"), entry.string("endpoint", "location")
+	case version.Before(flinkVersion1_19):
+		location = entry.string("location")
+
+		return location, location
+	case version.Before(flinkVersion2_0):
+		return entry.string("location"), entry.string("endpoint")
+	default:
+		endpoint = entry.string("endpoint")
+
+		return endpoint, endpoint
+	}
+}
+
+// exceptionNameOf returns the class name of the exception a stack trace starts with.
+func exceptionNameOf(stacktrace string) string {
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(stacktrace), "\n")
+	name, _, _ := strings.Cut(firstLine, ":")
+
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,234 @@
+package internal
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseFlinkVersion(t *testing.T) {
+	cases := map[string]FlinkVersion{
+		"1.18.1":        {Major: 1, Minor: 18},
+		"1.20-SNAPSHOT": {Major: 1, Minor: 20},
+		"2.0.0":         {Major: 2, Minor: 0},
+		"v1_16":         {Major: 1, Minor: 16},
+	}
+
+	for value, expected := range cases {
+		if version, ok := ParseFlinkVersion(value); !ok || version != expected {
+			t.Errorf("expected %s to parse as %s, got %s", value, expected, version)
+		}
+	}
+
+	if _, ok := ParseFlinkVersion("latest"); ok {
+		t.Errorf("expected an invalid version to be rejected")
+	}
+}
+
+func TestDecodeCheckpointStatistics(t *testing.T) {
+	response := []byte(`{
+		"counts": {"restored": 1, "total": 12, "in_progress": 0, "completed": 11, "failed": "1"},
+		"summary": {"checkpointed_size": {"min": 10, "max": 30, "avg": 20.5}, "state_size": {"min": 100, "max": 300, "avg": 200}},
+		"latest": {
+			"completed": {"id": 12, "status": "COMPLETED", "checkpoint_type": "CHECKPOINT", "checkpointed_size": 30, "state_size": 300},
+			"savepoint": null,
+			"failed": null,
+			"restored": {"id": 7, "restore_timestamp": 1700000000000, "is_savepoint": true, "external_path": "s3://bucket/savepoint-7"}
+		},
+		"history": [{"id": 12, "status": "COMPLETED", "checkpoint_type": "SAVEPOINT", "state_size": 300}]
+	}`)
+
+	stats, err := decodeCheckpointStatistics(response, flinkVersion2_0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.Counts.Failed != 1 || stats.Summary.CheckpointedSize.Avg != 20 || stats.Summary.StateSize.Max != 300 {
+		t.Errorf("unexpected counts %+v or summary %+v", stats.Counts, stats.Summary)
+	}
+
+	if stats.Latest == nil || stats.Latest.Id != 12 || stats.Latest.CheckpointedSize != 30 || stats.Latest.StateSize != 300 {
+		t.Errorf("unexpected latest checkpoint %+v", stats.Latest)
+	}
+
+	if stats.Restored == nil || stats.Restored.Id != 7 || !stats.Restored.IsSavepoint {
+		t.Errorf("unexpected restored checkpoint %+v", stats.Restored)
+	}
+
+	if len(stats.History) != 1 || !stats.History[0].IsSavepoint || stats.History[0].CheckpointedSize != 300 {
+		t.Errorf("unexpected history %+v", stats.History)
+	}
+}
+
+func TestDecodeJobExceptions(t *testing.T) {
+	response := []byte(`{"exceptionHistory": {"truncated": true, "entries": [
+		{"exceptionName": "java.io.IOException", "timestamp": 1700000000000, "location": "10.0.0.1:6121", "failureLabels": {"type": "user"},
+			"concurrentExceptions": [{"exceptionName": "java.lang.RuntimeException", "location": "10.0.0.2:6121"}]}
+	]}}`)
+
+	exceptions, err := decodeJobExceptions(response, FlinkVersion{Major: 1, Minor: 18})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := exceptions.ExceptionHistory.Entries
+	if !exceptions.ExceptionHistory.Truncated || len(entries) != 1 || entries[0].Endpoint != "10.0.0.1:6121" || entries[0].FailureLabels["type"] != "user" {
+		t.Fatalf("unexpected exception history %+v", exceptions.ExceptionHistory)
+	}
+
+	if len(entries[0].ConcurrentExceptions) != 1 || entries[0].ConcurrentExceptions[0].Location != "10.0.0.2:6121" {
+		t.Errorf("unexpected concurrent exceptions %+v", entries[0].ConcurrentExceptions)
+	}
+
+	legacy, err := decodeJobExceptions([]byte(`{"root-exception": "java.lang.IllegalStateException: boom\n\tat Job.main", "timestamp": 42}`), FlinkVersion{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if entries = legacy.ExceptionHistory.Entries; len(entries) != 1 || entries[0].ExceptionName != "java.lang.IllegalStateException" || entries[0].Timestamp != 42 {
+		t.Errorf("unexpected legacy exception history %+v", legacy.ExceptionHistory)
+	}
+}
+
+func TestDecodeExceptionAddresses(t *testing.T) {
+	entry := flinkObject{"location": json.RawMessage(`"tm-1.flink:6121"`), "endpoint": json.RawMessage(`"10.0.0.1:6122"`)}
+
+	cases := map[string]struct {
+		version  FlinkVersion
+		entry    flinkObject
+		location string
+		endpoint string
+	}{
+		"1.18 only reports the location": {version: FlinkVersion{Major: 1, Minor: 18}, entry: flinkObject{"location": entry["location"]}, location: "tm-1.flink:6121", endpoint: "tm-1.flink:6121"},
+		"1.19 reports both":              {version: flinkVersion1_19, entry: entry, location: "tm-1.flink:6121", endpoint: "10.0.0.1:6122"},
+		"2.0 only reports the endpoint":  {version: flinkVersion2_0, entry: flinkObject{"endpoint": entry["endpoint"]}, location: "10.0.0.1:6122", endpoint: "10.0.0.1:6122"},
+		"unknown version":                {entry: flinkObject{"endpoint": entry["endpoint"]}, location: "10.0.0.1:6122", endpoint: "10.0.0.1:6122"},
+	}
+
+	for name, tc := range cases {
+		if location, endpoint := decodeExceptionAddresses(tc.entry, tc.version); location != tc.location || endpoint != tc.endpoint {
+			t.Errorf("%s: expected location %q and endpoint %q, got %q and %q", name, tc.location, tc.endpoint, location, endpoint)
+		}
+	}
+}
+
+func TestDecodeTaskCheckpointDetails(t *testing.T) {
+	response := []byte(`{"id": 12, "status": "COMPLETED", "state_size": 300, "num_subtasks": 2, "subtasks": [
+		{"index": 0, "status": "completed", "state_size": "150", "checkpoint": {"sync": 3, "async": 40}, "alignment": {"duration": 7}, "unaligned_checkpoint": "true"},
+		{"index": 1, "status": "pending_or_failed"}
+	]}`)
+
+	details, err := decodeTaskCheckpointDetails(response, FlinkVersion{Major: 1, Minor: 14})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if details.Id != 12 || details.CheckpointedSize != 300 || details.NumSubtasks != 2 || len(details.Subtasks) != 2 {
+		t.Fatalf("unexpected checkpoint details %+v", details)
+	}
+
+	subtask := details.Subtasks[0]
+	if subtask.CheckpointedSize != 150 || subtask.Checkpoint.Async != 40 || subtask.Alignment.Duration != 7 || !subtask.UnalignedCheckpoint {
+		t.Errorf("unexpected subtask %+v", subtask)
+	}
+}
+
+func TestDecodeFlinkResponse(t *testing.T) {
+	type nested struct {
+		Ratio float64 `json:"ratio"`
+	}
+
+	type response struct {
+		Name     string            `json:"name"`
+		Count    int64             `json:"count"`
+		Enabled  bool              `json:"enabled"`
+		Nested   *nested           `json:"nested"`
+		Items    []nested          `json:"items"`
+		Labels   map[string]string `json:"labels"`
+		Missing  string            `json:"missing"`
+		Mismatch []string          `json:"mismatch"`
+	}
+
+	var decoded response
+
+	data := []byte(`{"name": 42, "count": "7", "enabled": "true", "nested": {"ratio": "0.5"}, "items": [{"ratio": 1}, 3], "labels": {"a": "b"}, "mismatch": {"a": 1}}`)
+	if err := decodeFlinkResponse(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := response{Name: "42", Count: 7, Enabled: true, Nested: &nested{Ratio: 0.5}, Items: []nested{{Ratio: 1}, {}}, Labels: map[string]string{"a": "b"}}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %+v, got %+v", expected, decoded)
+	}
+
+	var metrics []FlinkMetric
+	if err := decodeFlinkResponse([]byte(`[{"id": "numRestarts", "value": 3}]`), &metrics); err != nil || len(metrics) != 1 || metrics[0].Value != "3" {
+		t.Errorf("unexpected metrics %+v: %v", metrics, err)
+	}
+
+	if err := decodeFlinkResponse([]byte(`<html>`), &decoded); err == nil {
+		t.Errorf("expected a response which is no JSON to fail")
+	}
+}
+
+func TestTranslateMetricNames(t *testing.T) {
+	names, _ := translateMetricNames([]string{"fullRestarts", "uptime", "lastCheckpointSize"}, flinkVersion2_0)
+	if !slices.Equal(names, []string{"numRestarts", "runningTime", "lastCheckpointSize"}) {
+		t.Errorf("unexpected metric names for Flink 2.0: %v", names)
+	}
+
+	names, requested := translateMetricNames([]string{"numRestarts", "runningTime"}, FlinkVersion{})
+	if !slices.Equal(names, []string{"numRestarts", "fullRestarts", "runningTime", "uptime"}) {
+		t.Fatalf("unexpected metric names for an unknown version: %v", names)
+	}
+
+	metrics := renameMetrics([]FlinkMetric{
+		{Id: "fullRestarts", Value: "3"},
+		{Id: "numRestarts", Value: "4"},
+		{Id: "uptime", Value: "1000"},
+	}, requested)
+
+	expected := []FlinkMetric{{Id: "numRestarts", Value: "4"}, {Id: "runningTime", Value: "1000"}}
+	if !slices.Equal(metrics, expected) {
+		t.Errorf("expected %+v, got %+v", expected, metrics)
+	}
+
+	// a caller asking for both names of a renamed metric gets both of them
+	_, requested = translateMetricNames([]string{"numRestarts", "fullRestarts"}, FlinkVersion{})
+	metrics = renameMetrics([]FlinkMetric{{Id: "fullRestarts", Value: "3"}, {Id: "numRestarts", Value: "4"}}, requested)
+
+	expected = []FlinkMetric{{Id: "numRestarts", Value: "4"}, {Id: "fullRestarts", Value: "3"}}
+	if !slices.Equal(metrics, expected) {
+		t.Errorf("expected %+v, got %+v", expected, metrics)
+	}
+
+	names, requested = translateMetricNames([]string{"numRestarts", "fullRestarts"}, flinkVersion2_0)
+	metrics = renameMetrics([]FlinkMetric{{Id: "numRestarts", Value: "4"}}, requested)
+
+	expected = []FlinkMetric{{Id: "numRestarts", Value: "4"}, {Id: "fullRestarts", Value: "4"}}
+	if !slices.Equal(names, []string{"numRestarts"}) || !slices.Equal(metrics, expected) {
+		t.Errorf("expected %+v from %v, got %+v", expected, names, metrics)
+	}
+}
+
+func TestSetClusterVersionKeepsKnownVersion(t *testing.T) {
+	client := newTestFlinkClient(FlinkClientSettings{VersionTtl: time.Minute})
+
+	client.SetClusterVersion("http://flink", "v1_18")
+	detectedAt := client.versions["http://flink"].detectedAt
+
+	client.SetClusterVersion("http://flink", "v1_20")
+	if known := client.versions["http://flink"]; known.version != (FlinkVersion{Major: 1, Minor: 18}) || !known.detectedAt.Equal(detectedAt) {
+		t.Fatalf("expected the known version to be kept, got %+v", known)
+	}
+
+	// an expired version is replaced, e.g. after the cluster was upgraded
+	client.versions["http://flink"] = clusterVersion{version: FlinkVersion{Major: 1, Minor: 18}, detectedAt: time.Now().Add(-time.Hour)}
+
+	client.SetClusterVersion("http://flink", "v1_20")
+	if known := client.versions["http://flink"]; known.version != (FlinkVersion{Major: 1, Minor: 20}) {
+		t.Errorf("expected the expired version to be replaced, got %+v", known)
+	}
+}
@@ -0,0 +1,265 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/justtrackio/gosoline/pkg/appctx"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+const (
+	EndpointStrategyIngress = "ingress"
+	EndpointStrategyService = "service"
+	EndpointStrategyProxy   = "proxy"
+
+	flinkEndpointConfigKey = "flink.endpoint"
+)
+
+// FlinkEndpointSettings configures how the REST API of a deployment is reached. The strategies are tried in order
+// and the first one which can resolve an endpoint for the deployment is used. The service and proxy strategies
+// never fail, so they can only be the last strategy.
+type FlinkEndpointSettings struct {
+	Strategies    []string `cfg:"strategies"`
+	IngressScheme string   `cfg:"ingress_scheme" default:"https"`
+	IngressHost   string   `cfg:"ingress_host"`
+	ServiceScheme string   `cfg:"service_scheme" default:"http"`
+	ServicePort   int      `cfg:"service_port" default:"8081"`
+	ServiceDomain string   `cfg:"service_domain"`
+}
+
+type flinkEndpointResolverCtxKey struct{}
+
+// FlinkEndpointResolver resolves the URL of the REST API of a deployment with the strategies configured for its
+// namespace in flink.endpoint.namespaces, falling back to flink.endpoint.default.
+type FlinkEndpointResolver struct {
+	defaults           *FlinkEndpointSettings
+	namespaces         map[string]*FlinkEndpointSettings
+	apiServerURL       *url.URL
+	apiServerTransport http.RoundTripper
+}
+
+func ProvideFlinkEndpointResolver(ctx context.Context, config cfg.Config, logger log.Logger) (*FlinkEndpointResolver, error) {
+	return appctx.Provide(ctx, flinkEndpointResolverCtxKey{}, func() (*FlinkEndpointResolver, error) {
+		resolver, err := newFlinkEndpointResolver(config)
+		if err != nil {
+			return nil, err
+		}
+
+		if !resolver.usesStrategy(EndpointStrategyProxy) {
+			return resolver, nil
+		}
+
+		k8sService, err := ProvideK8sService(ctx, config, logger)
+		if err != nil {
+			return nil, fmt.Errorf("could not create k8s service: %w", err)
+		}
+
+		if resolver.apiServerURL, resolver.apiServerTransport, err = k8sService.ApiServerTransport(); err != nil {
+			return nil, err
+		}
+
+		return resolver, nil
+	})
+}
+
+// newFlinkEndpointResolver reads the default and namespace settings, the transport of the proxy strategy is left to
+// the caller.
+func newFlinkEndpointResolver(config cfg.Config) (*FlinkEndpointResolver, error) {
+	resolver := &FlinkEndpointResolver{
+		defaults:   &FlinkEndpointSettings{},
+		namespaces: map[string]*FlinkEndpointSettings{},
+	}
+
+	if err := config.UnmarshalKey(flinkEndpointConfigKey+".default", resolver.defaults); err != nil {
+		return nil, fmt.Errorf("could not unmarshal flink endpoint settings: %w", err)
+	}
+
+	if err := resolver.defaults.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid default flink endpoint settings: %w", err)
+	}
+
+	namespaces, err := config.GetStringMap(flinkEndpointConfigKey+".namespaces", map[string]any{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read flink endpoint namespace settings: %w", err)
+	}
+
+	for namespace := range namespaces {
+		if resolver.namespaces[namespace], err = readNamespaceEndpointSettings(config, namespace); err != nil {
+			return nil, err
+		}
+
+		if err = resolver.namespaces[namespace].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid flink endpoint settings of namespace %s: %w", namespace, err)
+		}
+	}
+
+	return resolver, nil
+}
+
+// Validate rejects unknown strategies and strategies listed after the service or proxy strategy. Those always
+// resolve an endpoint as they don't depend on the deployment, so the strategies after them would never be tried.
+func (s *FlinkEndpointSettings) Validate() error {
+	if len(s.Strategies) == 0 {
+		return fmt.Errorf("no strategies configured")
+	}
+
+	for i, strategy := range s.Strategies {
+		switch strategy {
+		case EndpointStrategyIngress:
+		case EndpointStrategyService, EndpointStrategyProxy:
+			if i < len(s.Strategies)-1 {
+				return fmt.Errorf("the %s strategy always resolves an endpoint, the strategies %v after it would never be used", strategy, s.Strategies[i+1:])
+			}
+		default:
+			return fmt.Errorf("unknown strategy %q", strategy)
+		}
+	}
+
+	return nil
+}
+
+// readNamespaceEndpointSettings reads the settings of a namespace with the default settings applied. Slices are
+// merged element wise with the defaults, so the strategies of the namespace are read before the defaults are
+// applied as they replace the default strategies.
+func readNamespaceEndpointSettings(config cfg.Config, namespace string) (*FlinkEndpointSettings, error) {
+	var err error
+	var strategies []string
+
+	settings := &FlinkEndpointSettings{}
+	key := flinkEndpointConfigKey + ".namespaces." + namespace
+
+	if config.IsSet(key + ".strategies") {
+		if strategies, err = config.GetStringSlice(key + ".strategies"); err != nil {
+			return nil, fmt.Errorf("could not read flink endpoint strategies of namespace %s: %w", namespace, err)
+		}
+	}
+
+	if err = config.UnmarshalKey(key, settings, cfg.UnmarshalWithDefaultsFromKey(flinkEndpointConfigKey+".default", ".")); err != nil {
+		return nil, fmt.Errorf("could not unmarshal flink endpoint settings of namespace %s: %w", namespace, err)
+	}
+
+	if strategies != nil {
+		settings.Strategies = strategies
+	}
+
+	return settings, nil
+}
+
+// Resolve returns the base URL of the REST API of the deployment.
+func (r *FlinkEndpointResolver) Resolve(deployment *FlinkDeployment) (string, error) {
+	settings := r.settingsFor(deployment.Namespace)
+	reasons := make([]string, 0, len(settings.Strategies))
+
+	for _, strategy := range settings.Strategies {
+		var endpoint string
+		var err error
+
+		switch strategy {
+		case EndpointStrategyIngress:
+			endpoint, err = r.resolveIngress(deployment, settings)
+		case EndpointStrategyService:
+			endpoint = r.resolveService(deployment, settings)
+		case EndpointStrategyProxy:
+			endpoint = r.resolveProxy(deployment)
+		}
+
+		if err == nil {
+			return endpoint, nil
+		}
+
+		reasons = append(reasons, fmt.Sprintf("%s: %s", strategy, err))
+	}
+
+	return "", fmt.Errorf("could not resolve the flink endpoint of deployment %s/%s (%s)", deployment.Namespace, deployment.Name, strings.Join(reasons, ", "))
+}
+
+// Transport returns a transport which authenticates requests to the API server for endpoints resolved by the
+// proxy strategy and uses the default transport for all other requests.
+func (r *FlinkEndpointResolver) Transport() http.RoundTripper {
+	return &flinkEndpointTransport{resolver: r}
+}
+
+func (r *FlinkEndpointResolver) settingsFor(namespace string) *FlinkEndpointSettings {
+	if settings, ok := r.namespaces[namespace]; ok {
+		return settings
+	}
+
+	return r.defaults
+}
+
+func (r *FlinkEndpointResolver) usesStrategy(strategy string) bool {
+	if slices.Contains(r.defaults.Strategies, strategy) {
+		return true
+	}
+
+	for _, settings := range r.namespaces {
+		if slices.Contains(settings.Strategies, strategy) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveIngress expands the ingress template of the operator. Path based templates like
+// "/{{namespace}}/{{name}}(/|$)(.*)" contain a regular expression for the rewrite rule, which is cut off,
+// and need the configured ingress host as they don't contain one.
+func (r *FlinkEndpointResolver) resolveIngress(deployment *FlinkDeployment, settings *FlinkEndpointSettings) (string, error) {
+	if deployment.Spec.Ingress == nil || deployment.Spec.Ingress.Template == "" {
+		return "", fmt.Errorf("no ingress configured")
+	}
+
+	endpoint := strings.NewReplacer("{{name}}", deployment.Name, "{{namespace}}", deployment.Namespace).Replace(deployment.Spec.Ingress.Template)
+	if index := strings.Index(endpoint, "("); index >= 0 {
+		endpoint = endpoint[:index]
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	if strings.HasPrefix(endpoint, "/") {
+		if settings.IngressHost == "" {
+			return "", fmt.Errorf("the ingress template has no host and no ingress_host is configured")
+		}
+
+		endpoint = strings.TrimSuffix(settings.IngressHost, "/") + endpoint
+	}
+
+	if strings.Contains(endpoint, "://") {
+		return endpoint, nil
+	}
+
+	return settings.IngressScheme + "://" + endpoint, nil
+}
+
+// resolveService returns the URL of the REST service the operator creates for every deployment.
+func (r *FlinkEndpointResolver) resolveService(deployment *FlinkDeployment, settings *FlinkEndpointSettings) string {
+	host := deployment.Name + "-rest." + deployment.Namespace
+	if settings.ServiceDomain != "" {
+		host += "." + settings.ServiceDomain
+	}
+
+	return settings.ServiceScheme + "://" + host + ":" + strconv.Itoa(settings.ServicePort)
+}
+
+// resolveProxy returns the URL of the REST service through the service proxy of the API server.
+func (r *FlinkEndpointResolver) resolveProxy(deployment *FlinkDeployment) string {
+	return r.apiServerURL.String() + "/api/v1/namespaces/" + url.PathEscape(deployment.Namespace) + "/services/" + url.PathEscape(deployment.Name+"-rest") + ":rest/proxy"
+}
+
+type flinkEndpointTransport struct {
+	resolver *FlinkEndpointResolver
+}
+
+func (t *flinkEndpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.resolver.apiServerURL != nil && req.URL.Host == t.resolver.apiServerURL.Host {
+		return t.resolver.apiServerTransport.RoundTrip(req)
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestFlinkEndpointResolver(t *testing.T, endpoint map[string]any) *FlinkEndpointResolver {
+	config := cfg.New(map[string]any{"flink": map[string]any{"endpoint": endpoint}})
+
+	resolver, err := newFlinkEndpointResolver(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resolver.apiServerURL = &url.URL{Scheme: "https", Host: "kubernetes.default.svc"}
+
+	return resolver
+}
+
+func testDeployment(namespace string, ingressTemplate string) *FlinkDeployment {
+	deployment := &FlinkDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "orders"}}
+	if ingressTemplate != "" {
+		deployment.Spec.Ingress = &FlinkDeploymentIngress{Template: ingressTemplate}
+	}
+
+	return deployment
+}
+
+func TestFlinkEndpointResolverResolve(t *testing.T) {
+	resolver := newTestFlinkEndpointResolver(t, map[string]any{
+		"default": map[string]any{
+			"strategies":     []any{"ingress", "service"},
+			"ingress_scheme": "https",
+			"ingress_host":   "flink.example.com",
+			"service_scheme": "http",
+			"service_port":   8081,
+		},
+		"namespaces": map[string]any{
+			"internal": map[string]any{
+				"strategies":     []any{"service"},
+				"service_domain": "svc.cluster.local",
+			},
+			"restricted": map[string]any{
+				"strategies": []any{"ingress", "proxy"},
+			},
+		},
+	})
+
+	cases := map[string]struct {
+		deployment *FlinkDeployment
+		expected   string
+	}{
+		"host template": {
+			deployment: testDeployment("streaming", "{{name}}.{{namespace}}.flink.example.com"),
+			expected:   "https://orders.streaming.flink.example.com",
+		},
+		"host template with scheme": {
+			deployment: testDeployment("streaming", "http://{{namespace}}-{{name}}.example.com/"),
+			expected:   "http://streaming-orders.example.com",
+		},
+		"path template": {
+			deployment: testDeployment("streaming", "/{{namespace}}/{{name}}(/|$)(.*)"),
+			expected:   "https://flink.example.com/streaming/orders",
+		},
+		"service fallback": {
+			deployment: testDeployment("streaming", ""),
+			expected:   "http://orders-rest.streaming:8081",
+		},
+		"namespace strategies": {
+			deployment: testDeployment("internal", "{{name}}.{{namespace}}.flink.example.com"),
+			expected:   "http://orders-rest.internal.svc.cluster.local:8081",
+		},
+		"namespace proxy": {
+			deployment: testDeployment("restricted", "/{{namespace}}/{{name}}(/|$)(.*)"),
+			expected:   "https://flink.example.com/restricted/orders",
+		},
+		"namespace proxy fallback": {
+			deployment: testDeployment("restricted", ""),
+			expected:   "https://kubernetes.default.svc/api/v1/namespaces/restricted/services/orders-rest:rest/proxy",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			endpoint, err := resolver.Resolve(c.deployment)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if endpoint != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, endpoint)
+			}
+		})
+	}
+}
+
+func TestFlinkEndpointResolverIngressOnly(t *testing.T) {
+	resolver := newTestFlinkEndpointResolver(t, map[string]any{
+		"default": map[string]any{"strategies": []any{"ingress"}},
+	})
+
+	if _, err := resolver.Resolve(testDeployment("streaming", "/{{namespace}}/{{name}}")); err == nil {
+		t.Errorf("expected a path template without an ingress host to fail")
+	}
+
+	if _, err := resolver.Resolve(testDeployment("streaming", "")); err == nil {
+		t.Errorf("expected a deployment without an ingress to fail")
+	}
+}
+
+func TestFlinkEndpointSettingsValidate(t *testing.T) {
+	cases := map[string]struct {
+		strategies []string
+		valid      bool
+	}{
+		"ingress then service":  {strategies: []string{"ingress", "service"}, valid: true},
+		"proxy only":            {strategies: []string{"proxy"}, valid: true},
+		"ingress after service": {strategies: []string{"service", "ingress"}},
+		"proxy after service":   {strategies: []string{"ingress", "service", "proxy"}},
+		"unknown strategy":      {strategies: []string{"ingress", "dns"}},
+		"no strategies":         {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := (&FlinkEndpointSettings{Strategies: c.strategies}).Validate()
+			if c.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !c.valid && err == nil {
+				t.Errorf("expected the strategies %v to be rejected", c.strategies)
+			}
+		})
+	}
+
+	config := cfg.New(map[string]any{"flink": map[string]any{"endpoint": map[string]any{
+		"default":    map[string]any{"strategies": []any{"ingress", "service"}},
+		"namespaces": map[string]any{"streaming": map[string]any{"strategies": []any{"service", "ingress"}}},
+	}}})
+
+	if _, err := newFlinkEndpointResolver(config); err == nil {
+		t.Errorf("expected a namespace listing a strategy after the service strategy to be rejected")
+	}
+}
@@ -12,6 +12,8 @@ type FlinkExceptionEntry struct {
 	TaskManagerId        string                `json:"taskManagerId"`
 	FailureLabels        map[string]string     `json:"failureLabels,omitempty"`
 	ConcurrentExceptions []FlinkExceptionEntry `json:"concurrentExceptions,omitempty"`
+	// Category is assigned by the ExceptionClassifier and not part of the response of Flink
+	Category *ExceptionCategory `json:"category,omitempty"`
 }
 
 // FlinkExceptionHistory holds the exception history for a Flink job.
@@ -20,7 +22,7 @@ type FlinkExceptionHistory struct {
 	Truncated bool                  `json:"truncated"`
 }
 
-// FlinkJobExceptions is the response from GET /jobs/:jobid/exceptions
+// FlinkJobExceptions is the response from GET /jobs/:jobid/exceptions as decoded by decodeJobExceptions
 type FlinkJobExceptions struct {
 	ExceptionHistory FlinkExceptionHistory `json:"exceptionHistory"`
 }
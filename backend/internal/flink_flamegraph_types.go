@@ -0,0 +1,29 @@
+package internal
+
+const (
+	FlameGraphTypeOnCpu  = "on_cpu"
+	FlameGraphTypeOffCpu = "off_cpu"
+	FlameGraphTypeMixed  = "mixed"
+
+	// flinkFlameGraphTypeFull is the name Flink uses for the mixed flame graph
+	flinkFlameGraphTypeFull = "full"
+
+	// flinkFlameGraphWaiting and flinkFlameGraphTerminated are the end timestamps of flame graphs whose sample
+	// is still being taken or which can't be sampled as the vertex is not running anymore
+	flinkFlameGraphWaiting    = -1
+	flinkFlameGraphTerminated = -2
+)
+
+// FlinkFlameGraph is the response from GET /jobs/:jobid/vertices/:vertexid/flamegraph
+type FlinkFlameGraph struct {
+	EndTimestamp int64                `json:"endTimestamp"`
+	Data         *FlinkFlameGraphNode `json:"data"`
+}
+
+// FlinkFlameGraphNode is a frame of the sampled stack traces. The value is the number of samples which
+// contained the frame, including the samples of its children.
+type FlinkFlameGraphNode struct {
+	Name     string                 `json:"name"`
+	Value    int64                  `json:"value"`
+	Children []*FlinkFlameGraphNode `json:"children"`
+}
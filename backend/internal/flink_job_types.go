@@ -0,0 +1,177 @@
+package internal
+
+// FlinkJobDetails is the response from GET /jobs/:jobid
+type FlinkJobDetails struct {
+	Jid            string           `json:"jid"`
+	Name           string           `json:"name"`
+	State          string           `json:"state"`
+	JobType        string           `json:"job-type"`
+	StartTime      int64            `json:"start-time"`
+	EndTime        int64            `json:"end-time"`
+	Duration       int64            `json:"duration"`
+	MaxParallelism int              `json:"maxParallelism"`
+	Now            int64            `json:"now"`
+	Vertices       []FlinkJobVertex `json:"vertices"`
+	StatusCounts   map[string]int   `json:"status-counts"`
+}
+
+// FlinkJobVertex is a single vertex of the job as returned in the job details.
+type FlinkJobVertex struct {
+	Id             string                `json:"id"`
+	Name           string                `json:"name"`
+	Parallelism    int                   `json:"parallelism"`
+	MaxParallelism int                   `json:"maxParallelism"`
+	Status         string                `json:"status"`
+	StartTime      int64                 `json:"start-time"`
+	EndTime        int64                 `json:"end-time"`
+	Duration       int64                 `json:"duration"`
+	Tasks          map[string]int        `json:"tasks"`
+	Metrics        FlinkJobVertexMetrics `json:"metrics"`
+}
+
+// FlinkJobVertexMetrics contains the IO metrics of a vertex summed over all of its subtasks.
+type FlinkJobVertexMetrics struct {
+	ReadBytes                    int64 `json:"read-bytes"`
+	ReadBytesComplete            bool  `json:"read-bytes-complete"`
+	WriteBytes                   int64 `json:"write-bytes"`
+	WriteBytesComplete           bool  `json:"write-bytes-complete"`
+	ReadRecords                  int64 `json:"read-records"`
+	ReadRecordsComplete          bool  `json:"read-records-complete"`
+	WriteRecords                 int64 `json:"write-records"`
+	WriteRecordsComplete         bool  `json:"write-records-complete"`
+	AccumulatedBackpressuredTime int64 `json:"accumulated-backpressured-time"`
+	AccumulatedIdleTime          int64 `json:"accumulated-idle-time"`
+	AccumulatedBusyTime          int64 `json:"accumulated-busy-time"`
+}
+
+// FlinkJobPlanResponse is the response from GET /jobs/:jobid/plan
+type FlinkJobPlanResponse struct {
+	Plan FlinkJobPlan `json:"plan"`
+}
+
+// FlinkJobPlan is the dataflow plan of a job.
+type FlinkJobPlan struct {
+	Jid   string             `json:"jid"`
+	Name  string             `json:"name"`
+	Type  string             `json:"type"`
+	Nodes []FlinkJobPlanNode `json:"nodes"`
+}
+
+// FlinkJobPlanNode is a vertex of the plan together with the inputs it consumes.
+type FlinkJobPlanNode struct {
+	Id               string              `json:"id"`
+	Parallelism      int                 `json:"parallelism"`
+	Operator         string              `json:"operator"`
+	OperatorStrategy string              `json:"operator_strategy"`
+	Description      string              `json:"description"`
+	Inputs           []FlinkJobPlanInput `json:"inputs,omitempty"`
+}
+
+// FlinkJobPlanInput is an edge from another vertex of the plan.
+type FlinkJobPlanInput struct {
+	Num          int    `json:"num"`
+	Id           string `json:"id"`
+	ShipStrategy string `json:"ship_strategy"`
+	Exchange     string `json:"exchange"`
+}
+
+// FlinkJobConfig is the response from GET /jobs/:jobid/config
+type FlinkJobConfig struct {
+	JobId           string               `json:"jid"`
+	Name            string               `json:"name"`
+	ExecutionConfig FlinkExecutionConfig `json:"execution-config"`
+}
+
+// FlinkExecutionConfig is the execution config of a job. The user config are the global job parameters the job
+// registered, e.g. the parsed program arguments.
+type FlinkExecutionConfig struct {
+	ExecutionMode   string            `json:"execution-mode"`
+	RestartStrategy string            `json:"restart-strategy"`
+	JobParallelism  int               `json:"job-parallelism"`
+	ObjectReuseMode bool              `json:"object-reuse-mode"`
+	UserConfig      map[string]string `json:"user-config"`
+}
+
+// JobGraph is the job DAG returned to the frontend.
+type JobGraph struct {
+	JobId     string           `json:"jobId"`
+	Name      string           `json:"name"`
+	State     string           `json:"state"`
+	JobType   string           `json:"jobType"`
+	StartTime int64            `json:"startTime"`
+	Duration  int64            `json:"duration"`
+	Vertices  []JobGraphVertex `json:"vertices"`
+	Edges     []JobGraphEdge   `json:"edges"`
+}
+
+// JobGraphVertex is a vertex of the job DAG with its IO counters.
+type JobGraphVertex struct {
+	Id             string         `json:"id"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description,omitempty"`
+	Parallelism    int            `json:"parallelism"`
+	MaxParallelism int            `json:"maxParallelism"`
+	Status         string         `json:"status"`
+	Tasks          map[string]int `json:"tasks"`
+	RecordsIn      int64          `json:"recordsIn"`
+	RecordsOut     int64          `json:"recordsOut"`
+	BytesIn        int64          `json:"bytesIn"`
+	BytesOut       int64          `json:"bytesOut"`
+}
+
+// JobGraphEdge connects the vertex producing data with the vertex consuming it.
+type JobGraphEdge struct {
+	Source       string `json:"source"`
+	Target       string `json:"target"`
+	ShipStrategy string `json:"shipStrategy"`
+	Exchange     string `json:"exchange,omitempty"`
+}
+
+// toJobGraph combines the job details and the plan into a single graph. The vertex order of the
+// job details is kept, which Flink returns in topological order.
+func toJobGraph(details *FlinkJobDetails, plan *FlinkJobPlan) *JobGraph {
+	descriptions := make(map[string]string, len(plan.Nodes))
+	for _, node := range plan.Nodes {
+		descriptions[node.Id] = node.Description
+	}
+
+	graph := &JobGraph{
+		JobId:     details.Jid,
+		Name:      details.Name,
+		State:     details.State,
+		JobType:   details.JobType,
+		StartTime: details.StartTime,
+		Duration:  details.Duration,
+		Vertices:  make([]JobGraphVertex, 0, len(details.Vertices)),
+		Edges:     []JobGraphEdge{},
+	}
+
+	for _, vertex := range details.Vertices {
+		graph.Vertices = append(graph.Vertices, JobGraphVertex{
+			Id:             vertex.Id,
+			Name:           vertex.Name,
+			Description:    descriptions[vertex.Id],
+			Parallelism:    vertex.Parallelism,
+			MaxParallelism: vertex.MaxParallelism,
+			Status:         vertex.Status,
+			Tasks:          vertex.Tasks,
+			RecordsIn:      vertex.Metrics.ReadRecords,
+			RecordsOut:     vertex.Metrics.WriteRecords,
+			BytesIn:        vertex.Metrics.ReadBytes,
+			BytesOut:       vertex.Metrics.WriteBytes,
+		})
+	}
+
+	for _, node := range plan.Nodes {
+		for _, input := range node.Inputs {
+			graph.Edges = append(graph.Edges, JobGraphEdge{
+				Source:       input.Id,
+				Target:       node.Id,
+				ShipStrategy: input.ShipStrategy,
+				Exchange:     input.Exchange,
+			})
+		}
+	}
+
+	return graph
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+// recordedJobDetails and recordedJobPlan are trimmed responses of /jobs/:jobid and /jobs/:jobid/plan of a job
+// reading orders and rules, joining them in a window and writing the result to a sink.
+const recordedJobDetails = `{
+  "jid": "5b2b6c8e0f1a4d3c9e7f6a5b4c3d2e1f",
+  "name": "orders",
+  "isStoppable": false,
+  "state": "RUNNING",
+  "job-type": "STREAMING",
+  "start-time": 1700000000000,
+  "end-time": -1,
+  "duration": 3600000,
+  "maxParallelism": -1,
+  "now": 1700003600000,
+  "vertices": [
+    {
+      "id": "bc764cd8ddf7a0cff126f51c16239658",
+      "name": "Source: Orders",
+      "maxParallelism": 128,
+      "parallelism": 2,
+      "status": "RUNNING",
+      "start-time": 1700000001000,
+      "end-time": -1,
+      "duration": 3599000,
+      "tasks": {"RUNNING": 2, "FAILED": 0},
+      "metrics": {"read-bytes": 0, "read-bytes-complete": true, "write-bytes": 2048, "write-bytes-complete": true, "read-records": 0, "read-records-complete": true, "write-records": 100, "write-records-complete": true}
+    },
+    {
+      "id": "feca28aff5a3958840bee985ee7de4d3",
+      "name": "Source: Rules",
+      "maxParallelism": 128,
+      "parallelism": 1,
+      "status": "RUNNING",
+      "tasks": {"RUNNING": 1},
+      "metrics": {"write-bytes": 64, "write-records": 4}
+    },
+    {
+      "id": "20ba6b65f97481d5570070de90e4e791",
+      "name": "Window(TumblingEventTimeWindows(60000)) -> Map",
+      "maxParallelism": 128,
+      "parallelism": 4,
+      "status": "RUNNING",
+      "tasks": {"RUNNING": 4},
+      "metrics": {"read-bytes": 2112, "write-bytes": 512, "read-records": 104, "write-records": 10}
+    },
+    {
+      "id": "7df19f87deec5680128845fd9a6ca18d",
+      "name": "Sink: Results",
+      "maxParallelism": 128,
+      "parallelism": 4,
+      "status": "RUNNING",
+      "tasks": {"RUNNING": 4},
+      "metrics": {"read-bytes": 512, "read-records": 10}
+    }
+  ],
+  "status-counts": {"RUNNING": 4},
+  "plan": {"jid": "5b2b6c8e0f1a4d3c9e7f6a5b4c3d2e1f"}
+}`
+
+const recordedJobPlan = `{
+  "plan": {
+    "jid": "5b2b6c8e0f1a4d3c9e7f6a5b4c3d2e1f",
+    "name": "orders",
+    "type": "STREAMING",
+    "nodes": [
+      {
+        "id": "7df19f87deec5680128845fd9a6ca18d",
+        "parallelism": 4,
+        "operator": "",
+        "operator_strategy": "",
+        "description": "Sink: Results<br/>",
+        "inputs": [{"num": 0, "id": "20ba6b65f97481d5570070de90e4e791", "ship_strategy": "FORWARD", "exchange": "pipelined_bounded"}],
+        "optimizer_properties": {}
+      },
+      {
+        "id": "20ba6b65f97481d5570070de90e4e791",
+        "parallelism": 4,
+        "operator": "",
+        "operator_strategy": "",
+        "description": "Window(TumblingEventTimeWindows(60000))<br/>+- Map<br/>",
+        "inputs": [
+          {"num": 0, "id": "bc764cd8ddf7a0cff126f51c16239658", "ship_strategy": "HASH", "exchange": "pipelined_bounded"},
+          {"num": 1, "id": "feca28aff5a3958840bee985ee7de4d3", "ship_strategy": "BROADCAST", "exchange": "pipelined_bounded"}
+        ],
+        "optimizer_properties": {}
+      },
+      {
+        "id": "feca28aff5a3958840bee985ee7de4d3",
+        "parallelism": 1,
+        "operator": "",
+        "operator_strategy": "",
+        "description": "Source: Rules<br/>",
+        "optimizer_properties": {}
+      },
+      {
+        "id": "bc764cd8ddf7a0cff126f51c16239658",
+        "parallelism": 2,
+        "operator": "",
+        "operator_strategy": "",
+        "description": "Source: Orders<br/>",
+        "optimizer_properties": {}
+      }
+    ]
+  }
+}`
+
+func TestToJobGraph(t *testing.T) {
+	details, err := decodeJobDetails([]byte(recordedJobDetails))
+	if err != nil {
+		t.Fatalf("failed to decode the job details: %v", err)
+	}
+
+	var plan FlinkJobPlanResponse
+	if err = decodeFlinkResponse([]byte(recordedJobPlan), &plan); err != nil {
+		t.Fatalf("failed to decode the plan: %v", err)
+	}
+
+	graph := toJobGraph(details, &plan.Plan)
+
+	if graph.JobId != "5b2b6c8e0f1a4d3c9e7f6a5b4c3d2e1f" || graph.Name != "orders" || graph.State != "RUNNING" || graph.JobType != "STREAMING" ||
+		graph.StartTime != 1700000000000 || graph.Duration != 3600000 {
+		t.Errorf("unexpected job %+v", graph)
+	}
+
+	// the vertices keep the topological order of the job details, not the order of the plan
+	names := make([]string, 0, len(graph.Vertices))
+	for _, vertex := range graph.Vertices {
+		names = append(names, vertex.Name)
+	}
+
+	expectedNames := []string{"Source: Orders", "Source: Rules", "Window(TumblingEventTimeWindows(60000)) -> Map", "Sink: Results"}
+	if !slices.Equal(names, expectedNames) {
+		t.Fatalf("expected vertices %v, got %v", expectedNames, names)
+	}
+
+	source := graph.Vertices[0]
+	if source.Id != "bc764cd8ddf7a0cff126f51c16239658" || source.Description != "Source: Orders<br/>" || source.Parallelism != 2 || source.MaxParallelism != 128 ||
+		source.Status != "RUNNING" || source.Tasks["RUNNING"] != 2 || source.RecordsOut != 100 || source.BytesOut != 2048 || source.RecordsIn != 0 {
+		t.Errorf("unexpected source vertex %+v", source)
+	}
+
+	window := graph.Vertices[2]
+	if window.Description != "Window(TumblingEventTimeWindows(60000))<br/>+- Map<br/>" || window.RecordsIn != 104 || window.BytesIn != 2112 ||
+		window.RecordsOut != 10 || window.BytesOut != 512 {
+		t.Errorf("unexpected window vertex %+v", window)
+	}
+
+	// every input of a plan node is an edge from the input to the node
+	expectedEdges := []JobGraphEdge{
+		{Source: "20ba6b65f97481d5570070de90e4e791", Target: "7df19f87deec5680128845fd9a6ca18d", ShipStrategy: "FORWARD", Exchange: "pipelined_bounded"},
+		{Source: "bc764cd8ddf7a0cff126f51c16239658", Target: "20ba6b65f97481d5570070de90e4e791", ShipStrategy: "HASH", Exchange: "pipelined_bounded"},
+		{Source: "feca28aff5a3958840bee985ee7de4d3", Target: "20ba6b65f97481d5570070de90e4e791", ShipStrategy: "BROADCAST", Exchange: "pipelined_bounded"},
+	}
+
+	if !slices.Equal(graph.Edges, expectedEdges) {
+		t.Errorf("expected edges\n%+v\ngot\n%+v", expectedEdges, graph.Edges)
+	}
+}
+
+func TestToJobGraphWithoutPlan(t *testing.T) {
+	details := &FlinkJobDetails{Jid: "job", Vertices: []FlinkJobVertex{{Id: "vertex", Name: "Source"}}}
+
+	graph := toJobGraph(details, &FlinkJobPlan{})
+
+	if len(graph.Vertices) != 1 || graph.Vertices[0].Description != "" || graph.Edges == nil || len(graph.Edges) != 0 {
+		t.Errorf("expected a single vertex without edges, got %+v", graph)
+	}
+}
@@ -18,7 +18,21 @@ type FlinkDeployment struct {
 }
 
 func (d *FlinkDeployment) GetStatusGroup() string {
-	switch d.Status.JobStatus.State {
+	return d.Status.JobStatus.statusGroup()
+}
+
+// GetFlinkVersion returns the version the running cluster reported to the operator, or the version of the spec
+// if the cluster didn't report one yet.
+func (d *FlinkDeployment) GetFlinkVersion() string {
+	if d.Status.ClusterInfo != nil && d.Status.ClusterInfo.FlinkVersion != "" {
+		return d.Status.ClusterInfo.FlinkVersion
+	}
+
+	return d.Spec.FlinkVersion
+}
+
+func (s FlinkJobStatus) statusGroup() string {
+	switch s.State {
 	case "CREATED", "RUNNING", "RESTARTING", "RECONCILING":
 		return "running"
 	case "FINISHED":
@@ -133,8 +147,58 @@ type FlinkCheckpointInfo struct {
 }
 
 type FlinkSavepointInfo struct {
-	LastPeriodicSavepointTimestamp int64    `json:"lastPeriodicSavepointTimestamp,omitempty"`
-	SavepointHistory               []string `json:"savepointHistory,omitempty"`
+	LastPeriodicSavepointTimestamp int64            `json:"lastPeriodicSavepointTimestamp,omitempty"`
+	SavepointHistory               []FlinkSavepoint `json:"savepointHistory,omitempty"`
+}
+
+// FlinkSavepoint is a savepoint recorded by the operator in the savepoint history of a deployment.
+type FlinkSavepoint struct {
+	TimeStamp   int64  `json:"timeStamp,omitempty"`
+	Location    string `json:"location,omitempty"`
+	TriggerType string `json:"triggerType,omitempty"`
+	FormatType  string `json:"formatType,omitempty"`
+}
+
+// UnmarshalJSON accepts both the savepoint object and a plain location string.
+func (s *FlinkSavepoint) UnmarshalJSON(data []byte) error {
+	var location string
+	if err := json.Unmarshal(data, &location); err == nil {
+		*s = FlinkSavepoint{Location: location}
+
+		return nil
+	}
+
+	type savepoint FlinkSavepoint
+	if err := json.Unmarshal(data, (*savepoint)(s)); err != nil {
+		return fmt.Errorf("could not unmarshal savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// FlinkSessionJob represents the FlinkSessionJob custom resource (group flink.apache.org, version v1beta1), a job
+// submitted to the session cluster of the FlinkDeployment named by spec.deploymentName.
+type FlinkSessionJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              FlinkSessionJobSpec   `json:"spec,omitempty"`
+	Status            FlinkSessionJobStatus `json:"status,omitempty"`
+}
+
+func (j *FlinkSessionJob) GetStatusGroup() string {
+	return j.Status.JobStatus.statusGroup()
+}
+
+type FlinkSessionJobSpec struct {
+	DeploymentName     string              `json:"deploymentName,omitempty"`
+	FlinkConfiguration map[string]any      `json:"flinkConfiguration,omitempty"`
+	Job                *FlinkDeploymentJob `json:"job,omitempty"`
+}
+
+type FlinkSessionJobStatus struct {
+	JobStatus      FlinkJobStatus `json:"jobStatus,omitempty"`
+	LifecycleState string         `json:"lifecycleState,omitempty"`
+	Error          string         `json:"error,omitempty"`
 }
 
 // FlinkDeploymentList for list operations (optional completeness).
@@ -145,6 +209,14 @@ type FlinkDeploymentList struct {
 }
 
 func FromUnstructured(val any) (*FlinkDeployment, error) {
+	return fromUnstructured[FlinkDeployment](val)
+}
+
+func SessionJobFromUnstructured(val any) (*FlinkSessionJob, error) {
+	return fromUnstructured[FlinkSessionJob](val)
+}
+
+func fromUnstructured[T any](val any) (*T, error) {
 	var ok bool
 	var err error
 	var object *unstructured.Unstructured
@@ -158,10 +230,10 @@ func FromUnstructured(val any) (*FlinkDeployment, error) {
 		return nil, fmt.Errorf("could not marshal object: %w", err)
 	}
 
-	deployment := &FlinkDeployment{}
-	if err = json.Unmarshal(data, deployment); err != nil {
+	resource := new(T)
+	if err = json.Unmarshal(data, resource); err != nil {
 		return nil, fmt.Errorf("could not unmarshal object: %w", err)
 	}
 
-	return deployment, nil
+	return resource, nil
 }
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	MetricScopeJob         = "job"
+	MetricScopeVertex      = "vertex"
+	MetricScopeSubtask     = "subtask"
+	MetricScopeTaskManager = "taskmanager"
+	MetricScopeJobManager  = "jobmanager"
+)
+
+// FlinkMetric is a metric as returned by the metric endpoints of Flink. The plain endpoints like
+// GET /jobs/:jobid/metrics return the value as a string, while the aggregating endpoints like
+// GET /jobs/:jobid/vertices/:vertexid/subtasks/metrics return the requested aggregations instead.
+// Both omit everything except the id if no metric names are requested.
+type FlinkMetric struct {
+	Id    string   `json:"id"`
+	Value string   `json:"value,omitempty"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Avg   *float64 `json:"avg,omitempty"`
+	Sum   *float64 `json:"sum,omitempty"`
+	Skew  *float64 `json:"skew,omitempty"`
+}
+
+// FloatValue parses the value of a plain metric. Gauges can report arbitrary strings, in which case
+// false is returned.
+func (m FlinkMetric) FloatValue() (float64, bool) {
+	value, err := strconv.ParseFloat(m.Value, 64)
+
+	return value, err == nil
+}
+
+// FlinkMetricScope selects one of the metric endpoints of Flink. Subtask metrics can be aggregated over
+// all subtasks of a vertex and task manager metrics over all task managers.
+type FlinkMetricScope struct {
+	Scope         string
+	VertexId      string
+	Subtask       *int
+	TaskManagerId string
+}
+
+// path returns the path of the metric endpoint for the scope and whether the endpoint aggregates. The ids come from
+// the request and are escaped, so they can't leave the path of the endpoint.
+func (s FlinkMetricScope) path(jobID string) (path string, aggregating bool, err error) {
+	switch s.Scope {
+	case MetricScopeJob:
+		return "/jobs/" + jobID + "/metrics", false, nil
+	case MetricScopeVertex:
+		if s.VertexId == "" {
+			return "", false, fmt.Errorf("the vertex scope requires a vertex id")
+		}
+
+		return "/jobs/" + jobID + "/vertices/" + url.PathEscape(s.VertexId) + "/subtasks/metrics", true, nil
+	case MetricScopeSubtask:
+		if s.VertexId == "" || s.Subtask == nil {
+			return "", false, fmt.Errorf("the subtask scope requires a vertex id and a subtask index")
+		}
+
+		return "/jobs/" + jobID + "/vertices/" + url.PathEscape(s.VertexId) + "/subtasks/" + strconv.Itoa(*s.Subtask) + "/metrics", false, nil
+	case MetricScopeTaskManager:
+		if s.TaskManagerId == "" {
+			return "/taskmanagers/metrics", true, nil
+		}
+
+		return "/taskmanagers/" + url.PathEscape(s.TaskManagerId) + "/metrics", false, nil
+	case MetricScopeJobManager:
+		return "/jobmanager/metrics", false, nil
+	default:
+		return "", false, fmt.Errorf("unknown metric scope %q", s.Scope)
+	}
+}
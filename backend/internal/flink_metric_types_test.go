@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestFlinkMetricScopePath(t *testing.T) {
+	subtask := 2
+
+	cases := map[string]struct {
+		scope       FlinkMetricScope
+		path        string
+		aggregating bool
+		invalid     bool
+	}{
+		"job":                   {scope: FlinkMetricScope{Scope: MetricScopeJob}, path: "/jobs/job-a/metrics"},
+		"vertex":                {scope: FlinkMetricScope{Scope: MetricScopeVertex, VertexId: "v1"}, path: "/jobs/job-a/vertices/v1/subtasks/metrics", aggregating: true},
+		"subtask":               {scope: FlinkMetricScope{Scope: MetricScopeSubtask, VertexId: "v1", Subtask: &subtask}, path: "/jobs/job-a/vertices/v1/subtasks/2/metrics"},
+		"all task managers":     {scope: FlinkMetricScope{Scope: MetricScopeTaskManager}, path: "/taskmanagers/metrics", aggregating: true},
+		"task manager":          {scope: FlinkMetricScope{Scope: MetricScopeTaskManager, TaskManagerId: "10.0.0.1:6122-abc"}, path: "/taskmanagers/10.0.0.1:6122-abc/metrics"},
+		"job manager":           {scope: FlinkMetricScope{Scope: MetricScopeJobManager}, path: "/jobmanager/metrics"},
+		"escaped vertex":        {scope: FlinkMetricScope{Scope: MetricScopeVertex, VertexId: "../../..?"}, path: "/jobs/job-a/vertices/..%2F..%2F..%3F/subtasks/metrics", aggregating: true},
+		"escaped task manager":  {scope: FlinkMetricScope{Scope: MetricScopeTaskManager, TaskManagerId: "../config#"}, path: "/taskmanagers/..%2Fconfig%23/metrics"},
+		"vertex without id":     {scope: FlinkMetricScope{Scope: MetricScopeVertex}, invalid: true},
+		"subtask without index": {scope: FlinkMetricScope{Scope: MetricScopeSubtask, VertexId: "v1"}, invalid: true},
+		"unknown scope":         {scope: FlinkMetricScope{Scope: "cluster"}, invalid: true},
+	}
+
+	for name, tc := range cases {
+		path, aggregating, err := tc.scope.path("job-a")
+		if tc.invalid {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", name, path)
+			}
+
+			continue
+		}
+
+		if err != nil || path != tc.path || aggregating != tc.aggregating {
+			t.Errorf("%s: expected %s (aggregating %v), got %s (aggregating %v): %v", name, tc.path, tc.aggregating, path, aggregating, err)
+		}
+	}
+}
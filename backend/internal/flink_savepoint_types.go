@@ -0,0 +1,43 @@
+package internal
+
+const (
+	SavepointFormatCanonical = "CANONICAL"
+	SavepointFormatNative    = "NATIVE"
+
+	flinkOperationCompleted = "COMPLETED"
+)
+
+// FlinkSavepointTriggerRequest is the request body of POST /jobs/:jobid/savepoints
+type FlinkSavepointTriggerRequest struct {
+	TargetDirectory string `json:"target-directory,omitempty"`
+	CancelJob       bool   `json:"cancel-job"`
+	FormatType      string `json:"formatType,omitempty"`
+}
+
+// FlinkTriggerResponse is the response of the endpoints triggering an asynchronous operation.
+type FlinkTriggerResponse struct {
+	RequestId string `json:"request-id"`
+}
+
+// FlinkSavepointStatus is the response from GET /jobs/:jobid/savepoints/:triggerid
+type FlinkSavepointStatus struct {
+	Status    FlinkOperationStatus     `json:"status"`
+	Operation *FlinkSavepointOperation `json:"operation,omitempty"`
+}
+
+// FlinkOperationStatus is the status of an asynchronous operation, either IN_PROGRESS or COMPLETED.
+type FlinkOperationStatus struct {
+	Id string `json:"id"`
+}
+
+// FlinkSavepointOperation is the result of a completed savepoint, which has either a location or a failure cause.
+type FlinkSavepointOperation struct {
+	Location     string             `json:"location,omitempty"`
+	FailureCause *FlinkFailureCause `json:"failure-cause,omitempty"`
+}
+
+// FlinkFailureCause is a serialized exception as returned for failed asynchronous operations.
+type FlinkFailureCause struct {
+	Class      string `json:"class"`
+	StackTrace string `json:"stack-trace"`
+}
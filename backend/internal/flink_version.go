@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	flinkVersion1_15 = FlinkVersion{Major: 1, Minor: 15}
+	flinkVersion1_16 = FlinkVersion{Major: 1, Minor: 16}
+	flinkVersion1_19 = FlinkVersion{Major: 1, Minor: 19}
+	flinkVersion2_0  = FlinkVersion{Major: 2, Minor: 0}
+)
+
+// FlinkVersion is the major and minor version of a Flink cluster, the REST API only changes between minor versions.
+// The zero value is an unknown version, responses are then decoded tolerating the differences of all versions.
+type FlinkVersion struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+}
+
+// ParseFlinkVersion parses versions like "1.18.1" or "2.0-SNAPSHOT" as reported by Flink and "v1_18" as
+// configured in the spec of a FlinkDeployment.
+func ParseFlinkVersion(value string) (FlinkVersion, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "v")
+	parts := strings.SplitN(strings.ReplaceAll(value, "_", "."), ".", 3)
+	if len(parts) < 2 {
+		return FlinkVersion{}, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || major <= 0 {
+		return FlinkVersion{}, false
+	}
+
+	digits := strings.IndexFunc(parts[1], func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if digits == -1 {
+		digits = len(parts[1])
+	}
+
+	minor, err := strconv.Atoi(parts[1][:digits])
+	if err != nil {
+		return FlinkVersion{}, false
+	}
+
+	return FlinkVersion{Major: major, Minor: minor}, true
+}
+
+func (v FlinkVersion) IsKnown() bool {
+	return v.Major > 0
+}
+
+// AtLeast reports whether the version is known and not older than the other version.
+func (v FlinkVersion) AtLeast(other FlinkVersion) bool {
+	if !v.IsKnown() {
+		return false
+	}
+
+	return v.Major > other.Major || v.Major == other.Major && v.Minor >= other.Minor
+}
+
+// Before reports whether the version is known and older than the other version.
+func (v FlinkVersion) Before(other FlinkVersion) bool {
+	return v.IsKnown() && !v.AtLeast(other)
+}
+
+func (v FlinkVersion) String() string {
+	if !v.IsKnown() {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+type clusterVersion struct {
+	version    FlinkVersion
+	detectedAt time.Time
+}
+
+// flinkMetricRename is a metric Flink renamed. Since is the version which introduced the current name and
+// RemovedIn the version which dropped the deprecated name, which is never if it is unset.
+type flinkMetricRename struct {
+	Deprecated string
+	Current    string
+	Since      FlinkVersion
+	RemovedIn  FlinkVersion
+}
+
+var flinkMetricRenames = []flinkMetricRename{
+	{Deprecated: "fullRestarts", Current: "numRestarts", RemovedIn: flinkVersion2_0},
+	{Deprecated: "uptime", Current: "runningTime", Since: flinkVersion1_16, RemovedIn: flinkVersion2_0},
+	{Deprecated: "downtime", Current: "restartingTime", Since: flinkVersion1_16, RemovedIn: flinkVersion2_0},
+	// lastCheckpointSize is only the size of the last increment since Flink 1.15, before it was the full size
+	{Deprecated: "lastCheckpointSize", Current: "lastCheckpointFullSize", Since: flinkVersion1_15},
+}
+
+// translateMetricNames returns the metric names to request from a cluster of the version together with the requested
+// names of every name Flink may return. Both names of a renamed metric are requested if the version is unknown, and
+// a name Flink returns maps to several requested names if the caller asked for both names of a renamed metric.
+func translateMetricNames(metrics []string, version FlinkVersion) (names []string, requested map[string][]string) {
+	names = make([]string, 0, len(metrics))
+	requested = make(map[string][]string, len(metrics))
+
+	add := func(name string, metric string) {
+		if _, ok := requested[name]; !ok {
+			names = append(names, name)
+		}
+
+		if !slices.Contains(requested[name], metric) {
+			requested[name] = append(requested[name], metric)
+		}
+	}
+
+	for _, metric := range metrics {
+		name, alias := metric, ""
+
+		for _, rename := range flinkMetricRenames {
+			switch {
+			case metric == rename.Current && version.Before(rename.Since):
+				name = rename.Deprecated
+			case metric == rename.Deprecated && rename.RemovedIn.IsKnown() && version.AtLeast(rename.RemovedIn):
+				name = rename.Current
+			case metric == rename.Current && !version.IsKnown():
+				alias = rename.Deprecated
+			case metric == rename.Deprecated && rename.RemovedIn.IsKnown() && !version.IsKnown():
+				alias = rename.Current
+			}
+		}
+
+		add(name, metric)
+		if alias != "" {
+			add(alias, metric)
+		}
+	}
+
+	return names, requested
+}
+
+// renameMetrics reports the metrics Flink returned under their requested names. The metrics are deduplicated after
+// renaming: if Flink returned both names of a renamed metric, the one returned under the requested name wins.
+func renameMetrics(metrics []FlinkMetric, requested map[string][]string) []FlinkMetric {
+	renamed := make([]FlinkMetric, 0, len(metrics))
+	positions := make(map[string]int, len(metrics))
+
+	for _, metric := range metrics {
+		names, ok := requested[metric.Id]
+		if !ok {
+			names = []string{metric.Id}
+		}
+
+		for _, name := range names {
+			renamedMetric := metric
+			renamedMetric.Id = name
+
+			position, seen := positions[name]
+			switch {
+			case !seen:
+				positions[name] = len(renamed)
+				renamed = append(renamed, renamedMetric)
+			case metric.Id == name:
+				renamed[position] = renamedMetric
+			}
+		}
+	}
+
+	return renamed
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+func NewHandlerAccumulators(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerAccumulators, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_accumulators")
+	if err != nil {
+		return nil, err
+	}
+
+	return &HandlerAccumulators{flinkDeploymentHandler: base}, nil
+}
+
+type HandlerAccumulators struct {
+	flinkDeploymentHandler
+}
+
+type GetAccumulatorsRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+// GetAccumulators fetches the accumulators of the job and the user accumulators of all subtasks of every vertex.
+func (h *HandlerAccumulators) GetAccumulators(ctx context.Context, request *GetAccumulatorsRequest) (httpserver.Response, error) {
+	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching accumulators for deployment %s/%s (job %s) from %s", request.Namespace, request.Name, jobID, flinkURL)
+
+	details, err := h.client.GetJob(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job from Flink: %w", err)
+	}
+
+	accumulators, err := h.client.GetJobAccumulators(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch accumulators from Flink: %w", err)
+	}
+
+	report := AccumulatorReport{
+		JobId:        jobID,
+		Accumulators: make([]FlinkAccumulator, 0, len(accumulators.JobAccumulators)+len(accumulators.UserTaskAccumulators)),
+		Vertices:     make([]VertexAccumulators, len(details.Vertices)),
+	}
+
+	report.Accumulators = append(report.Accumulators, accumulators.JobAccumulators...)
+	report.Accumulators = append(report.Accumulators, accumulators.UserTaskAccumulators...)
+
+	wg := sync.WaitGroup{}
+	for i, vertex := range details.Vertices {
+		report.Vertices[i] = VertexAccumulators{
+			VertexId:     vertex.Id,
+			Name:         vertex.Name,
+			Parallelism:  vertex.Parallelism,
+			Accumulators: []AggregatedAccumulator{},
+		}
+
+		wg.Add(1)
+		go func(vertex *VertexAccumulators) {
+			defer wg.Done()
+
+			subtasks, err := h.client.GetSubtaskAccumulators(ctx, flinkURL, jobID, vertex.VertexId)
+			if err != nil {
+				h.logger.Warn(ctx, "failed to fetch accumulators of vertex %s: %v", vertex.VertexId, err)
+				vertex.Error = err.Error()
+
+				return
+			}
+
+			vertex.Accumulators = aggregateSubtaskAccumulators(subtasks)
+		}(&report.Vertices[i])
+	}
+	wg.Wait()
+
+	return httpserver.NewJsonResponse(report), nil
+}
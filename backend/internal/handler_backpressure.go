@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+func NewHandlerBackpressure(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerBackpressure, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_backpressure")
+	if err != nil {
+		return nil, err
+	}
+
+	return &HandlerBackpressure{flinkDeploymentHandler: base}, nil
+}
+
+type HandlerBackpressure struct {
+	flinkDeploymentHandler
+}
+
+type GetBackpressureRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+// GetBackpressure polls the backpressure and busy time of all vertices of the job and ranks the vertices
+// which are the most likely bottleneck.
+func (h *HandlerBackpressure) GetBackpressure(ctx context.Context, request *GetBackpressureRequest) (httpserver.Response, error) {
+	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching backpressure for deployment %s/%s (job %s) from %s", request.Namespace, request.Name, jobID, flinkURL)
+
+	details, err := h.client.GetJob(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job from Flink: %w", err)
+	}
+
+	plan, err := h.client.GetJobPlan(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job plan from Flink: %w", err)
+	}
+
+	graph := toJobGraph(details, plan)
+	vertices := make([]VertexBackpressure, len(graph.Vertices))
+
+	wg := sync.WaitGroup{}
+	for i, vertex := range graph.Vertices {
+		vertices[i] = VertexBackpressure{
+			VertexId:    vertex.Id,
+			Name:        vertex.Name,
+			Parallelism: vertex.Parallelism,
+			Upstream:    []string{},
+		}
+
+		for _, edge := range graph.Edges {
+			if edge.Target == vertex.Id {
+				vertices[i].Upstream = append(vertices[i].Upstream, edge.Source)
+			}
+		}
+
+		wg.Add(1)
+		go func(vertex *VertexBackpressure) {
+			defer wg.Done()
+			h.fetchVertexBackpressure(ctx, flinkURL, jobID, vertex)
+		}(&vertices[i])
+	}
+	wg.Wait()
+
+	return httpserver.NewJsonResponse(BackpressureReport{
+		JobId:       jobID,
+		Vertices:    vertices,
+		Bottlenecks: findBottlenecks(vertices),
+	}), nil
+}
+
+func (h *HandlerBackpressure) fetchVertexBackpressure(ctx context.Context, flinkURL string, jobID string, vertex *VertexBackpressure) {
+	backpressure, err := h.client.GetVertexBackpressure(ctx, flinkURL, jobID, vertex.VertexId)
+	if err != nil {
+		h.logger.Warn(ctx, "failed to fetch backpressure of vertex %s: %v", vertex.VertexId, err)
+		vertex.Error = err.Error()
+
+		return
+	}
+
+	vertex.BackpressureLevel = backpressure.BackpressureLevel
+	for _, subtask := range backpressure.Subtasks {
+		vertex.BusyRatio = max(vertex.BusyRatio, subtask.BusyRatio)
+		vertex.BackpressuredRatio = max(vertex.BackpressuredRatio, subtask.Ratio)
+	}
+
+	// the subtask metrics are more precise than the sampled ratios, so they take precedence if available
+	metrics, err := h.client.GetAggregatedSubtaskMetrics(ctx, flinkURL, jobID, vertex.VertexId, []string{metricBusyTimeMsPerSecond, metricBackPressuredTimeMsPerSecond})
+	if err != nil {
+		h.logger.Warn(ctx, "failed to fetch busy time metrics of vertex %s: %v", vertex.VertexId, err)
+
+		return
+	}
+
+	vertex.applyAggregatedMetrics(metrics)
+}
@@ -3,6 +3,8 @@ package internal
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/gosoline-project/httpserver"
 	"github.com/justtrackio/gosoline/pkg/cfg"
@@ -23,12 +25,13 @@ type HandlerCheckpoints struct {
 }
 
 type GetCheckpointsRequest struct {
-	Namespace string `uri:"namespace"`
-	Name      string `uri:"name"`
+	Namespace  string `uri:"namespace"`
+	Name       string `uri:"name"`
+	SessionJob string `uri:"sessionJob"`
 }
 
 func (h *HandlerCheckpoints) GetCheckpoints(ctx context.Context, request *GetCheckpointsRequest) (httpserver.Response, error) {
-	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	flinkURL, jobID, err := h.watcher.GetJobEndpoint(request.Namespace, request.Name, request.SessionJob)
 	if err != nil {
 		return nil, err
 	}
@@ -42,3 +45,132 @@ func (h *HandlerCheckpoints) GetCheckpoints(ctx context.Context, request *GetChe
 
 	return httpserver.NewJsonResponse(stats), nil
 }
+
+type GetCheckpointDrilldownRequest struct {
+	Namespace    string `uri:"namespace"`
+	Name         string `uri:"name"`
+	SessionJob   string `uri:"sessionJob"`
+	CheckpointId int64  `uri:"checkpointId"`
+}
+
+type GetCheckpointSubtasksRequest struct {
+	Namespace    string `uri:"namespace"`
+	Name         string `uri:"name"`
+	SessionJob   string `uri:"sessionJob"`
+	CheckpointId int64  `uri:"checkpointId"`
+	VertexId     string `uri:"vertexId"`
+}
+
+// GetCheckpointDrilldown fetches the subtask statistics of all vertices of a checkpoint and names the slowest
+// vertex and subtask together with the phase it spent most of its time in.
+func (h *HandlerCheckpoints) GetCheckpointDrilldown(ctx context.Context, request *GetCheckpointDrilldownRequest) (httpserver.Response, error) {
+	flinkURL, jobID, err := h.watcher.GetJobEndpoint(request.Namespace, request.Name, request.SessionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching checkpoint %d for deployment %s/%s (job %s) from %s", request.CheckpointId, request.Namespace, request.Name, jobID, flinkURL)
+
+	details, err := h.client.GetCheckpointDetails(ctx, flinkURL, jobID, request.CheckpointId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoint details from Flink: %w", err)
+	}
+
+	// the names are only cosmetic, so the drilldown still works if the job is not available anymore
+	names := map[string]string{}
+	if job, err := h.client.GetJob(ctx, flinkURL, jobID); err != nil {
+		h.logger.Warn(ctx, "failed to fetch vertex names of job %s: %v", jobID, err)
+	} else {
+		for _, vertex := range job.Vertices {
+			names[vertex.Id] = vertex.Name
+		}
+	}
+
+	pendingUntil := time.Now().UnixMilli()
+	if details.FailureTimestamp > 0 {
+		pendingUntil = details.FailureTimestamp
+	}
+
+	vertices := make([]CheckpointVertexTiming, 0, len(details.Tasks))
+	for vertexId, task := range details.Tasks {
+		vertices = append(vertices, CheckpointVertexTiming{
+			VertexId:                vertexId,
+			Name:                    names[vertexId],
+			Status:                  task.Status,
+			NumSubtasks:             task.NumSubtasks,
+			NumAcknowledgedSubtasks: task.NumAcknowledgedSubtasks,
+			EndToEndDurationMs:      task.EndToEndDuration,
+			StateSize:               task.StateSize,
+			PendingSubtasks:         []int{},
+			Subtasks:                []CheckpointSubtaskTiming{},
+		})
+	}
+
+	wg := sync.WaitGroup{}
+	for i := range vertices {
+		wg.Add(1)
+		go func(vertex *CheckpointVertexTiming) {
+			defer wg.Done()
+
+			subtasks, err := h.client.GetCheckpointSubtasks(ctx, flinkURL, jobID, request.CheckpointId, vertex.VertexId)
+			if err != nil {
+				h.logger.Warn(ctx, "failed to fetch checkpoint subtasks of vertex %s: %v", vertex.VertexId, err)
+				vertex.Error = err.Error()
+
+				return
+			}
+
+			vertex.applySubtasks(subtasks, details.TriggerTimestamp, pendingUntil)
+		}(&vertices[i])
+	}
+	wg.Wait()
+
+	return httpserver.NewJsonResponse(buildCheckpointDrilldown(details, vertices)), nil
+}
+
+// GetCheckpointSubtasks returns the raw subtask statistics of a single vertex of a checkpoint.
+func (h *HandlerCheckpoints) GetCheckpointSubtasks(ctx context.Context, request *GetCheckpointSubtasksRequest) (httpserver.Response, error) {
+	flinkURL, jobID, err := h.watcher.GetJobEndpoint(request.Namespace, request.Name, request.SessionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching subtasks of checkpoint %d for vertex %s of deployment %s/%s (job %s) from %s",
+		request.CheckpointId, request.VertexId, request.Namespace, request.Name, jobID, flinkURL)
+
+	subtasks, err := h.client.GetCheckpointSubtasks(ctx, flinkURL, jobID, request.CheckpointId, request.VertexId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoint subtasks from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(subtasks), nil
+}
+
+type GetCheckpointConfigRequest struct {
+	Namespace  string `uri:"namespace"`
+	Name       string `uri:"name"`
+	SessionJob string `uri:"sessionJob"`
+}
+
+// GetCheckpointConfig returns the effective checkpoint configuration of the job compared with the flinkConfiguration
+// of the deployment, which reveals settings the job overrides in code.
+func (h *HandlerCheckpoints) GetCheckpointConfig(ctx context.Context, request *GetCheckpointConfigRequest) (httpserver.Response, error) {
+	flinkConfig, _, err := h.watcher.GetJobConfiguration(request.Namespace, request.Name, request.SessionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	flinkURL, jobID, err := h.watcher.GetJobEndpoint(request.Namespace, request.Name, request.SessionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching checkpoint config for deployment %s/%s (job %s) from %s", request.Namespace, request.Name, jobID, flinkURL)
+
+	config, err := h.client.GetCheckpointConfig(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoint config from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(compareCheckpointConfig(jobID, config, flinkConfig)), nil
+}
@@ -0,0 +1,370 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+var errUnsatisfiableRange = errors.New("range not satisfiable")
+
+func NewHandlerCluster(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerCluster, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_cluster")
+	if err != nil {
+		return nil, err
+	}
+
+	return &HandlerCluster{flinkDeploymentHandler: base}, nil
+}
+
+// HandlerCluster exposes the task managers and the job manager of the Flink cluster of a deployment.
+type HandlerCluster struct {
+	flinkDeploymentHandler
+}
+
+type GetClusterRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+type GetTaskManagerRequest struct {
+	Namespace   string `uri:"namespace"`
+	Name        string `uri:"name"`
+	TaskManager string `uri:"taskmanager"`
+}
+
+type StreamLogFileRequest struct {
+	Namespace   string `uri:"namespace"`
+	Name        string `uri:"name"`
+	TaskManager string `uri:"taskmanager"`
+	File        string `uri:"file"`
+	Grep        string `form:"grep"`
+	IgnoreCase  bool   `form:"ignoreCase"`
+}
+
+func (h *HandlerCluster) GetTaskManagers(ctx context.Context, request *GetClusterRequest) (httpserver.Response, error) {
+	flinkURL, _, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching task managers for deployment %s/%s from %s", request.Namespace, request.Name, flinkURL)
+
+	taskManagers, err := h.client.GetTaskManagers(ctx, flinkURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task managers from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(taskManagers), nil
+}
+
+func (h *HandlerCluster) GetTaskManager(ctx context.Context, request *GetTaskManagerRequest) (httpserver.Response, error) {
+	flinkURL, _, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching task manager %s for deployment %s/%s from %s", request.TaskManager, request.Namespace, request.Name, flinkURL)
+
+	taskManager, err := h.client.GetTaskManager(ctx, flinkURL, request.TaskManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task manager from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(taskManager), nil
+}
+
+func (h *HandlerCluster) GetTaskManagerLogs(ctx context.Context, request *GetTaskManagerRequest) (httpserver.Response, error) {
+	flinkURL, _, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching logs of task manager %s for deployment %s/%s from %s", request.TaskManager, request.Namespace, request.Name, flinkURL)
+
+	logs, err := h.client.GetTaskManagerLogs(ctx, flinkURL, request.TaskManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task manager logs from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(logs), nil
+}
+
+func (h *HandlerCluster) GetJobManagerConfig(ctx context.Context, request *GetClusterRequest) (httpserver.Response, error) {
+	flinkURL, _, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching job manager config for deployment %s/%s from %s", request.Namespace, request.Name, flinkURL)
+
+	config, err := h.client.GetJobManagerConfig(ctx, flinkURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job manager config from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(config), nil
+}
+
+func (h *HandlerCluster) GetJobManagerLogs(ctx context.Context, request *GetClusterRequest) (httpserver.Response, error) {
+	flinkURL, _, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching job manager logs for deployment %s/%s from %s", request.Namespace, request.Name, flinkURL)
+
+	logs, err := h.client.GetJobManagerLogs(ctx, flinkURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job manager logs from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(logs), nil
+}
+
+func (h *HandlerCluster) StreamTaskManagerLog(ginCtx *gin.Context) {
+	h.streamLogFile(ginCtx, func(request *StreamLogFileRequest) string {
+		return "/taskmanagers/" + url.PathEscape(request.TaskManager) + "/log"
+	})
+}
+
+func (h *HandlerCluster) StreamTaskManagerStdout(ginCtx *gin.Context) {
+	h.streamLogFile(ginCtx, func(request *StreamLogFileRequest) string {
+		return "/taskmanagers/" + url.PathEscape(request.TaskManager) + "/stdout"
+	})
+}
+
+func (h *HandlerCluster) StreamTaskManagerLogFile(ginCtx *gin.Context) {
+	h.streamLogFile(ginCtx, func(request *StreamLogFileRequest) string {
+		return "/taskmanagers/" + url.PathEscape(request.TaskManager) + "/logs/" + url.PathEscape(request.File)
+	})
+}
+
+func (h *HandlerCluster) StreamJobManagerLog(ginCtx *gin.Context) {
+	h.streamLogFile(ginCtx, func(_ *StreamLogFileRequest) string {
+		return "/jobmanager/log"
+	})
+}
+
+func (h *HandlerCluster) StreamJobManagerStdout(ginCtx *gin.Context) {
+	h.streamLogFile(ginCtx, func(_ *StreamLogFileRequest) string {
+		return "/jobmanager/stdout"
+	})
+}
+
+func (h *HandlerCluster) StreamJobManagerLogFile(ginCtx *gin.Context) {
+	h.streamLogFile(ginCtx, func(request *StreamLogFileRequest) string {
+		return "/jobmanager/logs/" + url.PathEscape(request.File)
+	})
+}
+
+// streamLogFile streams a log file from Flink. A single byte range can be requested with the Range header,
+// which is applied to the log file itself. With the grep parameter only the lines of the (requested range of the)
+// file matching the regular expression are returned.
+func (h *HandlerCluster) streamLogFile(ginCtx *gin.Context, logPath func(request *StreamLogFileRequest) string) {
+	var err error
+	var grep *regexp.Regexp
+
+	request := &StreamLogFileRequest{}
+	if err = ginCtx.ShouldBindUri(request); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+
+		return
+	}
+
+	if err = ginCtx.ShouldBindQuery(request); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+
+		return
+	}
+
+	if request.Grep != "" {
+		expression := request.Grep
+		if request.IgnoreCase {
+			expression = "(?i)" + expression
+		}
+
+		if grep, err = regexp.Compile(expression); err != nil {
+			ginCtx.JSON(http.StatusBadRequest, gin.H{"err": fmt.Sprintf("invalid grep expression: %s", err)})
+
+			return
+		}
+	}
+
+	flinkURL, _, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		ginCtx.JSON(FlinkErrorStatus(err, http.StatusInternalServerError), gin.H{"err": err.Error()})
+
+		return
+	}
+
+	path := logPath(request)
+	h.logger.Info(ginCtx, "streaming %s for deployment %s/%s from %s", path, request.Namespace, request.Name, flinkURL)
+
+	file, err := h.client.OpenLogFile(ginCtx, flinkURL, path, ginCtx.GetHeader("Range"))
+	if err != nil {
+		var flinkErr *FlinkError
+		if errors.As(err, &flinkErr) && flinkErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			ginCtx.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"err": errUnsatisfiableRange.Error()})
+
+			return
+		}
+
+		ginCtx.JSON(FlinkErrorStatus(err, http.StatusBadGateway), gin.H{"err": fmt.Sprintf("failed to fetch log file from Flink: %s", err)})
+
+		return
+	}
+	defer func() {
+		if cerr := file.Body.Close(); cerr != nil {
+			h.logger.Warn(ginCtx, "failed to close log file %s: %v", path, cerr)
+		}
+	}()
+
+	if err = writeLogFile(ginCtx, file, grep); err != nil {
+		// the headers are usually sent at this point, so the client only notices the truncated response
+		h.logger.Warn(ginCtx, "failed to stream log file %s: %v", path, err)
+	}
+}
+
+// writeLogFile writes the requested range of the log file or the lines of it matching the expression. If Flink
+// ignored the range and sent the whole file, the range is cut out of it.
+func writeLogFile(ginCtx *gin.Context, file *FlinkLogFile, grep *regexp.Regexp) error {
+	var reader io.Reader = file.Body
+	start, end, partial, size := file.Start, file.End, file.Partial, file.Size
+
+	if !file.Partial {
+		var err error
+		if start, end, partial, err = parseByteRange(ginCtx.GetHeader("Range"), size); err != nil {
+			ginCtx.Header("Content-Range", fmt.Sprintf("bytes */%d", size))
+			ginCtx.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"err": err.Error()})
+
+			return nil
+		}
+
+		if partial {
+			if _, err = io.CopyN(io.Discard, file.Body, start); err != nil {
+				ginCtx.JSON(http.StatusBadGateway, gin.H{"err": fmt.Sprintf("failed to seek log file: %s", err)})
+
+				return nil
+			}
+
+			reader = io.LimitReader(file.Body, end-start+1)
+		}
+	}
+
+	ginCtx.Header("Content-Type", "text/plain; charset=utf-8")
+	ginCtx.Header("Accept-Ranges", "bytes")
+
+	if grep != nil {
+		ginCtx.Status(http.StatusOK)
+
+		return writeMatchingLines(ginCtx.Writer, reader, grep)
+	}
+
+	status := http.StatusOK
+	length := size
+	if partial {
+		status = http.StatusPartialContent
+		length = end - start + 1
+		ginCtx.Header("Content-Range", contentRange(start, end, size))
+	}
+
+	if length >= 0 {
+		ginCtx.Header("Content-Length", strconv.FormatInt(length, 10))
+	}
+
+	ginCtx.Status(status)
+	_, err := io.Copy(ginCtx.Writer, reader)
+
+	return err
+}
+
+func contentRange(start int64, end int64, size int64) string {
+	if size < 0 {
+		return fmt.Sprintf("bytes %d-%d/*", start, end)
+	}
+
+	return fmt.Sprintf("bytes %d-%d/%d", start, end, size)
+}
+
+// parseByteRange parses a Range header with a single byte range like "bytes=0-1023", "bytes=1024-" or "bytes=-1024".
+// Ranges are ignored if the header is empty, contains multiple ranges or the size of the file is unknown,
+// in which case the whole file is served.
+func parseByteRange(header string, size int64) (start int64, end int64, partial bool, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || size < 0 || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, false, nil
+	}
+
+	switch {
+	case first == "":
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix <= 0 {
+			return 0, 0, false, errUnsatisfiableRange
+		}
+
+		start = max(size-suffix, 0)
+		end = size - 1
+	default:
+		if start, err = strconv.ParseInt(first, 10, 64); err != nil || start < 0 {
+			return 0, 0, false, errUnsatisfiableRange
+		}
+
+		end = size - 1
+		if last != "" {
+			if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+				return 0, 0, false, errUnsatisfiableRange
+			}
+
+			end = min(end, size-1)
+		}
+	}
+
+	if start >= size {
+		return 0, 0, false, errUnsatisfiableRange
+	}
+
+	return start, end, true, nil
+}
+
+// writeMatchingLines copies the lines of the reader matching the expression to the writer, flushing after each match
+// so the matches show up while the rest of the file is still searched.
+func writeMatchingLines(writer gin.ResponseWriter, reader io.Reader, grep *regexp.Regexp) error {
+	buffered := bufio.NewReader(reader)
+
+	for {
+		line, err := buffered.ReadBytes('\n')
+		if len(line) > 0 && grep.Match(line) {
+			if _, werr := writer.Write(line); werr != nil {
+				return werr
+			}
+
+			writer.Flush()
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
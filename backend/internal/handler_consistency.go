@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+func NewHandlerConsistency(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerConsistency, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_consistency")
+	if err != nil {
+		return nil, err
+	}
+
+	s3Service, err := ProvideS3Service(ctx, config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize s3 service: %w", err)
+	}
+
+	return &HandlerConsistency{
+		flinkDeploymentHandler: base,
+		s3Service:              s3Service,
+	}, nil
+}
+
+type HandlerConsistency struct {
+	flinkDeploymentHandler
+	s3Service *S3Service
+}
+
+type GetConsistencyReportRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+// GetConsistencyReport cross-checks the checkpoint history of Flink and the savepoint history of the operator
+// against the checkpoint and savepoint directories in storage.
+func (h *HandlerConsistency) GetConsistencyReport(ctx context.Context, request *GetConsistencyReportRequest) (httpserver.Response, error) {
+	deployment, exists := h.watcher.GetDeployment(request.Namespace, request.Name)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s/%s", ErrDeploymentNotFound, request.Namespace, request.Name)
+	}
+
+	report := &ConsistencyReport{
+		Namespace:        request.Namespace,
+		Name:             request.Name,
+		JobId:            deployment.Status.JobStatus.JobId,
+		CheckedAt:        time.Now().UTC(),
+		MissingMetadata:  []ConsistencyFinding{},
+		Orphaned:         []ConsistencyFinding{},
+		DiscardedPresent: []ConsistencyFinding{},
+	}
+
+	var stats *FlinkCheckpointStatistics
+	if report.JobId != "" {
+		stats = h.fetchCheckpoints(ctx, request, report)
+	}
+
+	references := collectConsistencyReferences(stats, deployment.Status.JobStatus.SavepointInfo)
+	h.logger.Info(ctx, "checking %d known checkpoint and savepoint locations of %s/%s", len(references), request.Namespace, request.Name)
+
+	referenced := h.checkReferences(ctx, references, report)
+
+	knownJobs := map[string]bool{}
+	if report.JobId != "" {
+		knownJobs[strings.ReplaceAll(report.JobId, "-", "")] = true
+	}
+
+	for _, dir := range getStorageDirs(deployment.Spec.FlinkConfiguration) {
+		h.findOrphans(ctx, dir, knownJobs, referenced, report)
+	}
+
+	return httpserver.NewJsonResponse(report), nil
+}
+
+func (h *HandlerConsistency) fetchCheckpoints(ctx context.Context, request *GetConsistencyReportRequest, report *ConsistencyReport) *FlinkCheckpointStatistics {
+	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+
+		return nil
+	}
+
+	stats, err := h.client.GetCheckpoints(ctx, flinkURL, jobID)
+	if err != nil {
+		h.logger.Warn(ctx, "failed to fetch checkpoints from Flink: %v", err)
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to fetch checkpoints from Flink: %s", err))
+
+		return nil
+	}
+
+	return stats
+}
+
+// checkReferences flags completed locations without _metadata and discarded locations which still have one.
+// It returns the set of all referenced locations.
+func (h *HandlerConsistency) checkReferences(ctx context.Context, references []consistencyReference, report *ConsistencyReport) map[string]bool {
+	referenced := map[string]bool{}
+
+	for _, reference := range references {
+		path := normalizeStoragePath(reference.path)
+		if referenced[path] {
+			continue
+		}
+		referenced[path] = true
+
+		if !reference.completed && !reference.discarded {
+			continue
+		}
+
+		metadataInfo, err := h.s3Service.GetMetadataInfo(ctx, path)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to check metadata for %s: %s", path, err))
+
+			continue
+		}
+
+		report.addReference(path, reference, metadataInfo)
+	}
+
+	return referenced
+}
+
+// findOrphans reports all directories below the job directories of a storage location which belong to
+// an unknown job and are not referenced by any history entry.
+func (h *HandlerConsistency) findOrphans(ctx context.Context, dir string, knownJobs map[string]bool, referenced map[string]bool, report *ConsistencyReport) {
+	jobIds, err := h.s3Service.ListJobDirectories(ctx, dir)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to list job directories of %s: %s", dir, err))
+
+		return
+	}
+
+	for _, jobId := range jobIds {
+		if knownJobs[jobId] {
+			continue
+		}
+
+		entries, err := h.s3Service.ListStorageCheckpoints(ctx, strings.TrimSuffix(dir, "/")+"/"+jobId)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to list directories of job %s: %s", jobId, err))
+
+			continue
+		}
+
+		report.addOrphans(jobId, entries, referenced)
+	}
+}
@@ -40,6 +40,20 @@ func (h *HandlerDeployments) WatchDeployments(ctx context.Context, writer *https
 	return streamDeploymentUpdates(ctx, writer, updates)
 }
 
+type GetSessionJobsRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+// GetSessionJobs lists the FlinkSessionJobs running on the session cluster of a deployment.
+func (h *HandlerDeployments) GetSessionJobs(_ context.Context, request *GetSessionJobsRequest) (httpserver.Response, error) {
+	if _, exists := h.watcher.GetDeployment(request.Namespace, request.Name); !exists {
+		return nil, fmt.Errorf("%w: %s/%s", ErrDeploymentNotFound, request.Namespace, request.Name)
+	}
+
+	return httpserver.NewJsonResponse(h.watcher.GetSessionJobs(request.Namespace, request.Name)), nil
+}
+
 func sendInitialDeployments(writer *httpserver.SseWriter, deployments map[string]map[string]*FlinkDeployment, firstEvent *bool) error {
 	for _, nsDeployments := range deployments {
 		for _, deployment := range nsDeployments {
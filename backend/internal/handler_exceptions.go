@@ -2,43 +2,159 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/gosoline-project/httpserver"
 	"github.com/justtrackio/gosoline/pkg/cfg"
 	"github.com/justtrackio/gosoline/pkg/log"
 )
 
+const (
+	defaultMaxExceptions = 50
+	maxExceptionsLimit   = 1000
+)
+
 func NewHandlerExceptions(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerExceptions, error) {
 	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_exceptions")
 	if err != nil {
 		return nil, err
 	}
 
-	return &HandlerExceptions{flinkDeploymentHandler: base}, nil
+	collector, err := ProvideExceptionCollectorModule(ctx, config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize exception collector: %w", err)
+	}
+
+	classifier, err := ProvideExceptionClassifier(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("could not create exception classifier: %w", err)
+	}
+
+	return &HandlerExceptions{
+		flinkDeploymentHandler: base,
+		collector:              collector,
+		classifier:             classifier,
+	}, nil
 }
 
 type HandlerExceptions struct {
 	flinkDeploymentHandler
+	collector  *ExceptionCollectorModule
+	classifier *ExceptionClassifier
 }
 
 type GetExceptionsRequest struct {
-	Namespace string `uri:"namespace"`
-	Name      string `uri:"name"`
+	Namespace     string `uri:"namespace"`
+	Name          string `uri:"name"`
+	SessionJob    string `uri:"sessionJob"`
+	MaxExceptions int    `form:"maxExceptions"`
+}
+
+type GetExceptionHistoryRequest struct {
+	Namespace  string    `uri:"namespace"`
+	Name       string    `uri:"name"`
+	SessionJob string    `uri:"sessionJob"`
+	JobId      string    `form:"jobId"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
 }
 
 func (h *HandlerExceptions) GetExceptions(ctx context.Context, request *GetExceptionsRequest) (httpserver.Response, error) {
-	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err := request.applyDefaults(); err != nil {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
+
+	exceptions, err := h.fetchExceptions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return httpserver.NewJsonResponse(exceptions), nil
+}
+
+// GetExceptionGroups groups the exception history by the normalized root cause of the exceptions, so the same
+// failure repeated by a restarting job shows up once with its count.
+func (h *HandlerExceptions) GetExceptionGroups(ctx context.Context, request *GetExceptionsRequest) (httpserver.Response, error) {
+	if err := request.applyDefaults(); err != nil {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
+
+	exceptions, err := h.fetchExceptions(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	h.logger.Info(ctx, "fetching exceptions for deployment %s/%s (job %s) from %s", request.Namespace, request.Name, jobID, flinkURL)
+	return httpserver.NewJsonResponse(groupExceptions(&exceptions.ExceptionHistory)), nil
+}
+
+// GetExceptionCategories counts the exceptions of the history by their category, so the kind of failures of a
+// deployment can be triaged at a glance.
+func (h *HandlerExceptions) GetExceptionCategories(ctx context.Context, request *GetExceptionsRequest) (httpserver.Response, error) {
+	if err := request.applyDefaults(); err != nil {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
 
-	exceptions, err := h.client.GetExceptions(ctx, flinkURL, jobID)
+	exceptions, err := h.fetchExceptions(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch exceptions from Flink: %w", err)
+		return nil, err
+	}
+
+	return httpserver.NewJsonResponse(summarizeExceptionCategories(&exceptions.ExceptionHistory)), nil
+}
+
+// GetExceptionHistory returns the exceptions persisted by the exception collector, which go back further than the
+// exception history kept by Flink and survive job restarts.
+func (h *HandlerExceptions) GetExceptionHistory(_ context.Context, request *GetExceptionHistoryRequest) (httpserver.Response, error) {
+	if !request.From.IsZero() && !request.To.IsZero() && request.To.Before(request.From) {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, fmt.Errorf("to has to be after from")), nil
+	}
+
+	exceptions, err := h.collector.Query(request.Namespace, request.Name, request.SessionJob, request.JobId, request.From, request.To)
+	if errors.Is(err, errInvalidStoragePath) {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exception history: %w", err)
+	}
+
+	// the exceptions are classified with the current rules instead of the ones at the time they were collected
+	for i := range exceptions {
+		h.classifier.ClassifyEntry(&exceptions[i].Exception)
 	}
 
 	return httpserver.NewJsonResponse(exceptions), nil
 }
+
+func (h *HandlerExceptions) fetchExceptions(ctx context.Context, request *GetExceptionsRequest) (*FlinkJobExceptions, error) {
+	flinkURL, jobID, err := h.watcher.GetJobEndpoint(request.Namespace, request.Name, request.SessionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching %d exceptions for deployment %s/%s (job %s) from %s", request.MaxExceptions, request.Namespace, request.Name, jobID, flinkURL)
+
+	exceptions, err := h.client.GetExceptions(ctx, flinkURL, jobID, request.MaxExceptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exceptions from Flink: %w", err)
+	}
+
+	h.classifier.ClassifyHistory(&exceptions.ExceptionHistory)
+
+	return exceptions, nil
+}
+
+func (r *GetExceptionsRequest) applyDefaults() error {
+	if r.MaxExceptions == 0 {
+		r.MaxExceptions = defaultMaxExceptions
+	}
+
+	if r.MaxExceptions < 1 || r.MaxExceptions > maxExceptionsLimit {
+		return fmt.Errorf("maxExceptions has to be between 1 and %d", maxExceptionsLimit)
+	}
+
+	return nil
+}
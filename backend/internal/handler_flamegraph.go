@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+const FlameGraphFormatCollapsed = "collapsed"
+
+type FlameGraphSettings struct {
+	PollInterval time.Duration `cfg:"poll_interval" default:"1s"`
+	Timeout      time.Duration `cfg:"timeout" default:"30s"`
+}
+
+func NewHandlerFlameGraph(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerFlameGraph, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_flamegraph")
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &FlameGraphSettings{}
+	if err = config.UnmarshalKey("flamegraph", settings); err != nil {
+		return nil, fmt.Errorf("could not unmarshal flame graph settings: %w", err)
+	}
+
+	return &HandlerFlameGraph{
+		flinkDeploymentHandler: base,
+		settings:               settings,
+	}, nil
+}
+
+type HandlerFlameGraph struct {
+	flinkDeploymentHandler
+	settings *FlameGraphSettings
+}
+
+type GetFlameGraphRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+	VertexId  string `uri:"vertexId"`
+	Type      string `form:"type"`
+	Subtask   *int   `form:"subtask"`
+	Format    string `form:"format"`
+}
+
+// GetFlameGraph samples the stack traces of a vertex and returns them as flame graph. The type is one of on_cpu
+// (the default), off_cpu or mixed and the sample can be restricted to a single subtask. With format=collapsed the
+// flame graph is returned as collapsed stacks for offline tools instead.
+func (h *HandlerFlameGraph) GetFlameGraph(ctx context.Context, request *GetFlameGraphRequest) (httpserver.Response, error) {
+	if request.Type == "" {
+		request.Type = FlameGraphTypeOnCpu
+	}
+
+	flinkType, err := toFlinkFlameGraphType(request.Type)
+	if err != nil {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
+
+	if request.Format != "" && request.Format != FlameGraphFormatCollapsed {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, fmt.Errorf("unsupported format %q", request.Format)), nil
+	}
+
+	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching %s flame graph of vertex %s for deployment %s/%s (job %s) from %s", request.Type, request.VertexId, request.Namespace, request.Name, jobID, flinkURL)
+
+	flinkFlameGraph, err := h.awaitFlameGraph(ctx, flinkURL, jobID, request.VertexId, flinkType, request.Subtask)
+	if err != nil {
+		return nil, err
+	}
+
+	if flinkFlameGraph.EndTimestamp == flinkFlameGraphTerminated {
+		return httpserver.GetErrorHandler()(http.StatusConflict, fmt.Errorf("vertex %s is not running and can't be sampled", request.VertexId)), nil
+	}
+
+	flameGraph := toFlameGraph(request.VertexId, request.Type, request.Subtask, flinkFlameGraph)
+
+	if request.Format != FlameGraphFormatCollapsed {
+		return httpserver.NewJsonResponse(flameGraph), nil
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s-%s.collapsed", request.Name, request.VertexId, request.Type, time.UnixMilli(flameGraph.EndTimestamp).UTC().Format("20060102T150405Z"))
+
+	return httpserver.NewResponse(
+		httpserver.WithBody([]byte(flameGraph.Collapsed())),
+		httpserver.WithHeader("Content-Type", "text/plain; charset=utf-8"),
+		httpserver.WithHeader("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename)),
+		httpserver.WithStatusCode(http.StatusOK),
+	), nil
+}
+
+// awaitFlameGraph polls the flame graph until Flink finished taking the sample, which is started by the first request.
+func (h *HandlerFlameGraph) awaitFlameGraph(ctx context.Context, flinkURL string, jobID string, vertexID string, flinkType string, subtask *int) (*FlinkFlameGraph, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.settings.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(h.settings.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		flameGraph, err := h.client.GetFlameGraph(ctx, flinkURL, jobID, vertexID, flinkType, subtask)
+
+		switch {
+		case errors.Is(err, ErrFlinkNotFound):
+			return nil, fmt.Errorf("failed to fetch flame graph from Flink, flame graphs need rest.flamegraph.enabled: %w", err)
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, fmt.Errorf("flame graph of vertex %s was not sampled within %s: %w", vertexID, h.settings.Timeout, ErrFlinkTimeout)
+		case err != nil:
+			return nil, fmt.Errorf("failed to fetch flame graph from Flink: %w", err)
+		case (flameGraph.EndTimestamp != flinkFlameGraphWaiting && flameGraph.Data != nil) || flameGraph.EndTimestamp == flinkFlameGraphTerminated:
+			return flameGraph, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("flame graph of vertex %s was not sampled within %s: %w", vertexID, h.settings.Timeout, ErrFlinkTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func toFlinkFlameGraphType(flameGraphType string) (string, error) {
+	switch flameGraphType {
+	case FlameGraphTypeOnCpu, FlameGraphTypeOffCpu:
+		return flameGraphType, nil
+	case FlameGraphTypeMixed:
+		return flinkFlameGraphTypeFull, nil
+	default:
+		return "", fmt.Errorf("unknown flame graph type %q, expected one of %s, %s or %s", flameGraphType, FlameGraphTypeOnCpu, FlameGraphTypeOffCpu, FlameGraphTypeMixed)
+	}
+}
@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/flink-admin/internal/checkpoint"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+// maxHaPointerSize limits how much of a completed checkpoint pointer file is read to find the checkpoint location.
+const maxHaPointerSize = 4 << 20
+
+var checkpointDirPattern = regexp.MustCompile(`/(chk-\d+|savepoint-[^/]+)/?$`)
+
+func NewHandlerHighAvailability(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerHighAvailability, error) {
+	var err error
+	var watcher *DeploymentWatcherModule
+	var k8sService *K8sService
+	var s3Service *S3Service
+
+	if watcher, err = ProvideDeploymentWatcherModule(ctx, config, logger); err != nil {
+		return nil, fmt.Errorf("could not initialize deployment watcher: %w", err)
+	}
+
+	if k8sService, err = ProvideK8sService(ctx, config, logger); err != nil {
+		return nil, fmt.Errorf("could not provide k8s service: %w", err)
+	}
+
+	if s3Service, err = ProvideS3Service(ctx, config, logger); err != nil {
+		return nil, fmt.Errorf("could not initialize s3 service: %w", err)
+	}
+
+	return &HandlerHighAvailability{
+		logger:     logger.WithChannel("handler_high_availability"),
+		watcher:    watcher,
+		k8sService: k8sService,
+		s3Service:  s3Service,
+	}, nil
+}
+
+type HandlerHighAvailability struct {
+	logger     log.Logger
+	watcher    *DeploymentWatcherModule
+	k8sService *K8sService
+	s3Service  *S3Service
+}
+
+type GetHighAvailabilityRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+// GetHighAvailability inspects the Kubernetes HA ConfigMaps of a deployment and flags completed checkpoint
+// pointers which reference files that no longer exist, as those prevent the job from being restored.
+func (h *HandlerHighAvailability) GetHighAvailability(ctx context.Context, request *GetHighAvailabilityRequest) (httpserver.Response, error) {
+	deployment, exists := h.watcher.GetDeployment(request.Namespace, request.Name)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s/%s", ErrDeploymentNotFound, request.Namespace, request.Name)
+	}
+
+	report := &HighAvailabilityReport{
+		Namespace:  request.Namespace,
+		Name:       request.Name,
+		ClusterId:  request.Name,
+		ConfigMaps: []string{},
+		Leaders:    []HighAvailabilityLeader{},
+		Jobs:       []HighAvailabilityJob{},
+	}
+
+	if clusterId, ok := getStringConfig(deployment.Spec.FlinkConfiguration, "kubernetes.cluster-id"); ok {
+		report.ClusterId = clusterId
+	}
+
+	if storageDir, ok := getStringConfig(deployment.Spec.FlinkConfiguration, "high-availability.storageDir"); ok {
+		report.StorageDir = storageDir
+	}
+
+	h.logger.Info(ctx, "inspecting high availability metadata of %s/%s (cluster %s)", request.Namespace, request.Name, report.ClusterId)
+
+	configMaps, err := h.k8sService.ListHighAvailabilityConfigMaps(ctx, request.Namespace, report.ClusterId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch high availability config maps from kubernetes: %w", err)
+	}
+
+	parseHighAvailabilityConfigMaps(report, configMaps.Items)
+
+	for i := range report.Jobs {
+		h.checkJob(ctx, &report.Jobs[i])
+
+		for _, completed := range report.Jobs[i].CompletedCheckpoints {
+			report.Stale = report.Stale || completed.Stale
+		}
+	}
+
+	return httpserver.NewJsonResponse(report), nil
+}
+
+func (h *HandlerHighAvailability) checkJob(ctx context.Context, job *HighAvailabilityJob) {
+	if job.JobGraph != nil && job.JobGraph.PointerPath != "" {
+		job.JobGraph.PointerExists = h.objectExists(ctx, job.JobGraph.PointerPath)
+	}
+
+	for i := range job.CompletedCheckpoints {
+		h.checkCompletedCheckpoint(ctx, &job.CompletedCheckpoints[i])
+	}
+}
+
+func (h *HandlerHighAvailability) checkCompletedCheckpoint(ctx context.Context, completed *HighAvailabilityCompletedCheckpoint) {
+	if completed.PointerPath == "" {
+		completed.Stale = true
+		completed.Reason = "the config map entry does not contain a readable pointer"
+
+		return
+	}
+
+	if completed.PointerExists = h.objectExists(ctx, completed.PointerPath); !completed.PointerExists {
+		completed.Stale = true
+		completed.Reason = "the pointer file in the high availability storage was deleted"
+
+		return
+	}
+
+	checkpointPath, err := h.readCheckpointPath(ctx, completed.PointerPath)
+	if err != nil {
+		h.logger.Warn(ctx, "failed to read high availability pointer %s: %v", completed.PointerPath, err)
+
+		return
+	}
+
+	if checkpointPath == "" {
+		return
+	}
+
+	completed.CheckpointPath = checkpointPath
+	metadata, err := h.s3Service.GetMetadataInfo(ctx, checkpointPath)
+	if err != nil {
+		h.logger.Warn(ctx, "failed to check metadata for %s: %v", checkpointPath, err)
+
+		return
+	}
+
+	if completed.CheckpointExists = metadata.Exists; !completed.CheckpointExists {
+		completed.Stale = true
+		completed.Reason = "the referenced checkpoint was deleted"
+	}
+}
+
+func (h *HandlerHighAvailability) objectExists(ctx context.Context, s3URI string) bool {
+	info, err := h.s3Service.GetObjectInfo(ctx, s3URI)
+	if err != nil {
+		h.logger.Warn(ctx, "failed to check %s: %v", s3URI, err)
+
+		// we could not check it, so we don't report it as missing
+		return true
+	}
+
+	return info.Exists
+}
+
+// readCheckpointPath reads a completed checkpoint pointer file and returns the checkpoint directory it references.
+func (h *HandlerHighAvailability) readCheckpointPath(ctx context.Context, pointerPath string) (path string, err error) {
+	body, err := h.s3Service.OpenObject(ctx, pointerPath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if cerr := body.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("close pointer body: %w", cerr)
+		}
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxHaPointerSize))
+	if err != nil {
+		return "", fmt.Errorf("could not read pointer: %w", err)
+	}
+
+	for _, statePath := range checkpoint.FindStatePaths(data) {
+		if strings.HasSuffix(statePath, "/_metadata") {
+			return strings.TrimSuffix(statePath, "_metadata"), nil
+		}
+
+		if checkpointDirPattern.MatchString(statePath) {
+			return statePath, nil
+		}
+	}
+
+	return "", nil
+}
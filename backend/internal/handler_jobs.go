@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+func NewHandlerJobs(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerJobs, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_jobs")
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := ProvideJobRunHistoryModule(ctx, config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize job run history: %w", err)
+	}
+
+	redaction := &RedactionSettings{}
+	if err = config.UnmarshalKey("redaction", redaction); err != nil {
+		return nil, fmt.Errorf("could not unmarshal redaction settings: %w", err)
+	}
+
+	return &HandlerJobs{
+		flinkDeploymentHandler: base,
+		runs:                   runs,
+		redaction:              redaction,
+	}, nil
+}
+
+type HandlerJobs struct {
+	flinkDeploymentHandler
+	runs      *JobRunHistoryModule
+	redaction *RedactionSettings
+}
+
+type GetJobGraphRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+func (h *HandlerJobs) GetJobGraph(ctx context.Context, request *GetJobGraphRequest) (httpserver.Response, error) {
+	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching job graph for deployment %s/%s (job %s) from %s", request.Namespace, request.Name, jobID, flinkURL)
+
+	details, err := h.client.GetJob(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job from Flink: %w", err)
+	}
+
+	plan, err := h.client.GetJobPlan(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job plan from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(toJobGraph(details, plan)), nil
+}
+
+type GetJobEnvironmentRequest struct {
+	Namespace  string `uri:"namespace"`
+	Name       string `uri:"name"`
+	SessionJob string `uri:"sessionJob"`
+}
+
+// GetJobEnvironment returns the global job parameters the job received together with the program arguments of the spec
+// and the JVM options and classpath of the job manager, with sensitive values redacted.
+func (h *HandlerJobs) GetJobEnvironment(ctx context.Context, request *GetJobEnvironmentRequest) (httpserver.Response, error) {
+	job, err := h.watcher.GetJobSpec(request.Namespace, request.Name, request.SessionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	flinkURL, jobID, err := h.watcher.GetJobEndpoint(request.Namespace, request.Name, request.SessionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching job environment for deployment %s/%s (job %s) from %s", request.Namespace, request.Name, jobID, flinkURL)
+
+	config, err := h.client.GetJobConfig(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job config from Flink: %w", err)
+	}
+
+	// the environment of the job manager is not essential, so the job config is still returned without it
+	environment, envErr := h.client.GetJobManagerEnvironment(ctx, flinkURL)
+	if envErr != nil {
+		h.logger.Warn(ctx, "failed to fetch job manager environment of deployment %s/%s: %v", request.Namespace, request.Name, envErr)
+	}
+
+	result := buildJobEnvironment(h.redaction, config, job, environment)
+	if envErr != nil {
+		result.EnvironmentError = envErr.Error()
+	}
+
+	return httpserver.NewJsonResponse(result), nil
+}
+
+type GetJobRunsRequest struct {
+	Namespace  string `uri:"namespace"`
+	Name       string `uri:"name"`
+	SessionJob string `uri:"sessionJob"`
+}
+
+// GetJobRuns returns every job id the deployment or session job had with the state it ran and the checkpoint it
+// restored from.
+func (h *HandlerJobs) GetJobRuns(_ context.Context, request *GetJobRunsRequest) (httpserver.Response, error) {
+	history, err := h.runs.GetHistory(request.Namespace, request.Name, request.SessionJob)
+	if errors.Is(err, errInvalidStoragePath) {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job runs: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(history), nil
+}
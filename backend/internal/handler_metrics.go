@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+var metricAggregations = []string{"min", "max", "avg", "sum", "skew"}
+
+func NewHandlerMetrics(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerMetrics, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_metrics")
+	if err != nil {
+		return nil, err
+	}
+
+	sampler, err := ProvideMetricsSamplerModule(ctx, config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize metrics sampler: %w", err)
+	}
+
+	return &HandlerMetrics{
+		flinkDeploymentHandler: base,
+		sampler:                sampler,
+	}, nil
+}
+
+type HandlerMetrics struct {
+	flinkDeploymentHandler
+	sampler *MetricsSamplerModule
+}
+
+type GetMetricsRequest struct {
+	Namespace     string `uri:"namespace"`
+	Name          string `uri:"name"`
+	Scope         string `form:"scope"`
+	VertexId      string `form:"vertexId"`
+	Subtask       *int   `form:"subtask"`
+	TaskManagerId string `form:"taskmanagerId"`
+	Get           string `form:"get"`
+	Agg           string `form:"agg"`
+}
+
+type GetMetricsHistoryRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+	Scope     string `form:"scope"`
+	VertexId  string `form:"vertexId"`
+	Metric    string `form:"metric"`
+}
+
+// GetMetrics proxies the metric endpoint of the requested scope. Without metric names the available metrics are listed.
+// The aggregations only apply to the vertex scope and to the task manager scope without a task manager id.
+func (h *HandlerMetrics) GetMetrics(ctx context.Context, request *GetMetricsRequest) (httpserver.Response, error) {
+	scope := FlinkMetricScope{
+		Scope:         request.Scope,
+		VertexId:      request.VertexId,
+		Subtask:       request.Subtask,
+		TaskManagerId: request.TaskManagerId,
+	}
+	if scope.Scope == "" {
+		scope.Scope = MetricScopeJob
+	}
+
+	if _, _, err := scope.path(""); err != nil {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
+
+	aggregations := splitList(request.Agg)
+	for _, aggregation := range aggregations {
+		if !slices.Contains(metricAggregations, aggregation) {
+			return httpserver.GetErrorHandler()(http.StatusBadRequest, fmt.Errorf("unknown aggregation %q", aggregation)), nil
+		}
+	}
+
+	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "fetching %s metrics for deployment %s/%s (job %s) from %s", scope.Scope, request.Namespace, request.Name, jobID, flinkURL)
+
+	metrics, err := h.client.GetMetrics(ctx, flinkURL, jobID, scope, splitList(request.Get), aggregations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metrics from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(metrics), nil
+}
+
+// GetMetricsHistory returns the series sampled in the background for the deployment.
+func (h *HandlerMetrics) GetMetricsHistory(_ context.Context, request *GetMetricsHistoryRequest) (httpserver.Response, error) {
+	return httpserver.NewJsonResponse(h.sampler.GetSeries(request.Namespace, request.Name, request.Scope, request.VertexId, request.Metric)), nil
+}
+
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
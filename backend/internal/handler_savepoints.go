@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/encoding/json"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+const (
+	SavepointStatusTriggered  = "TRIGGERED"
+	SavepointStatusInProgress = "IN_PROGRESS"
+	SavepointStatusCompleted  = "COMPLETED"
+	SavepointStatusFailed     = "FAILED"
+)
+
+type SavepointTriggerSettings struct {
+	PollInterval time.Duration `cfg:"poll_interval" default:"2s"`
+	Timeout      time.Duration `cfg:"timeout" default:"30m"`
+}
+
+func NewHandlerSavepoints(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerSavepoints, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_savepoints")
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &SavepointTriggerSettings{}
+	if err = config.UnmarshalKey("savepoints.trigger", settings); err != nil {
+		return nil, fmt.Errorf("could not unmarshal savepoint trigger settings: %w", err)
+	}
+
+	return &HandlerSavepoints{
+		flinkDeploymentHandler: base,
+		settings:               settings,
+	}, nil
+}
+
+type HandlerSavepoints struct {
+	flinkDeploymentHandler
+	settings *SavepointTriggerSettings
+}
+
+// OptionalJsonBinding binds a JSON body like the json binding of gin, but accepts an empty body, which leaves the
+// request with its defaults. A savepoint can be triggered without a body this way.
+var OptionalJsonBinding binding.BindingBody = optionalJsonBinding{}
+
+type optionalJsonBinding struct{}
+
+func (optionalJsonBinding) Name() string {
+	return binding.JSON.Name()
+}
+
+func (b optionalJsonBinding) Bind(req *http.Request, obj any) error {
+	if req.Body == nil {
+		return b.BindBody(nil, obj)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("could not read request body: %w", err)
+	}
+
+	return b.BindBody(body, obj)
+}
+
+func (optionalJsonBinding) BindBody(body []byte, obj any) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return binding.Validator.ValidateStruct(obj)
+	}
+
+	return binding.JSON.BindBody(body, obj)
+}
+
+type TriggerSavepointRequest struct {
+	Namespace       string `uri:"namespace"`
+	Name            string `uri:"name"`
+	TargetDirectory string `json:"targetDirectory"`
+	FormatType      string `json:"formatType"`
+}
+
+// SavepointProgress is sent as SSE event whenever the status of a triggered savepoint was polled.
+type SavepointProgress struct {
+	TriggerId       string `json:"triggerId"`
+	JobId           string `json:"jobId"`
+	TargetDirectory string `json:"targetDirectory"`
+	FormatType      string `json:"formatType"`
+	Status          string `json:"status"`
+	ElapsedMs       int64  `json:"elapsedMs"`
+	Location        string `json:"location,omitempty"`
+	FailureCause    string `json:"failureCause,omitempty"`
+}
+
+// TriggerSavepoint triggers a savepoint through the REST API of Flink and streams its progress until it completed or failed.
+// The target directory defaults to the savepoint directory of the deployment. Closing the stream stops the polling,
+// but not the savepoint itself.
+func (h *HandlerSavepoints) TriggerSavepoint(ctx context.Context, request *TriggerSavepointRequest, writer *httpserver.SseWriter) error {
+	deployment, ok := h.watcher.GetDeployment(request.Namespace, request.Name)
+	if !ok {
+		return fmt.Errorf("%w: %s/%s", ErrDeploymentNotFound, request.Namespace, request.Name)
+	}
+
+	if request.TargetDirectory == "" {
+		if request.TargetDirectory, ok = getStringConfig(deployment.Spec.FlinkConfiguration, "execution.checkpointing.savepoint-dir"); !ok {
+			return fmt.Errorf("deployment %s/%s has no savepoint directory configured and no target directory was given", request.Namespace, request.Name)
+		}
+	}
+
+	if request.FormatType == "" {
+		request.FormatType = SavepointFormatCanonical
+	}
+
+	if request.FormatType != SavepointFormatCanonical && request.FormatType != SavepointFormatNative {
+		return fmt.Errorf("unsupported savepoint format type %q", request.FormatType)
+	}
+
+	flinkURL, jobID, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return err
+	}
+
+	triggerID, err := h.client.TriggerSavepoint(ctx, flinkURL, jobID, FlinkSavepointTriggerRequest{
+		TargetDirectory: request.TargetDirectory,
+		FormatType:      request.FormatType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to trigger savepoint in Flink: %w", err)
+	}
+
+	h.logger.Info(ctx, "triggered %s savepoint %s for deployment %s/%s (job %s) into %s", request.FormatType, triggerID, request.Namespace, request.Name, jobID, request.TargetDirectory)
+
+	start := time.Now()
+	progress := SavepointProgress{
+		TriggerId:       triggerID,
+		JobId:           jobID,
+		TargetDirectory: request.TargetDirectory,
+		FormatType:      request.FormatType,
+		Status:          SavepointStatusTriggered,
+	}
+
+	if err = sendSavepointProgress(writer, progress); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(h.settings.PollInterval)
+	defer ticker.Stop()
+
+	for progress.Status != SavepointStatusCompleted && progress.Status != SavepointStatusFailed {
+		select {
+		case <-ctx.Done():
+			h.logger.Info(ctx, "stopped tracking savepoint %s of deployment %s/%s as the client disconnected", triggerID, request.Namespace, request.Name)
+
+			return nil
+		case <-ticker.C:
+		}
+
+		if time.Since(start) > h.settings.Timeout {
+			return fmt.Errorf("savepoint %s did not complete within %s", triggerID, h.settings.Timeout)
+		}
+
+		status, err := h.client.GetSavepointStatus(ctx, flinkURL, jobID, triggerID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch savepoint status from Flink: %w", err)
+		}
+
+		progress.ElapsedMs = time.Since(start).Milliseconds()
+		progress.applyStatus(status)
+
+		if err = sendSavepointProgress(writer, progress); err != nil {
+			return err
+		}
+	}
+
+	h.logger.Info(ctx, "savepoint %s of deployment %s/%s finished with status %s", triggerID, request.Namespace, request.Name, progress.Status)
+
+	return nil
+}
+
+func (p *SavepointProgress) applyStatus(status *FlinkSavepointStatus) {
+	if status.Status.Id != flinkOperationCompleted {
+		p.Status = SavepointStatusInProgress
+
+		return
+	}
+
+	switch {
+	case status.Operation != nil && status.Operation.FailureCause != nil:
+		p.Status = SavepointStatusFailed
+		p.FailureCause = status.Operation.FailureCause.StackTrace
+		if p.FailureCause == "" {
+			p.FailureCause = status.Operation.FailureCause.Class
+		}
+	case status.Operation != nil:
+		p.Status = SavepointStatusCompleted
+		p.Location = status.Operation.Location
+	default:
+		p.Status = SavepointStatusFailed
+		p.FailureCause = "savepoint completed without a location"
+	}
+}
+
+func sendSavepointProgress(writer *httpserver.SseWriter, progress SavepointProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("could not marshal savepoint progress: %w", err)
+	}
+
+	if err = writer.SendEvent(httpserver.SseEvent{Event: "progress", Data: string(data)}); err != nil {
+		return fmt.Errorf("could not write savepoint progress to sse stream: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOptionalJsonBinding(t *testing.T) {
+	for body, expected := range map[string]TriggerSavepointRequest{
+		"":                         {},
+		"  ":                       {},
+		`{"formatType": "NATIVE"}`: {FormatType: SavepointFormatNative},
+	} {
+		req, err := http.NewRequest(http.MethodPost, "/savepoints", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		request := TriggerSavepointRequest{}
+		if err = OptionalJsonBinding.Bind(req, &request); err != nil {
+			t.Errorf("unexpected error for body %q: %v", body, err)
+		}
+
+		if request != expected {
+			t.Errorf("expected %+v for body %q, got %+v", expected, body, request)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "/savepoints", strings.NewReader("{"))
+	if err := OptionalJsonBinding.Bind(req, &TriggerSavepointRequest{}); err == nil {
+		t.Errorf("expected an invalid body to be rejected")
+	}
+}
@@ -3,7 +3,9 @@ package internal
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gosoline-project/httpserver"
 	"github.com/justtrackio/gosoline/pkg/cfg"
@@ -37,8 +39,28 @@ type HandlerStorageCheckpoints struct {
 }
 
 type GetStorageCheckpointsRequest struct {
-	Namespace string `uri:"namespace"`
-	Name      string `uri:"name"`
+	Namespace  string    `uri:"namespace"`
+	Name       string    `uri:"name"`
+	SessionJob string    `uri:"sessionJob"`
+	JobId      string    `form:"jobId"`
+	Type       string    `form:"type"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort       string    `form:"sort"`
+	Limit      int       `form:"limit"`
+	Cursor     string    `form:"cursor"`
+}
+
+func (r *GetStorageCheckpointsRequest) query() StorageCheckpointsQuery {
+	return StorageCheckpointsQuery{
+		JobId:  r.JobId,
+		Type:   r.Type,
+		From:   r.From,
+		To:     r.To,
+		Sort:   r.Sort,
+		Limit:  r.Limit,
+		Cursor: r.Cursor,
+	}
 }
 
 type StorageCheckpointsResponse struct {
@@ -47,12 +69,24 @@ type StorageCheckpointsResponse struct {
 	SavepointDir  string         `json:"savepointDir,omitempty"`
 	Checkpoints   []StorageEntry `json:"checkpoints"`
 	Savepoints    []StorageEntry `json:"savepoints"`
+	Total         int            `json:"total"`
+	NextCursor    string         `json:"nextCursor,omitempty"`
 }
 
 func (h *HandlerStorageCheckpoints) GetStorageCheckpoints(ctx context.Context, request *GetStorageCheckpointsRequest) (httpserver.Response, error) {
-	deployment, exists := h.watcher.GetDeployment(request.Namespace, request.Name)
-	if !exists {
-		return nil, fmt.Errorf("deployment %s/%s not found", request.Namespace, request.Name)
+	query := request.query()
+	if err := query.Validate(); err != nil {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
+
+	flinkConfig, jobId, err := h.watcher.GetJobConfiguration(request.Namespace, request.Name, request.SessionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	// the jobs of a session cluster share its checkpoint directory, so a session job only lists its own job by default
+	if request.SessionJob != "" && query.JobId == "" {
+		query.JobId = jobId
 	}
 
 	response := StorageCheckpointsResponse{
@@ -60,7 +94,6 @@ func (h *HandlerStorageCheckpoints) GetStorageCheckpoints(ctx context.Context, r
 		Savepoints:  []StorageEntry{},
 	}
 
-	flinkConfig := deployment.Spec.FlinkConfiguration
 	if flinkConfig == nil {
 		h.logger.Info(ctx, "returning %d checkpoints and %d savepoints for %s/%s",
 			len(response.Checkpoints), len(response.Savepoints), request.Namespace, request.Name)
@@ -68,26 +101,56 @@ func (h *HandlerStorageCheckpoints) GetStorageCheckpoints(ctx context.Context, r
 		return httpserver.NewJsonResponse(response), nil
 	}
 
+	var entries []StorageEntry
+
 	if checkpointBaseDir, ok := getStringConfig(flinkConfig, "execution.checkpointing.dir"); ok {
 		response.CheckpointDir = checkpointBaseDir
-		h.logger.Info(ctx, "scanning for checkpoints in all job IDs under %s", checkpointBaseDir)
-		if err := h.populateCheckpoints(ctx, checkpointBaseDir, &response); err != nil {
-			h.logger.Warn(ctx, "failed to list job directories: %v", err)
+
+		if query.includesType(StorageEntryTypeCheckpoint) {
+			h.logger.Info(ctx, "scanning for checkpoints under %s", checkpointBaseDir)
+			checkpoints, err := h.listCheckpoints(ctx, checkpointBaseDir, query)
+			if err != nil {
+				h.logger.Warn(ctx, "failed to list job directories: %v", err)
+			}
+			entries = append(entries, checkpoints...)
 		}
 	}
 
-	jobId := deployment.Status.JobStatus.JobId
 	if jobId != "" {
 		response.JobId = jobId
 	}
 
 	if savepointDir, ok := getStringConfig(flinkConfig, "execution.checkpointing.savepoint-dir"); ok {
 		response.SavepointDir = savepointDir
-		h.populateSavepoints(ctx, savepointDir, jobId, &response)
+
+		savepointJobId := jobId
+		if query.JobId != "" {
+			savepointJobId = query.JobId
+		}
+
+		if query.includesType(StorageEntryTypeSavepoint) {
+			entries = append(entries, h.listSavepoints(ctx, savepointDir, savepointJobId)...)
+		}
+	}
+
+	page, total, nextCursor, err := query.Apply(entries)
+	if err != nil {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, err), nil
+	}
+
+	for _, entry := range page {
+		if entry.Type == StorageEntryTypeSavepoint {
+			response.Savepoints = append(response.Savepoints, entry)
+		} else {
+			response.Checkpoints = append(response.Checkpoints, entry)
+		}
 	}
 
-	h.logger.Info(ctx, "returning %d checkpoints and %d savepoints for %s/%s",
-		len(response.Checkpoints), len(response.Savepoints), request.Namespace, request.Name)
+	response.Total = total
+	response.NextCursor = nextCursor
+
+	h.logger.Info(ctx, "returning %d checkpoints and %d savepoints of %d matching entries for %s/%s",
+		len(response.Checkpoints), len(response.Savepoints), total, request.Namespace, request.Name)
 
 	return httpserver.NewJsonResponse(response), nil
 }
@@ -106,9 +169,43 @@ func getStringConfig(config map[string]any, key string) (string, bool) {
 	return stringValue, true
 }
 
-func buildSavepointPath(savepointDir string, jobId string) string {
+// getStorageDirs returns the checkpoint and savepoint base directories configured for a deployment.
+func getStorageDirs(config map[string]any) []string {
+	var dirs []string
+
+	for _, key := range []string{"execution.checkpointing.dir", "execution.checkpointing.savepoint-dir"} {
+		if dir, ok := getStringConfig(config, key); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// getJobStorageDirs returns the checkpoint and savepoint directories of a single job below the base directories
+// configured for a deployment. It returns none for a job without id.
+func getJobStorageDirs(config map[string]any, jobId string) []string {
+	if jobId == "" {
+		return nil
+	}
+
+	var dirs []string
+
+	if dir, ok := getStringConfig(config, "execution.checkpointing.dir"); ok {
+		dirs = append(dirs, buildJobPath(dir, jobId))
+	}
+
+	if dir, ok := getStringConfig(config, "execution.checkpointing.savepoint-dir"); ok {
+		dirs = append(dirs, buildJobPath(dir, jobId))
+	}
+
+	return dirs
+}
+
+// buildJobPath returns the directory of a job below a checkpoint or savepoint directory.
+func buildJobPath(baseDir string, jobId string) string {
 	dashlessJobId := strings.ReplaceAll(jobId, "-", "")
-	path := savepointDir
+	path := baseDir
 	if !strings.HasSuffix(path, "/") {
 		path += "/"
 	}
@@ -116,13 +213,19 @@ func buildSavepointPath(savepointDir string, jobId string) string {
 	return path + dashlessJobId
 }
 
-func (h *HandlerStorageCheckpoints) populateCheckpoints(ctx context.Context, checkpointBaseDir string, response *StorageCheckpointsResponse) error {
-	jobIds, err := h.s3Service.ListJobDirectories(ctx, checkpointBaseDir)
-	if err != nil {
-		return err
+func (h *HandlerStorageCheckpoints) listCheckpoints(ctx context.Context, checkpointBaseDir string, query StorageCheckpointsQuery) ([]StorageEntry, error) {
+	var err error
+	var jobIds []string
+
+	if query.JobId != "" {
+		jobIds = []string{strings.ReplaceAll(query.JobId, "-", "")}
+	} else if jobIds, err = h.s3Service.ListJobDirectories(ctx, checkpointBaseDir); err != nil {
+		return nil, err
 	}
 
 	h.logger.Info(ctx, "found %d job directories to scan", len(jobIds))
+
+	var entries []StorageEntry
 	for _, jobId := range jobIds {
 		checkpoints, err := h.s3Service.ListValidCheckpoints(ctx, checkpointBaseDir, jobId)
 		if err != nil {
@@ -130,26 +233,41 @@ func (h *HandlerStorageCheckpoints) populateCheckpoints(ctx context.Context, che
 
 			continue
 		}
-		response.Checkpoints = append(response.Checkpoints, checkpoints...)
+		entries = append(entries, checkpoints...)
 	}
 
-	return nil
+	return entries, nil
 }
 
-func (h *HandlerStorageCheckpoints) populateSavepoints(ctx context.Context, savepointDir string, jobId string, response *StorageCheckpointsResponse) {
+func (h *HandlerStorageCheckpoints) listSavepoints(ctx context.Context, savepointDir string, jobId string) []StorageEntry {
 	if jobId == "" {
-		return
+		return nil
 	}
 
-	savepointPath := buildSavepointPath(savepointDir, jobId)
+	savepointPath := buildJobPath(savepointDir, jobId)
 	h.logger.Info(ctx, "listing savepoints from %s", savepointPath)
 
 	savepoints, err := h.s3Service.ListStorageCheckpoints(ctx, savepointPath)
 	if err != nil {
 		h.logger.Warn(ctx, "failed to list savepoints: %v", err)
 
-		return
+		return nil
+	}
+
+	for i := range savepoints {
+		savepoints[i].Type = StorageEntryTypeSavepoint
+		savepoints[i].JobId = strings.ReplaceAll(jobId, "-", "")
+
+		metadataInfo, err := h.s3Service.GetCompletedMetadataInfo(ctx, savepoints[i].Path)
+		if err != nil {
+			h.logger.Warn(ctx, "failed to check metadata for %s: %v", savepoints[i].Path, err)
+
+			continue
+		}
+
+		savepoints[i].LastModified = metadataInfo.LastModified
+		savepoints[i].Size = metadataInfo.Size
 	}
 
-	response.Savepoints = savepoints
+	return savepoints
 }
@@ -0,0 +1,309 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+const (
+	ExportFormatTar = "tar"
+	ExportFormatZip = "zip"
+)
+
+var exportContentTypes = map[string]string{
+	ExportFormatTar: "application/x-tar",
+	ExportFormatZip: "application/zip",
+}
+
+type StorageDownloadSettings struct {
+	PresignExpiry     time.Duration `cfg:"presign_expiry" default:"15m"`
+	MaxExportBytes    int64         `cfg:"max_export_bytes" default:"5368709120"`
+	MaxExportObjects  int           `cfg:"max_export_objects" default:"10000"`
+	AllowedNamespaces []string      `cfg:"allowed_namespaces"`
+}
+
+func NewHandlerStorageDownloads(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerStorageDownloads, error) {
+	var err error
+	var watcher *DeploymentWatcherModule
+	var s3Service *S3Service
+
+	settings := &StorageDownloadSettings{}
+	if err = config.UnmarshalKey("storage.download", settings); err != nil {
+		return nil, fmt.Errorf("could not unmarshal storage download settings: %w", err)
+	}
+
+	if watcher, err = ProvideDeploymentWatcherModule(ctx, config, logger); err != nil {
+		return nil, fmt.Errorf("could not initialize deployment watcher: %w", err)
+	}
+
+	if s3Service, err = ProvideS3Service(ctx, config, logger); err != nil {
+		return nil, fmt.Errorf("could not initialize s3 service: %w", err)
+	}
+
+	return &HandlerStorageDownloads{
+		logger:    logger.WithChannel("handler_storage_downloads"),
+		settings:  settings,
+		watcher:   watcher,
+		s3Service: s3Service,
+	}, nil
+}
+
+type HandlerStorageDownloads struct {
+	logger    log.Logger
+	settings  *StorageDownloadSettings
+	watcher   *DeploymentWatcherModule
+	s3Service *S3Service
+}
+
+type GetDownloadUrlRequest struct {
+	Namespace  string `uri:"namespace"`
+	Name       string `uri:"name"`
+	SessionJob string `uri:"sessionJob"`
+	Path       string `form:"path" binding:"required"`
+}
+
+type ExportDirectoryRequest struct {
+	Namespace  string `uri:"namespace"`
+	Name       string `uri:"name"`
+	SessionJob string `uri:"sessionJob"`
+	Path       string `form:"path" binding:"required"`
+	Format     string `form:"format"`
+}
+
+// GetDownloadUrl returns a presigned GET URL for a single file of a checkpoint or savepoint.
+// If the path points to a directory, the URL for its _metadata file is returned.
+func (h *HandlerStorageDownloads) GetDownloadUrl(ctx context.Context, request *GetDownloadUrlRequest) (httpserver.Response, error) {
+	objectPath := request.Path
+	if strings.HasSuffix(objectPath, "/") {
+		objectPath += "_metadata"
+	}
+
+	if status, err := h.authorize(request.Namespace, request.Name, request.SessionJob, objectPath); err != nil {
+		return httpserver.GetErrorHandler()(status, err), nil
+	}
+
+	h.logger.Info(ctx, "presigning %s for deployment %s/%s", objectPath, request.Namespace, request.Name)
+
+	url, err := h.s3Service.PresignGetObject(ctx, objectPath, h.settings.PresignExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign download url: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(url), nil
+}
+
+// ExportDirectory streams all objects below a checkpoint or savepoint directory as a tar or zip archive.
+// The archive is rejected up front if it would exceed the configured size or object count limits.
+func (h *HandlerStorageDownloads) ExportDirectory(ginCtx *gin.Context) {
+	request := &ExportDirectoryRequest{}
+	if err := ginCtx.ShouldBindUri(request); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+
+		return
+	}
+
+	if err := ginCtx.ShouldBindQuery(request); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+
+		return
+	}
+
+	if request.Format == "" {
+		request.Format = ExportFormatTar
+	}
+
+	if request.Format != ExportFormatTar && request.Format != ExportFormatZip {
+		ginCtx.JSON(http.StatusBadRequest, gin.H{"err": fmt.Sprintf("unsupported export format %q", request.Format)})
+
+		return
+	}
+
+	dir := request.Path
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+
+	if status, err := h.authorize(request.Namespace, request.Name, request.SessionJob, dir); err != nil {
+		ginCtx.JSON(status, gin.H{"err": err.Error()})
+
+		return
+	}
+
+	objects, err := h.s3Service.ListObjects(ginCtx, dir)
+	if err != nil {
+		ginCtx.JSON(http.StatusInternalServerError, gin.H{"err": fmt.Sprintf("failed to list export objects: %s", err)})
+
+		return
+	}
+
+	if len(objects) == 0 {
+		ginCtx.JSON(http.StatusNotFound, gin.H{"err": fmt.Sprintf("no objects found below %s", dir)})
+
+		return
+	}
+
+	if err = h.checkExportLimits(objects); err != nil {
+		ginCtx.JSON(http.StatusRequestEntityTooLarge, gin.H{"err": err.Error()})
+
+		return
+	}
+
+	archiveName := path.Base(strings.TrimSuffix(dir, "/")) + "." + request.Format
+	h.logger.Info(ginCtx, "exporting %d objects from %s as %s for deployment %s/%s", len(objects), dir, archiveName, request.Namespace, request.Name)
+
+	ginCtx.Header("Content-Type", exportContentTypes[request.Format])
+	ginCtx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", archiveName))
+	ginCtx.Status(http.StatusOK)
+
+	if err = h.writeArchive(ginCtx, ginCtx.Writer, request.Format, dir, objects); err != nil {
+		// the status line is already on the wire, so all we can do is to stop writing
+		h.logger.Error(ginCtx, "failed to export %s: %v", dir, err)
+		ginCtx.Abort()
+	}
+}
+
+// authorize makes sure downloads are enabled for the namespace and that the requested path belongs to
+// the checkpoint or savepoint storage of the deployment or session job. The jobs of a session cluster share its
+// storage, so a session job is restricted to the directories of its own job id. It returns the HTTP status to use
+// on failure.
+func (h *HandlerStorageDownloads) authorize(namespace string, name string, sessionJob string, s3URI string) (int, error) {
+	if len(h.settings.AllowedNamespaces) > 0 && !slices.Contains(h.settings.AllowedNamespaces, namespace) {
+		return http.StatusForbidden, fmt.Errorf("downloads are not allowed for namespace %s", namespace)
+	}
+
+	flinkConfig, jobId, err := h.watcher.GetJobConfiguration(namespace, name, sessionJob)
+	if err != nil {
+		return http.StatusNotFound, err
+	}
+
+	dirs := getStorageDirs(flinkConfig)
+	if sessionJob != "" {
+		dirs = getJobStorageDirs(flinkConfig, jobId)
+	}
+
+	if strings.Contains(s3URI, "/../") || strings.HasSuffix(s3URI, "/..") {
+		return http.StatusBadRequest, fmt.Errorf("invalid path %s", s3URI)
+	}
+
+	for _, dir := range dirs {
+		if !strings.HasSuffix(dir, "/") {
+			dir += "/"
+		}
+
+		if strings.HasPrefix(s3URI, dir) && s3URI != dir {
+			return http.StatusOK, nil
+		}
+	}
+
+	return http.StatusForbidden, fmt.Errorf("path %s is not part of the storage of deployment %s/%s", s3URI, namespace, name)
+}
+
+func (h *HandlerStorageDownloads) checkExportLimits(objects []StorageObject) error {
+	if len(objects) > h.settings.MaxExportObjects {
+		return fmt.Errorf("export contains %d objects, the limit is %d", len(objects), h.settings.MaxExportObjects)
+	}
+
+	var totalBytes int64
+	for _, object := range objects {
+		totalBytes += object.Size
+	}
+
+	if totalBytes > h.settings.MaxExportBytes {
+		return fmt.Errorf("export contains %d bytes, the limit is %d", totalBytes, h.settings.MaxExportBytes)
+	}
+
+	return nil
+}
+
+func (h *HandlerStorageDownloads) writeArchive(ctx context.Context, writer io.Writer, format string, dir string, objects []StorageObject) error {
+	if format == ExportFormatZip {
+		return h.writeZip(ctx, writer, dir, objects)
+	}
+
+	return h.writeTar(ctx, writer, dir, objects)
+}
+
+func (h *HandlerStorageDownloads) writeTar(ctx context.Context, writer io.Writer, dir string, objects []StorageObject) error {
+	tw := tar.NewWriter(writer)
+
+	for _, object := range objects {
+		header := &tar.Header{
+			Name:    strings.TrimPrefix(object.Path, dir),
+			Mode:    0o644,
+			Size:    object.Size,
+			ModTime: object.LastModified,
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("could not write tar header for %s: %w", object.Path, err)
+		}
+
+		if err := h.copyObject(ctx, tw, object); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("could not finish tar archive: %w", err)
+	}
+
+	return nil
+}
+
+func (h *HandlerStorageDownloads) writeZip(ctx context.Context, writer io.Writer, dir string, objects []StorageObject) error {
+	zw := zip.NewWriter(writer)
+
+	for _, object := range objects {
+		header := &zip.FileHeader{
+			Name:     strings.TrimPrefix(object.Path, dir),
+			Method:   zip.Deflate,
+			Modified: object.LastModified,
+		}
+
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("could not write zip header for %s: %w", object.Path, err)
+		}
+
+		if err = h.copyObject(ctx, entry, object); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("could not finish zip archive: %w", err)
+	}
+
+	return nil
+}
+
+func (h *HandlerStorageDownloads) copyObject(ctx context.Context, writer io.Writer, object StorageObject) (err error) {
+	body, err := h.s3Service.OpenObject(ctx, object.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := body.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("close object body: %w", cerr)
+		}
+	}()
+
+	if _, err = io.Copy(writer, body); err != nil {
+		return fmt.Errorf("could not copy %s: %w", object.Path, err)
+	}
+
+	return nil
+}
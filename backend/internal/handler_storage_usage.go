@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+func NewHandlerStorageUsage(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerStorageUsage, error) {
+	module, err := ProvideStorageUsageModule(ctx, config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize storage usage module: %w", err)
+	}
+
+	return &HandlerStorageUsage{
+		logger: logger.WithChannel("handler_storage_usage"),
+		module: module,
+	}, nil
+}
+
+type HandlerStorageUsage struct {
+	logger log.Logger
+	module *StorageUsageModule
+}
+
+type GetDeploymentStorageUsageRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+type DeploymentStorageUsageResponse struct {
+	Usage *DeploymentStorageUsage       `json:"usage"`
+	Trend []DeploymentStorageUsagePoint `json:"trend"`
+}
+
+// GetStorageUsage returns the latest storage usage report rolled up per namespace.
+// The report is empty until the first background scan has finished.
+func (h *HandlerStorageUsage) GetStorageUsage(_ context.Context) (httpserver.Response, error) {
+	report, ok := h.module.GetLatestReport()
+	if !ok {
+		return httpserver.NewJsonResponse(StorageUsageReport{
+			Namespaces:  []NamespaceStorageUsage{},
+			Deployments: []DeploymentStorageUsage{},
+		}), nil
+	}
+
+	return httpserver.NewJsonResponse(report), nil
+}
+
+func (h *HandlerStorageUsage) GetStorageUsageHistory(_ context.Context) (httpserver.Response, error) {
+	return httpserver.NewJsonResponse(h.module.GetHistory()), nil
+}
+
+func (h *HandlerStorageUsage) GetDeploymentStorageUsage(_ context.Context, request *GetDeploymentStorageUsageRequest) (httpserver.Response, error) {
+	usage, trend := h.module.GetDeploymentUsage(request.Namespace, request.Name)
+
+	return httpserver.NewJsonResponse(DeploymentStorageUsageResponse{
+		Usage: usage,
+		Trend: trend,
+	}), nil
+}
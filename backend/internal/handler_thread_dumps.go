@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+func NewHandlerThreadDumps(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerThreadDumps, error) {
+	base, err := newFlinkDeploymentHandler(ctx, config, logger, "handler_thread_dumps")
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := ProvideThreadDumpStore(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("could not create thread dump store: %w", err)
+	}
+
+	return &HandlerThreadDumps{
+		flinkDeploymentHandler: base,
+		store:                  store,
+	}, nil
+}
+
+// HandlerThreadDumps captures and analyzes thread dumps of the job manager and the task managers of a deployment.
+type HandlerThreadDumps struct {
+	flinkDeploymentHandler
+	store *ThreadDumpStore
+}
+
+type GetThreadDumpRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+	DumpId    string `uri:"dumpId"`
+}
+
+type CompareThreadDumpsRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+	Before    string `form:"before"`
+	After     string `form:"after"`
+}
+
+// CaptureTaskManagerThreadDump takes a thread dump of a task manager, analyzes and keeps it.
+func (h *HandlerThreadDumps) CaptureTaskManagerThreadDump(ctx context.Context, request *GetTaskManagerRequest) (httpserver.Response, error) {
+	flinkURL, _, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "capturing thread dump of task manager %s for deployment %s/%s from %s", request.TaskManager, request.Namespace, request.Name, flinkURL)
+
+	dump, err := h.client.GetTaskManagerThreadDump(ctx, flinkURL, request.TaskManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch thread dump from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(h.store.Add(request.Namespace, request.Name, ThreadDumpSourceTaskManager, request.TaskManager, dump)), nil
+}
+
+// CaptureJobManagerThreadDump takes a thread dump of the job manager, analyzes and keeps it.
+func (h *HandlerThreadDumps) CaptureJobManagerThreadDump(ctx context.Context, request *GetClusterRequest) (httpserver.Response, error) {
+	flinkURL, _, err := h.watcher.GetFlinkEndpoint(request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "capturing thread dump of job manager for deployment %s/%s from %s", request.Namespace, request.Name, flinkURL)
+
+	dump, err := h.client.GetJobManagerThreadDump(ctx, flinkURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch thread dump from Flink: %w", err)
+	}
+
+	return httpserver.NewJsonResponse(h.store.Add(request.Namespace, request.Name, ThreadDumpSourceJobManager, "", dump)), nil
+}
+
+// ListThreadDumps lists the kept thread dumps of a deployment, the latest first.
+func (h *HandlerThreadDumps) ListThreadDumps(_ context.Context, request *GetClusterRequest) (httpserver.Response, error) {
+	return httpserver.NewJsonResponse(h.store.List(request.Namespace, request.Name)), nil
+}
+
+func (h *HandlerThreadDumps) GetThreadDump(_ context.Context, request *GetThreadDumpRequest) (httpserver.Response, error) {
+	dump, ok := h.store.Get(request.Namespace, request.Name, request.DumpId)
+	if !ok {
+		return httpserver.GetErrorHandler()(http.StatusNotFound, fmt.Errorf("thread dump %s not found", request.DumpId)), nil
+	}
+
+	return httpserver.NewJsonResponse(dump), nil
+}
+
+// CompareThreadDumps compares two kept thread dumps of the same job manager or task manager.
+func (h *HandlerThreadDumps) CompareThreadDumps(_ context.Context, request *CompareThreadDumpsRequest) (httpserver.Response, error) {
+	before, ok := h.store.Get(request.Namespace, request.Name, request.Before)
+	if !ok {
+		return httpserver.GetErrorHandler()(http.StatusNotFound, fmt.Errorf("thread dump %s not found", request.Before)), nil
+	}
+
+	after, ok := h.store.Get(request.Namespace, request.Name, request.After)
+	if !ok {
+		return httpserver.GetErrorHandler()(http.StatusNotFound, fmt.Errorf("thread dump %s not found", request.After)), nil
+	}
+
+	if before.Source != after.Source || before.TaskManagerId != after.TaskManagerId {
+		return httpserver.GetErrorHandler()(http.StatusBadRequest, fmt.Errorf("thread dumps %s and %s were not taken from the same process", before.Id, after.Id)), nil
+	}
+
+	if after.CapturedAt.Before(before.CapturedAt) {
+		before, after = after, before
+	}
+
+	return httpserver.NewJsonResponse(compareThreadDumps(before, after)), nil
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gosoline-project/httpserver"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+func NewHandlerWatermarks(ctx context.Context, config cfg.Config, logger log.Logger) (*HandlerWatermarks, error) {
+	module, err := ProvideWatermarkMonitorModule(ctx, config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize watermark monitor: %w", err)
+	}
+
+	return &HandlerWatermarks{
+		logger: logger.WithChannel("handler_watermarks"),
+		module: module,
+	}, nil
+}
+
+type HandlerWatermarks struct {
+	logger log.Logger
+	module *WatermarkMonitorModule
+}
+
+type GetWatermarksRequest struct {
+	Namespace string `uri:"namespace"`
+	Name      string `uri:"name"`
+}
+
+// GetWatermarks polls the current watermarks of the deployment. Idle subtasks can only be detected once
+// the deployment was observed for the configured idle timeout, either by the monitor or by earlier requests.
+func (h *HandlerWatermarks) GetWatermarks(ctx context.Context, request *GetWatermarksRequest) (httpserver.Response, error) {
+	h.logger.Info(ctx, "checking watermarks of deployment %s/%s", request.Namespace, request.Name)
+
+	report, err := h.module.Check(ctx, request.Namespace, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return httpserver.NewJsonResponse(report), nil
+}
+
+// GetWatermarkAlerts returns the active watermark alerts of all deployments.
+func (h *HandlerWatermarks) GetWatermarkAlerts(_ context.Context) (httpserver.Response, error) {
+	return httpserver.NewJsonResponse(h.module.GetAlerts()), nil
+}
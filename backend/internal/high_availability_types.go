@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/justtrackio/flink-admin/internal/checkpoint"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	haLeaderAnnotation         = "control-plane.alpha.kubernetes.io/leader"
+	haLeaderKeyPrefix          = "org.apache.flink.k8s.leader."
+	haCheckpointCounterKey     = "counter"
+	haCompletedCheckpointKey   = "completedCheckpoint"
+	haJobGraphKeyPrefix        = "jobGraph-"
+	haExecutionPlanKeyPrefix   = "executionPlan-"
+	haClusterConfigMapSuffix   = "-cluster-config-map"
+	haJobConfigMapSuffix       = "-config-map"
+	haLegacyLeaderAddressKey   = "address"
+	haLegacyLeaderSessionIdKey = "sessionId"
+)
+
+// HighAvailabilityReport describes the Kubernetes HA metadata of a Flink cluster.
+type HighAvailabilityReport struct {
+	Namespace  string                   `json:"namespace"`
+	Name       string                   `json:"name"`
+	ClusterId  string                   `json:"clusterId"`
+	StorageDir string                   `json:"storageDir,omitempty"`
+	ConfigMaps []string                 `json:"configMaps"`
+	Leaders    []HighAvailabilityLeader `json:"leaders"`
+	Jobs       []HighAvailabilityJob    `json:"jobs"`
+	Stale      bool                     `json:"stale"`
+}
+
+// HighAvailabilityLeader is the leader of a single component (dispatcher, resource manager, rest server or job).
+type HighAvailabilityLeader struct {
+	Component string `json:"component"`
+	ConfigMap string `json:"configMap"`
+	Address   string `json:"address,omitempty"`
+	SessionId string `json:"sessionId,omitempty"`
+	Holder    string `json:"holder,omitempty"`
+	RenewTime string `json:"renewTime,omitempty"`
+}
+
+// HighAvailabilityJob contains the HA state stored for a single job ID.
+type HighAvailabilityJob struct {
+	JobId                string                                `json:"jobId"`
+	CheckpointIdCounter  *int64                                `json:"checkpointIdCounter,omitempty"`
+	CompletedCheckpoints []HighAvailabilityCompletedCheckpoint `json:"completedCheckpoints"`
+	JobGraph             *HighAvailabilityPointer              `json:"jobGraph,omitempty"`
+}
+
+// HighAvailabilityCompletedCheckpoint is a completed checkpoint pointer of the HA services. The pointer file in the
+// HA storage directory references the checkpoint the job restores from when it comes back.
+type HighAvailabilityCompletedCheckpoint struct {
+	CheckpointId     int64  `json:"checkpointId"`
+	ConfigMap        string `json:"configMap"`
+	Key              string `json:"key"`
+	PointerPath      string `json:"pointerPath,omitempty"`
+	PointerExists    bool   `json:"pointerExists"`
+	CheckpointPath   string `json:"checkpointPath,omitempty"`
+	CheckpointExists bool   `json:"checkpointExists"`
+	Stale            bool   `json:"stale"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// HighAvailabilityPointer is a state handle stored in a ConfigMap that points to a blob in the HA storage directory.
+type HighAvailabilityPointer struct {
+	ConfigMap     string `json:"configMap"`
+	Key           string `json:"key"`
+	PointerPath   string `json:"pointerPath,omitempty"`
+	PointerExists bool   `json:"pointerExists"`
+}
+
+type haLeaderRecord struct {
+	HolderIdentity string `json:"holderIdentity"`
+	RenewTime      string `json:"renewTime"`
+}
+
+// parseHighAvailabilityConfigMaps extracts leaders, checkpoint counters, completed checkpoint pointers and
+// job graph pointers from the HA ConfigMaps of a cluster. Existence of the referenced files is not checked here.
+func parseHighAvailabilityConfigMaps(report *HighAvailabilityReport, items []corev1.ConfigMap) {
+	jobs := map[string]*HighAvailabilityJob{}
+	getJob := func(jobId string) *HighAvailabilityJob {
+		if _, ok := jobs[jobId]; !ok {
+			jobs[jobId] = &HighAvailabilityJob{JobId: jobId, CompletedCheckpoints: []HighAvailabilityCompletedCheckpoint{}}
+		}
+
+		return jobs[jobId]
+	}
+
+	for _, item := range items {
+		report.ConfigMaps = append(report.ConfigMaps, item.Name)
+		holder := parseHaLeaderRecord(item.Annotations[haLeaderAnnotation])
+		jobId := haJobIdFromConfigMap(report.ClusterId, item.Name)
+
+		if address, ok := item.Data[haLegacyLeaderAddressKey]; ok {
+			component := strings.TrimSuffix(strings.TrimPrefix(item.Name, report.ClusterId+"-"), "-leader")
+			report.Leaders = append(report.Leaders, newHaLeader(component, item.Name, address, item.Data[haLegacyLeaderSessionIdKey], holder))
+		}
+
+		for key, value := range item.Data {
+			switch {
+			case strings.HasPrefix(key, haLeaderKeyPrefix):
+				address, sessionId := splitHaLeaderValue(value)
+				report.Leaders = append(report.Leaders, newHaLeader(strings.TrimPrefix(key, haLeaderKeyPrefix), item.Name, address, sessionId, holder))
+			case key == haCheckpointCounterKey && jobId != "":
+				if counter, err := strconv.ParseInt(value, 10, 64); err == nil {
+					getJob(jobId).CheckpointIdCounter = &counter
+				}
+			case strings.HasPrefix(key, haCompletedCheckpointKey) && jobId != "":
+				checkpointId, _ := strconv.ParseInt(strings.TrimPrefix(key, haCompletedCheckpointKey), 10, 64)
+				job := getJob(jobId)
+				job.CompletedCheckpoints = append(job.CompletedCheckpoints, HighAvailabilityCompletedCheckpoint{
+					CheckpointId: checkpointId,
+					ConfigMap:    item.Name,
+					Key:          key,
+					PointerPath:  decodeHaPointer(value),
+				})
+			case strings.HasPrefix(key, haJobGraphKeyPrefix), strings.HasPrefix(key, haExecutionPlanKeyPrefix):
+				// Flink 2.0 stores the job graph as an execution plan
+				jobId := strings.TrimPrefix(strings.TrimPrefix(key, haJobGraphKeyPrefix), haExecutionPlanKeyPrefix)
+				getJob(jobId).JobGraph = &HighAvailabilityPointer{
+					ConfigMap:   item.Name,
+					Key:         key,
+					PointerPath: decodeHaPointer(value),
+				}
+			}
+		}
+	}
+
+	for _, job := range jobs {
+		sort.Slice(job.CompletedCheckpoints, func(i, j int) bool {
+			return job.CompletedCheckpoints[i].CheckpointId > job.CompletedCheckpoints[j].CheckpointId
+		})
+		report.Jobs = append(report.Jobs, *job)
+	}
+
+	sort.Slice(report.Jobs, func(i, j int) bool {
+		return report.Jobs[i].JobId < report.Jobs[j].JobId
+	})
+	sort.Slice(report.Leaders, func(i, j int) bool {
+		return report.Leaders[i].Component < report.Leaders[j].Component
+	})
+	sort.Strings(report.ConfigMaps)
+}
+
+func newHaLeader(component string, configMap string, address string, sessionId string, holder haLeaderRecord) HighAvailabilityLeader {
+	return HighAvailabilityLeader{
+		Component: component,
+		ConfigMap: configMap,
+		Address:   address,
+		SessionId: sessionId,
+		Holder:    holder.HolderIdentity,
+		RenewTime: holder.RenewTime,
+	}
+}
+
+func parseHaLeaderRecord(annotation string) haLeaderRecord {
+	record := haLeaderRecord{}
+	if annotation != "" {
+		_ = json.Unmarshal([]byte(annotation), &record)
+	}
+
+	return record
+}
+
+// splitHaLeaderValue splits the "<address>,<session id>" value Flink stores for each leader.
+func splitHaLeaderValue(value string) (address string, sessionId string) {
+	idx := strings.LastIndex(value, ",")
+	if idx < 0 {
+		return value, ""
+	}
+
+	return value[:idx], value[idx+1:]
+}
+
+// haJobIdFromConfigMap returns the job ID of a job specific "<cluster id>-<job id>-config-map".
+func haJobIdFromConfigMap(clusterId string, configMap string) string {
+	if strings.HasSuffix(configMap, haClusterConfigMapSuffix) || !strings.HasSuffix(configMap, haJobConfigMapSuffix) {
+		return ""
+	}
+
+	jobId := strings.TrimSuffix(strings.TrimPrefix(configMap, clusterId+"-"), haJobConfigMapSuffix)
+	if !storageJobIdPattern.MatchString(jobId) {
+		return ""
+	}
+
+	return jobId
+}
+
+// decodeHaPointer decodes the base64 encoded, java serialized state handle stored in a ConfigMap
+// and returns the path of the file it points to.
+func decodeHaPointer(value string) string {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return ""
+	}
+
+	paths := checkpoint.FindStatePaths(data)
+	if len(paths) == 0 {
+		return ""
+	}
+
+	// the pointer is a java serialized state handle, its path is a java string with a two byte length prefix, which
+	// cuts off printable bytes following the path like the end block marker of the serialized uri
+	path := paths[0]
+	if idx := bytes.Index(data, []byte(path)); idx >= 2 {
+		if length := int(data[idx-2])<<8 | int(data[idx-1]); length > 0 && length < len(path) {
+			path = path[:length]
+		}
+	}
+
+	return path
+}
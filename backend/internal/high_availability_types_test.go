@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"encoding/base64"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// javaSerializedStateHandle mimics the java serialized RetrievableStreamStateHandle Flink stores in its HA ConfigMaps,
+// the path is written as a java string with a two byte length prefix.
+func javaSerializedStateHandle(path string) string {
+	data := []byte("\xac\xed\x00\x05sr\x00\x3borg.apache.flink.runtime.state.RetrievableStreamStateHandle\x00\x00\x00\x00\x00\x00\x00\x01\x02\x00\x01L\x00\x18wrappedStreamStateHandle")
+	data = append(data, 0x74, byte(len(path)>>8), byte(len(path)))
+	data = append(data, path...)
+	data = append(data, 0x78)
+
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestParseHighAvailabilityConfigMaps(t *testing.T) {
+	jobId := "3c5c0e4bd2b3d5e8d6a0f4f1f2e7a9b1"
+	leader := `{"holderIdentity":"a1b2c3d4-jobmanager","leaseDuration":15.0,"acquireTime":"2026-10-18T08:00:00.000000Z","renewTime":"2026-10-18T09:00:00.000000Z","leaderTransitions":0}`
+
+	items := []corev1.ConfigMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders-cluster-config-map", Annotations: map[string]string{haLeaderAnnotation: leader}},
+			Data: map[string]string{
+				"org.apache.flink.k8s.leader.dispatcher": "pekko.tcp://flink@10.0.0.1:6123/user/rpc/dispatcher_1,7f8e9d6c-5b4a-3c2d-1e0f-a1b2c3d4e5f6",
+				"org.apache.flink.k8s.leader.restserver": "http://orders-rest.flink:8081,7f8e9d6c-5b4a-3c2d-1e0f-a1b2c3d4e5f6",
+				"executionPlan-" + jobId:                 javaSerializedStateHandle("s3://bucket/ha/orders/submittedExecutionPlan9f8e7d6c5b4a"),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders-" + jobId + "-config-map", Annotations: map[string]string{haLeaderAnnotation: leader}},
+			Data: map[string]string{
+				"counter":                                "13",
+				"completedCheckpoint0000000000000000011": javaSerializedStateHandle("s3://bucket/ha/orders/completedCheckpoint0a1b2c3d4e5f"),
+				"completedCheckpoint0000000000000000012": javaSerializedStateHandle("s3://bucket/ha/orders/completedCheckpoint6a7b8c9d0e1f"),
+				"org.apache.flink.k8s.leader." + jobId:   "pekko.tcp://flink@10.0.0.1:6123/user/rpc/jobmanager_2,0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d",
+			},
+		},
+	}
+
+	report := &HighAvailabilityReport{ClusterId: "orders"}
+	parseHighAvailabilityConfigMaps(report, items)
+
+	if len(report.Jobs) != 1 || report.Jobs[0].JobId != jobId {
+		t.Fatalf("expected the single job %s, got %+v", jobId, report.Jobs)
+	}
+
+	job := report.Jobs[0]
+	if job.CheckpointIdCounter == nil || *job.CheckpointIdCounter != 13 {
+		t.Errorf("expected the checkpoint id counter 13, got %v", job.CheckpointIdCounter)
+	}
+
+	if len(job.CompletedCheckpoints) != 2 || job.CompletedCheckpoints[0].CheckpointId != 12 ||
+		job.CompletedCheckpoints[0].PointerPath != "s3://bucket/ha/orders/completedCheckpoint6a7b8c9d0e1f" {
+		t.Errorf("unexpected completed checkpoints %+v", job.CompletedCheckpoints)
+	}
+
+	if job.JobGraph == nil || job.JobGraph.PointerPath != "s3://bucket/ha/orders/submittedExecutionPlan9f8e7d6c5b4a" {
+		t.Errorf("unexpected execution plan pointer %+v", job.JobGraph)
+	}
+
+	if len(report.Leaders) != 3 || report.Leaders[0].Component != jobId || report.Leaders[1].Component != "dispatcher" ||
+		report.Leaders[1].Holder != "a1b2c3d4-jobmanager" || report.Leaders[2].Address != "http://orders-rest.flink:8081" {
+		t.Errorf("unexpected leaders %+v", report.Leaders)
+	}
+}
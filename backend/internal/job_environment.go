@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces sensitive values, it is the same placeholder Flink uses in its own configuration.
+const redactedValue = "******"
+
+// urlCredentials matches the user info of URLs like s3://user:password@bucket, whose password is redacted in any value.
+var urlCredentials = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^/@\s:]+):[^/@\s]+@`)
+
+type RedactionSettings struct {
+	SensitiveKeys []string `cfg:"sensitive_keys"`
+}
+
+// JobEnvironment shows which parameters a running job actually received: the global job parameters it registered,
+// the program arguments of the spec and the JVM options and classpath of the job manager. Sensitive values are
+// redacted and the keys they belong to are listed.
+type JobEnvironment struct {
+	JobId           string               `json:"jobId"`
+	JobName         string               `json:"jobName"`
+	ExecutionConfig FlinkExecutionConfig `json:"executionConfig"`
+	Args            []string             `json:"args"`
+	Jvm             *FlinkJvmInfo        `json:"jvm,omitempty"`
+	Classpath       []string             `json:"classpath"`
+	// EnvironmentError is set if the environment of the job manager could not be fetched, the job config is returned anyway
+	EnvironmentError string   `json:"environmentError,omitempty"`
+	RedactedKeys     []string `json:"redactedKeys"`
+}
+
+// valueRedactor hides values whose key contains one of the sensitive keys, compared case-insensitively like Flink
+// hides sensitive options of its configuration.
+type valueRedactor struct {
+	sensitiveKeys []string
+	redacted      map[string]bool
+}
+
+func newValueRedactor(settings *RedactionSettings) *valueRedactor {
+	redactor := &valueRedactor{
+		sensitiveKeys: make([]string, 0, len(settings.SensitiveKeys)),
+		redacted:      map[string]bool{},
+	}
+
+	for _, key := range settings.SensitiveKeys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			redactor.sensitiveKeys = append(redactor.sensitiveKeys, key)
+		}
+	}
+
+	return redactor
+}
+
+func (r *valueRedactor) isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range r.sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// value returns the value of the key with a sensitive value or the credentials of URLs redacted.
+func (r *valueRedactor) value(key string, value string) string {
+	return r.redact(key, key, value)
+}
+
+// redact hides the value if the key is sensitive and lists it by the label. Values without a key of their own, like
+// positional arguments, are listed by a generic label, as listing them by their content would leak it.
+func (r *valueRedactor) redact(label string, key string, value string) string {
+	if r.isSensitive(key) {
+		r.redacted[label] = true
+
+		return redactedValue
+	}
+
+	if redacted := urlCredentials.ReplaceAllString(value, "$1:"+redactedValue+"@"); redacted != value {
+		r.redacted[label] = true
+
+		return redacted
+	}
+
+	return value
+}
+
+func (r *valueRedactor) values(values map[string]string) map[string]string {
+	redacted := make(map[string]string, len(values))
+	for key, value := range values {
+		redacted[key] = r.value(key, value)
+	}
+
+	return redacted
+}
+
+// jvmOptions redacts the values of system properties like -Djavax.net.ssl.keyStorePassword=secret.
+func (r *valueRedactor) jvmOptions(options []string) []string {
+	redacted := make([]string, len(options))
+	for i, option := range options {
+		key, value, ok := strings.Cut(strings.TrimPrefix(option, "-D"), "=")
+		if !ok || !strings.HasPrefix(option, "-D") {
+			redacted[i] = r.redact("jvm options", option, option)
+
+			continue
+		}
+
+		redacted[i] = "-D" + key + "=" + r.value(key, value)
+	}
+
+	return redacted
+}
+
+// args redacts program arguments given as --key=value or as --key followed by the value.
+func (r *valueRedactor) args(args []string) []string {
+	redacted := make([]string, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			redacted[i] = r.redact("args", "", arg)
+
+			continue
+		}
+
+		key := strings.TrimLeft(arg, "-")
+		if name, value, ok := strings.Cut(key, "="); ok {
+			redacted[i] = arg[:len(arg)-len(key)] + name + "=" + r.value(name, value)
+
+			continue
+		}
+
+		redacted[i] = arg
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			redacted[i] = r.value(key, args[i])
+		}
+	}
+
+	return redacted
+}
+
+func (r *valueRedactor) keys() []string {
+	keys := make([]string, 0, len(r.redacted))
+	for key := range r.redacted {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// buildJobEnvironment combines the job config, the job of the spec and the environment of the job manager, which is
+// nil if it could not be fetched, and redacts the sensitive values of all of them.
+func buildJobEnvironment(settings *RedactionSettings, config *FlinkJobConfig, job *FlinkDeploymentJob, environment *FlinkJobManagerEnvironment) *JobEnvironment {
+	redactor := newValueRedactor(settings)
+
+	result := &JobEnvironment{
+		JobId:           config.JobId,
+		JobName:         config.Name,
+		ExecutionConfig: config.ExecutionConfig,
+		Args:            []string{},
+		Classpath:       []string{},
+	}
+	result.ExecutionConfig.UserConfig = redactor.values(config.ExecutionConfig.UserConfig)
+
+	if job != nil {
+		result.Args = redactor.args(job.Args)
+	}
+
+	if environment != nil {
+		jvm := environment.Jvm
+		jvm.Options = redactor.jvmOptions(jvm.Options)
+
+		result.Jvm = &jvm
+		if environment.Classpath != nil {
+			result.Classpath = environment.Classpath
+		}
+	}
+
+	result.RedactedKeys = redactor.keys()
+
+	return result
+}
@@ -0,0 +1,396 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JobRunStateDeleted is the terminal state of a run whose deployment was deleted while the job was running.
+const JobRunStateDeleted = "DELETED"
+
+// JobRun is a single job id a deployment had, e.g. between two upgrades or last-state restarts.
+type JobRun struct {
+	JobId         string     `json:"jobId"`
+	State         string     `json:"state"`
+	TerminalState string     `json:"terminalState,omitempty"`
+	StartTime     time.Time  `json:"startTime"`
+	EndTime       *time.Time `json:"endTime,omitempty"`
+	Image         string     `json:"image,omitempty"`
+	FlinkVersion  string     `json:"flinkVersion,omitempty"`
+	UpgradeMode   string     `json:"upgradeMode,omitempty"`
+	Generation    int64      `json:"generation"`
+	// Restored is the checkpoint or savepoint the run restored its state from, nil if it started without state
+	Restored *FlinkRestoredCheckpoint `json:"restored,omitempty"`
+	// RestoreChecked tells whether the restored checkpoint was fetched from Flink already
+	RestoreChecked bool      `json:"restoreChecked"`
+	FirstSeen      time.Time `json:"firstSeen"`
+	LastSeen       time.Time `json:"lastSeen"`
+}
+
+// JobRunHistory lists the runs of a deployment or session job, the latest first. The changes of a run are the parts of the
+// deployment which differ from the run before, which points to the deploy which introduced a regression.
+type JobRunHistory struct {
+	Namespace  string          `json:"namespace"`
+	Name       string          `json:"name"`
+	SessionJob string          `json:"sessionJob,omitempty"`
+	Runs       []JobRunSummary `json:"runs"`
+}
+
+type JobRunSummary struct {
+	JobRun
+	Changes []string `json:"changes"`
+}
+
+// jobRunTarget identifies the deployment or, if a session job is given, the session job on the session cluster of
+// the deployment whose runs are tracked.
+type jobRunTarget struct {
+	Namespace  string
+	Name       string
+	SessionJob string
+}
+
+func (t jobRunTarget) String() string {
+	if t.SessionJob == "" {
+		return t.Namespace + "/" + t.Name
+	}
+
+	return t.Namespace + "/" + t.Name + "/" + t.SessionJob
+}
+
+func (t jobRunTarget) path() []string {
+	if t.SessionJob == "" {
+		return []string{t.Namespace, t.Name + ".json"}
+	}
+
+	return []string{t.Namespace, t.Name, "session-jobs", t.SessionJob + ".json"}
+}
+
+// observedJob is the state of a deployment or session job a run is tracked from. The image and Flink version of a
+// session job are the ones of its session cluster.
+type observedJob struct {
+	jobRunTarget
+	JobStatus    FlinkJobStatus
+	Image        string
+	FlinkVersion string
+	UpgradeMode  string
+	Generation   int64
+}
+
+func observedDeploymentJob(deployment *FlinkDeployment) observedJob {
+	job := observedJob{
+		jobRunTarget: jobRunTarget{Namespace: deployment.Namespace, Name: deployment.Name},
+		JobStatus:    deployment.Status.JobStatus,
+		Image:        deployment.Spec.Image,
+		FlinkVersion: deployment.GetFlinkVersion(),
+		Generation:   deployment.Generation,
+	}
+
+	if deployment.Spec.Job != nil {
+		job.UpgradeMode = deployment.Spec.Job.UpgradeMode
+	}
+
+	return job
+}
+
+func observedSessionJob(deployment *FlinkDeployment, sessionJob *FlinkSessionJob) observedJob {
+	job := observedJob{
+		jobRunTarget: jobRunTarget{Namespace: deployment.Namespace, Name: deployment.Name, SessionJob: sessionJob.Name},
+		JobStatus:    sessionJob.Status.JobStatus,
+		Image:        deployment.Spec.Image,
+		FlinkVersion: deployment.GetFlinkVersion(),
+		Generation:   sessionJob.Generation,
+	}
+
+	if sessionJob.Spec.Job != nil {
+		job.UpgradeMode = sessionJob.Spec.Job.UpgradeMode
+	}
+
+	return job
+}
+
+func (r *JobRun) ended() bool {
+	return r.EndTime != nil
+}
+
+func (r *JobRun) end(state string, at time.Time) {
+	r.TerminalState = state
+	r.EndTime = &at
+}
+
+// jobRunStore keeps the runs of every deployment and session job in memory and persists them as one JSON file per
+// deployment below its directory, e.g. <directory>/<namespace>/<name>.json, the runs of a session job are stored in
+// <directory>/<namespace>/<name>/session-jobs/<sessionJob>.json.
+type jobRunStore struct {
+	lck       sync.Mutex
+	directory string
+	maxRuns   int
+	runs      map[string][]*JobRun
+}
+
+func newJobRunStore(directory string, maxRuns int) *jobRunStore {
+	return &jobRunStore{
+		directory: directory,
+		maxRuns:   maxRuns,
+		runs:      map[string][]*JobRun{},
+	}
+}
+
+// Observe updates the runs of a deployment or session job with its current state. A new job id ends the previous
+// run and starts a new run. It returns whether the runs changed.
+func (s *jobRunStore) Observe(job observedJob, now time.Time) (bool, error) {
+	jobStatus := job.JobStatus
+	if jobStatus.JobId == "" {
+		return false, nil
+	}
+
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	runs, err := s.load(job.jobRunTarget)
+	if err != nil {
+		return false, err
+	}
+
+	var current *JobRun
+	if len(runs) > 0 {
+		current = runs[len(runs)-1]
+	}
+
+	// the deployment watcher keeps deleted deployments, they must not bring their last run back to life
+	if current != nil && current.JobId == jobStatus.JobId && current.TerminalState == JobRunStateDeleted {
+		return false, nil
+	}
+
+	if current == nil || current.JobId != jobStatus.JobId {
+		// the terminal state of the previous run stays empty if the watcher missed it, its state is the last observed one
+		if current != nil && !current.ended() {
+			current.end("", now)
+		}
+
+		current = newJobRun(job, now)
+		runs = append(runs, current)
+	}
+
+	changed := current.observe(jobStatus, now)
+	if len(runs) > s.maxRuns {
+		runs = runs[len(runs)-s.maxRuns:]
+	}
+
+	return s.save(job.jobRunTarget, runs, changed)
+}
+
+// Delete ends the current run of a deleted deployment. The runs are kept, a deployment created again with the
+// same name continues its history.
+func (s *jobRunStore) Delete(target jobRunTarget, now time.Time) (bool, error) {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	runs, err := s.load(target)
+	if err != nil || len(runs) == 0 || runs[len(runs)-1].ended() {
+		return false, err
+	}
+
+	runs[len(runs)-1].end(JobRunStateDeleted, now)
+
+	return s.save(target, runs, true)
+}
+
+// SetRestored records the checkpoint the run of a job restored from.
+func (s *jobRunStore) SetRestored(target jobRunTarget, jobID string, restored *FlinkRestoredCheckpoint) error {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	runs, err := s.load(target)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		if run.JobId == jobID {
+			run.Restored = restored
+			run.RestoreChecked = true
+			_, err = s.save(target, runs, true)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Current returns a copy of the current run of a deployment or session job, if it has one.
+func (s *jobRunStore) Current(target jobRunTarget) (JobRun, bool, error) {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	runs, err := s.load(target)
+	if err != nil || len(runs) == 0 {
+		return JobRun{}, false, err
+	}
+
+	return *runs[len(runs)-1], true, nil
+}
+
+// History returns the runs of a deployment or session job, the latest first.
+func (s *jobRunStore) History(target jobRunTarget) (*JobRunHistory, error) {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	runs, err := s.load(target)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &JobRunHistory{
+		Namespace:  target.Namespace,
+		Name:       target.Name,
+		SessionJob: target.SessionJob,
+		Runs:       make([]JobRunSummary, 0, len(runs)),
+	}
+
+	for i, run := range runs {
+		summary := JobRunSummary{
+			JobRun:  *run,
+			Changes: []string{},
+		}
+
+		if i > 0 {
+			summary.Changes = runs[i-1].changesTo(run)
+		}
+
+		history.Runs = append(history.Runs, summary)
+	}
+
+	slices.Reverse(history.Runs)
+
+	return history, nil
+}
+
+func newJobRun(job observedJob, now time.Time) *JobRun {
+	return &JobRun{
+		JobId:        job.JobStatus.JobId,
+		StartTime:    parseJobStatusTime(job.JobStatus.StartTime, now),
+		Image:        job.Image,
+		FlinkVersion: job.FlinkVersion,
+		UpgradeMode:  job.UpgradeMode,
+		Generation:   job.Generation,
+		FirstSeen:    now,
+	}
+}
+
+func (r *JobRun) observe(jobStatus FlinkJobStatus, now time.Time) bool {
+	changed := r.State != jobStatus.State || r.LastSeen.IsZero()
+
+	r.State = jobStatus.State
+	r.LastSeen = now
+
+	// a job can still leave a terminal state, e.g. a failed job restarted by the operator with the same job id
+	switch {
+	case isTerminalJobState(jobStatus.State) && !r.ended():
+		r.end(jobStatus.State, parseJobStatusTime(jobStatus.UpdateTime, now))
+	case !isTerminalJobState(jobStatus.State) && r.ended():
+		r.TerminalState = ""
+		r.EndTime = nil
+	}
+
+	return changed
+}
+
+func (r *JobRun) changesTo(next *JobRun) []string {
+	changes := make([]string, 0)
+
+	if r.Image != next.Image {
+		changes = append(changes, "image")
+	}
+
+	if r.FlinkVersion != next.FlinkVersion {
+		changes = append(changes, "flinkVersion")
+	}
+
+	if r.UpgradeMode != next.UpgradeMode {
+		changes = append(changes, "upgradeMode")
+	}
+
+	if r.Generation != next.Generation {
+		changes = append(changes, "spec")
+	}
+
+	return changes
+}
+
+func isTerminalJobState(state string) bool {
+	switch state {
+	case "FINISHED", "FAILED", "CANCELED", "SUSPENDED":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseJobStatusTime parses the times of the job status, which the operator reports as milliseconds since the epoch.
+func parseJobStatusTime(value string, fallback time.Time) time.Time {
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || millis <= 0 {
+		return fallback
+	}
+
+	return time.UnixMilli(millis).UTC()
+}
+
+func (s *jobRunStore) load(target jobRunTarget) ([]*JobRun, error) {
+	key := target.String()
+	if runs, ok := s.runs[key]; ok {
+		return runs, nil
+	}
+
+	path, err := storagePath(s.directory, target.path()...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read job runs %s: %w", path, err)
+	}
+
+	runs := make([]*JobRun, 0)
+	if err = json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("could not decode job runs %s: %w", path, err)
+	}
+
+	s.runs[key] = runs
+
+	return runs, nil
+}
+
+// save keeps the runs in memory and writes them to the file of the deployment or session job if they changed.
+// Unchanged runs are only kept in memory, the times they were last seen don't need to survive a restart.
+func (s *jobRunStore) save(target jobRunTarget, runs []*JobRun, changed bool) (bool, error) {
+	s.runs[target.String()] = runs
+
+	if !changed {
+		return false, nil
+	}
+
+	path, err := storagePath(s.directory, target.path()...)
+	if err != nil {
+		return false, err
+	}
+
+	err = writeFileAtomic(path, func(file *os.File) error {
+		return json.NewEncoder(file).Encode(runs)
+	})
+
+	return true, err
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestJobRunStore(t *testing.T) {
+	directory := t.TempDir()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	deployment := &FlinkDeployment{
+		Spec: FlinkDeploymentSpec{
+			Image: "registry/orders:1.0.0",
+			Job:   &FlinkDeploymentJob{UpgradeMode: "last-state"},
+		},
+	}
+	deployment.Namespace = "flink"
+	deployment.Name = "orders"
+	deployment.Generation = 3
+	deployment.Status.JobStatus = FlinkJobStatus{JobId: "job-a", State: "RUNNING", StartTime: "1714564800000"}
+
+	target := jobRunTarget{Namespace: "flink", Name: "orders"}
+
+	observe := func(store *jobRunStore, at time.Time) {
+		if _, err := store.Observe(observedDeploymentJob(deployment), at); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	store := newJobRunStore(directory, 10)
+	observe(store, now)
+
+	restored := &FlinkRestoredCheckpoint{Id: 42, IsSavepoint: true, ExternalPath: "s3://bucket/savepoint-42"}
+	if err := store.SetRestored(target, "job-a", restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// an upgrade to a new image starts a new job, the history has to survive a restart in between
+	deployment.Spec.Image = "registry/orders:1.1.0"
+	deployment.Generation = 4
+	deployment.Status.JobStatus = FlinkJobStatus{JobId: "job-b", State: "RUNNING"}
+
+	store = newJobRunStore(directory, 10)
+	observe(store, now.Add(time.Hour))
+
+	deployment.Status.JobStatus = FlinkJobStatus{JobId: "job-b", State: "FAILED", UpdateTime: "1714572000000"}
+	observe(store, now.Add(2*time.Hour))
+
+	history, err := store.History(target)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(history.Runs) != 2 {
+		t.Fatalf("expected 2 runs, got %+v", history.Runs)
+	}
+
+	latest, previous := history.Runs[0], history.Runs[1]
+	if latest.JobId != "job-b" || latest.TerminalState != "FAILED" || latest.EndTime == nil || !latest.EndTime.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("unexpected latest run: %+v", latest)
+	}
+
+	if !slices.Equal(latest.Changes, []string{"image", "spec"}) {
+		t.Errorf("unexpected changes of the latest run: %v", latest.Changes)
+	}
+
+	if previous.JobId != "job-a" || previous.State != "RUNNING" || previous.TerminalState != "" || previous.EndTime == nil || !previous.StartTime.Equal(now) || previous.Restored == nil || previous.Restored.Id != 42 {
+		t.Errorf("unexpected previous run: %+v", previous)
+	}
+}
+
+func TestJobRunStoreSessionJob(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	deployment := &FlinkDeployment{Spec: FlinkDeploymentSpec{Image: "registry/session:1.0.0"}}
+	deployment.Namespace = "flink"
+	deployment.Name = "session"
+
+	sessionJob := &FlinkSessionJob{Spec: FlinkSessionJobSpec{DeploymentName: "session", Job: &FlinkDeploymentJob{UpgradeMode: "savepoint"}}}
+	sessionJob.Namespace = "flink"
+	sessionJob.Name = "enrichment"
+	sessionJob.Generation = 2
+	sessionJob.Status.JobStatus = FlinkJobStatus{JobId: "job-a", State: "RUNNING"}
+
+	store := newJobRunStore(t.TempDir(), 10)
+
+	// the session cluster has no job of its own, only its session job has runs
+	for _, job := range []observedJob{observedDeploymentJob(deployment), observedSessionJob(deployment, sessionJob)} {
+		if _, err := store.Observe(job, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cluster, err := store.History(jobRunTarget{Namespace: "flink", Name: "session"})
+	if err != nil || len(cluster.Runs) != 0 {
+		t.Fatalf("expected no runs of the session cluster, got %+v: %v", cluster, err)
+	}
+
+	history, err := store.History(jobRunTarget{Namespace: "flink", Name: "session", SessionJob: "enrichment"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if history.SessionJob != "enrichment" || len(history.Runs) != 1 {
+		t.Fatalf("unexpected history of the session job: %+v", history)
+	}
+
+	run := history.Runs[0]
+	if run.JobId != "job-a" || run.Image != "registry/session:1.0.0" || run.UpgradeMode != "savepoint" || run.Generation != 2 {
+		t.Errorf("unexpected run of the session job: %+v", run)
+	}
+}
@@ -3,10 +3,14 @@ package internal
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/justtrackio/gosoline/pkg/appctx"
 	"github.com/justtrackio/gosoline/pkg/cfg"
 	"github.com/justtrackio/gosoline/pkg/log"
+	corev1 "k8s.io/api/core/v1"
 	eventsv1 "k8s.io/api/events/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -72,6 +76,7 @@ func newK8sServiceFromConfig(clientConfig *rest.Config, logger log.Logger) (*K8s
 
 	return &K8sService{
 		logger:        logger.WithChannel("k8s"),
+		clientConfig:  clientConfig,
 		dynamicClient: dynamicClient,
 		client:        client,
 	}, nil
@@ -79,10 +84,34 @@ func newK8sServiceFromConfig(clientConfig *rest.Config, logger log.Logger) (*K8s
 
 type K8sService struct {
 	logger        log.Logger
+	clientConfig  *rest.Config
 	dynamicClient dynamic.Interface
 	client        *kubernetes.Clientset
 }
 
+// ApiServerTransport returns the URL of the API server together with a transport which authenticates requests
+// against it, e.g. to reach services through the service proxy of the API server.
+func (s *K8sService) ApiServerTransport() (*url.URL, http.RoundTripper, error) {
+	host := s.clientConfig.Host
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	apiServerURL, err := url.Parse(host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse api server host %s: %w", s.clientConfig.Host, err)
+	}
+
+	apiServerURL.Path = strings.TrimSuffix(apiServerURL.Path, "/")
+
+	transport, err := rest.TransportFor(s.clientConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create api server transport: %w", err)
+	}
+
+	return apiServerURL, transport, nil
+}
+
 func (s *K8sService) WatchDeployments(ctx context.Context) (watch.Interface, error) {
 	gvr := schema.GroupVersionResource{Group: "flink.apache.org", Version: "v1beta1", Resource: "flinkdeployments"}
 	deployments := s.dynamicClient.Resource(gvr)
@@ -90,6 +119,13 @@ func (s *K8sService) WatchDeployments(ctx context.Context) (watch.Interface, err
 	return deployments.Watch(ctx, metav1.ListOptions{})
 }
 
+func (s *K8sService) WatchSessionJobs(ctx context.Context) (watch.Interface, error) {
+	gvr := schema.GroupVersionResource{Group: "flink.apache.org", Version: "v1beta1", Resource: "flinksessionjobs"}
+	sessionJobs := s.dynamicClient.Resource(gvr)
+
+	return sessionJobs.Watch(ctx, metav1.ListOptions{})
+}
+
 func (s *K8sService) GetEvents(ctx context.Context, namespace string, name string) (*eventsv1.EventList, error) {
 	fieldSelector := fmt.Sprintf("regarding.name=%s", name)
 	events, err := s.client.EventsV1().Events(namespace).List(ctx, metav1.ListOptions{
@@ -101,3 +137,17 @@ func (s *K8sService) GetEvents(ctx context.Context, namespace string, name strin
 
 	return events, nil
 }
+
+// ListHighAvailabilityConfigMaps lists the ConfigMaps the Kubernetes HA services of a Flink cluster keep
+// their leader information, checkpoint pointers and job graphs in.
+func (s *K8sService) ListHighAvailabilityConfigMaps(ctx context.Context, namespace string, clusterId string) (*corev1.ConfigMapList, error) {
+	labelSelector := fmt.Sprintf("app=%s,configmap-type=high-availability", clusterId)
+	configMaps, err := s.client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list high availability config maps for %s/%s: %w", namespace, clusterId, err)
+	}
+
+	return configMaps, nil
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justtrackio/gosoline/pkg/cfg"
+)
+
+var (
+	// errInvalidStoragePath is returned for a namespace, name or job id which can't be used as part of a file path.
+	errInvalidStoragePath = errors.New("invalid storage path")
+	// ErrDataDirectoryMissing is returned by the features which need a data directory if none is configured.
+	ErrDataDirectoryMissing = errors.New("no data directory configured, data.directory has to point to a persistent volume")
+)
+
+// DataSettings configures the directory the background modules keep their state in. It has to be on a persistent
+// volume, the file system of the container is lost on every restart.
+type DataSettings struct {
+	Directory string `cfg:"directory"`
+}
+
+// dataDirectory returns the directory of a module, which is either configured for the module itself or the
+// subdirectory of data.directory. It returns ErrDataDirectoryMissing if neither is configured.
+func dataDirectory(config cfg.Config, directory string, subdirectory string) (string, error) {
+	if directory != "" {
+		return directory, nil
+	}
+
+	settings := &DataSettings{}
+	if err := config.UnmarshalKey("data", settings); err != nil {
+		return "", fmt.Errorf("could not unmarshal data settings: %w", err)
+	}
+
+	if settings.Directory == "" {
+		return "", ErrDataDirectoryMissing
+	}
+
+	return filepath.Join(settings.Directory, subdirectory), nil
+}
+
+// storagePath joins the components below the directory of a local store. The components can come from requests,
+// so they are checked to not escape the directory.
+func storagePath(directory string, components ...string) (string, error) {
+	for _, component := range components {
+		if component == "" || component == "." || component == ".." || strings.ContainsAny(component, `/\`) {
+			return "", fmt.Errorf("%w: %q", errInvalidStoragePath, component)
+		}
+	}
+
+	return filepath.Join(append([]string{directory}, components...)...), nil
+}
+
+// writeFileAtomic writes the file by replacing it with a temporary file, so a crash while writing doesn't lose its content.
+func writeFileAtomic(path string, write func(file *os.File) error) (err error) {
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("could not create directory of %s: %w", path, err)
+	}
+
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("could not create file %s: %w", tmpPath, err)
+	}
+
+	if err = write(file); err == nil {
+		err = file.Sync()
+	}
+
+	if err != nil {
+		err = fmt.Errorf("could not write file %s: %w", tmpPath, err)
+	}
+
+	if cerr := file.Close(); cerr != nil && err == nil {
+		err = fmt.Errorf("could not close file %s: %w", tmpPath, cerr)
+	}
+
+	if err != nil {
+		return errors.Join(err, os.Remove(tmpPath))
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("could not replace file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readJSONFile decodes the file into the target. It returns false without an error if the file doesn't exist yet.
+func readJSONFile(path string, target any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	if err = json.Unmarshal(data, target); err != nil {
+		return false, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// writeJSONFile replaces the file with the JSON encoding of the value.
+func writeJSONFile(path string, value any) error {
+	return writeFileAtomic(path, func(file *os.File) error {
+		return json.NewEncoder(file).Encode(value)
+	})
+}
@@ -0,0 +1,74 @@
+package internal
+
+import "time"
+
+// MetricPoint is a single sample of a metric. Plain metrics have a value, aggregated metrics the
+// aggregations over all subtasks or task managers instead.
+type MetricPoint struct {
+	Time  time.Time `json:"time"`
+	Value *float64  `json:"value,omitempty"`
+	Min   *float64  `json:"min,omitempty"`
+	Max   *float64  `json:"max,omitempty"`
+	Avg   *float64  `json:"avg,omitempty"`
+	Sum   *float64  `json:"sum,omitempty"`
+}
+
+// MetricSeries are the samples of a metric of a deployment, oldest first.
+type MetricSeries struct {
+	Scope      string        `json:"scope"`
+	VertexId   string        `json:"vertexId,omitempty"`
+	VertexName string        `json:"vertexName,omitempty"`
+	Metric     string        `json:"metric"`
+	Points     []MetricPoint `json:"points"`
+}
+
+// ringBuffer keeps the last items added to it, overwriting the oldest one once it is full.
+type ringBuffer[T any] struct {
+	items []T
+	next  int
+	full  bool
+}
+
+func newRingBuffer[T any](capacity int) *ringBuffer[T] {
+	return &ringBuffer[T]{
+		items: make([]T, max(capacity, 1)),
+	}
+}
+
+func (b *ringBuffer[T]) Add(item T) {
+	b.items[b.next] = item
+	b.next = (b.next + 1) % len(b.items)
+	b.full = b.full || b.next == 0
+}
+
+// Items returns a copy of the items in the order they were added.
+func (b *ringBuffer[T]) Items() []T {
+	if !b.full {
+		items := make([]T, b.next)
+		copy(items, b.items[:b.next])
+
+		return items
+	}
+
+	items := make([]T, 0, len(b.items))
+	items = append(items, b.items[b.next:]...)
+	items = append(items, b.items[:b.next]...)
+
+	return items
+}
+
+func toMetricPoint(now time.Time, metric FlinkMetric) (MetricPoint, bool) {
+	point := MetricPoint{
+		Time: now,
+		Min:  metric.Min,
+		Max:  metric.Max,
+		Avg:  metric.Avg,
+		Sum:  metric.Sum,
+	}
+
+	if value, ok := metric.FloatValue(); ok {
+		point.Value = &value
+	}
+
+	return point, point.Value != nil || point.Min != nil || point.Max != nil || point.Avg != nil || point.Sum != nil
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRingBuffer(t *testing.T) {
+	buffer := newRingBuffer[int](3)
+	if items := buffer.Items(); len(items) != 0 {
+		t.Fatalf("expected an empty buffer, got %v", items)
+	}
+
+	buffer.Add(1)
+	buffer.Add(2)
+	if items := buffer.Items(); !slices.Equal(items, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v", items)
+	}
+
+	buffer.Add(3)
+	if items := buffer.Items(); !slices.Equal(items, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", items)
+	}
+
+	// the oldest items are overwritten once the buffer is full
+	buffer.Add(4)
+	buffer.Add(5)
+
+	items := buffer.Items()
+	if !slices.Equal(items, []int{3, 4, 5}) {
+		t.Fatalf("expected [3 4 5], got %v", items)
+	}
+
+	// the items are a copy, changing them doesn't change the buffer
+	items[0] = 42
+	if items = buffer.Items(); items[0] != 3 {
+		t.Errorf("expected the buffer to be unchanged, got %v", items)
+	}
+
+	// a buffer without capacity keeps the last item
+	single := newRingBuffer[string](0)
+	single.Add("a")
+	single.Add("b")
+
+	if items := single.Items(); !slices.Equal(items, []string{"b"}) {
+		t.Errorf("expected [b], got %v", items)
+	}
+}
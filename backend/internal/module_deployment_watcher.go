@@ -3,6 +3,8 @@ package internal
 import (
 	"context"
 	"fmt"
+	"maps"
+	"sort"
 	"sync"
 
 	"github.com/justtrackio/gosoline/pkg/appctx"
@@ -11,6 +13,8 @@ import (
 	"github.com/justtrackio/gosoline/pkg/kernel"
 	"github.com/justtrackio/gosoline/pkg/log"
 	"github.com/justtrackio/gosoline/pkg/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 type deploymentWatcherModuleCtxKey struct{}
@@ -22,7 +26,10 @@ type DeploymentWatcherModule struct {
 	lck         sync.Mutex
 	logger      log.Logger
 	watcher     *DeploymentWatcher
+	resolver    *FlinkEndpointResolver
+	client      *FlinkClient
 	deployments map[string]map[string]*FlinkDeployment
+	sessionJobs map[string]map[string]*FlinkSessionJob
 	channels    map[string]chan DeploymentEvent
 }
 
@@ -30,15 +37,28 @@ func ProvideDeploymentWatcherModule(ctx context.Context, config cfg.Config, logg
 	return appctx.Provide(ctx, deploymentWatcherModuleCtxKey{}, func() (*DeploymentWatcherModule, error) {
 		var err error
 		var watcher *DeploymentWatcher
+		var resolver *FlinkEndpointResolver
+		var client *FlinkClient
 
 		if watcher, err = NewDeploymentWatcher(ctx, config, logger); err != nil {
 			return nil, fmt.Errorf("failed to initialize k8s service: %w", err)
 		}
 
+		if resolver, err = ProvideFlinkEndpointResolver(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("failed to initialize flink endpoint resolver: %w", err)
+		}
+
+		if client, err = ProvideFlinkClient(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not create flink client: %w", err)
+		}
+
 		return &DeploymentWatcherModule{
 			logger:      logger.WithChannel("k8s-watcher"),
 			watcher:     watcher,
+			resolver:    resolver,
+			client:      client,
 			deployments: make(map[string]map[string]*FlinkDeployment),
+			sessionJobs: make(map[string]map[string]*FlinkSessionJob),
 			channels:    map[string]chan DeploymentEvent{},
 		}, nil
 	})
@@ -81,19 +101,140 @@ func (m *DeploymentWatcherModule) GetDeployment(namespace, name string) (*FlinkD
 	return deployment, exists
 }
 
+// GetDeployments returns all deployments currently known to the watcher.
+func (m *DeploymentWatcherModule) GetDeployments() []*FlinkDeployment {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	var deployments []*FlinkDeployment
+	for _, nsDeployments := range m.deployments {
+		for _, deployment := range nsDeployments {
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	return deployments
+}
+
 // GetFlinkEndpoint resolves the Flink REST API URL and job ID for a deployment.
-// Returns an error if the deployment is not found or has no ingress configured.
+// Returns an error if the deployment is not found or none of the endpoint strategies of its namespace applies.
+// The Flink version of the deployment is passed on to the client, which decodes the responses accordingly.
 func (m *DeploymentWatcherModule) GetFlinkEndpoint(namespace, name string) (flinkURL string, jobID string, err error) {
 	deployment, exists := m.GetDeployment(namespace, name)
 	if !exists {
-		return "", "", fmt.Errorf("deployment %s/%s not found", namespace, name)
+		return "", "", fmt.Errorf("%w: %s/%s", ErrDeploymentNotFound, namespace, name)
 	}
 
-	if deployment.Spec.Ingress == nil {
-		return "", "", fmt.Errorf("deployment %s/%s has no ingress configured", namespace, name)
+	if flinkURL, err = m.resolver.Resolve(deployment); err != nil {
+		return "", "", err
 	}
 
-	return "https://" + deployment.Spec.Ingress.Template, deployment.Status.JobStatus.JobId, nil
+	m.client.SetClusterVersion(flinkURL, deployment.GetFlinkVersion())
+
+	return flinkURL, deployment.Status.JobStatus.JobId, nil
+}
+
+// GetSessionJob retrieves a single session job from the in-memory cache by namespace and name
+func (m *DeploymentWatcherModule) GetSessionJob(namespace, name string) (*FlinkSessionJob, bool) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	sessionJob, exists := m.sessionJobs[namespace][name]
+
+	return sessionJob, exists
+}
+
+// GetSessionJobs returns the session jobs running on the session cluster of a deployment, sorted by name.
+func (m *DeploymentWatcherModule) GetSessionJobs(namespace, deploymentName string) []*FlinkSessionJob {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	sessionJobs := make([]*FlinkSessionJob, 0)
+	for _, sessionJob := range m.sessionJobs[namespace] {
+		if sessionJob.Spec.DeploymentName == deploymentName {
+			sessionJobs = append(sessionJobs, sessionJob)
+		}
+	}
+
+	sort.Slice(sessionJobs, func(i, j int) bool {
+		return sessionJobs[i].Name < sessionJobs[j].Name
+	})
+
+	return sessionJobs
+}
+
+// GetJobEndpoint resolves the Flink REST API URL and job ID for a deployment or, if a session job is given, for a
+// session job running on the session cluster of the deployment. Session jobs use the REST API of their session
+// cluster together with their own job ID.
+func (m *DeploymentWatcherModule) GetJobEndpoint(namespace, name, sessionJobName string) (flinkURL string, jobID string, err error) {
+	if sessionJobName == "" {
+		return m.GetFlinkEndpoint(namespace, name)
+	}
+
+	sessionJob, err := m.getDeploymentSessionJob(namespace, name, sessionJobName)
+	if err != nil {
+		return "", "", err
+	}
+
+	if flinkURL, _, err = m.GetFlinkEndpoint(namespace, name); err != nil {
+		return "", "", err
+	}
+
+	return flinkURL, sessionJob.Status.JobStatus.JobId, nil
+}
+
+// GetJobConfiguration returns the flinkConfiguration and job ID of a deployment or, if a session job is given, the
+// flinkConfiguration of the session cluster overridden by the one of the session job together with its job ID.
+func (m *DeploymentWatcherModule) GetJobConfiguration(namespace, name, sessionJobName string) (flinkConfig map[string]any, jobID string, err error) {
+	deployment, exists := m.GetDeployment(namespace, name)
+	if !exists {
+		return nil, "", fmt.Errorf("%w: %s/%s", ErrDeploymentNotFound, namespace, name)
+	}
+
+	if sessionJobName == "" {
+		return deployment.Spec.FlinkConfiguration, deployment.Status.JobStatus.JobId, nil
+	}
+
+	sessionJob, err := m.getDeploymentSessionJob(namespace, name, sessionJobName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	flinkConfig = make(map[string]any, len(deployment.Spec.FlinkConfiguration)+len(sessionJob.Spec.FlinkConfiguration))
+	maps.Copy(flinkConfig, deployment.Spec.FlinkConfiguration)
+	maps.Copy(flinkConfig, sessionJob.Spec.FlinkConfiguration)
+
+	return flinkConfig, sessionJob.Status.JobStatus.JobId, nil
+}
+
+// GetJobSpec returns the job of the spec of a deployment or, if a session job is given, of the session job. It is nil
+// for session clusters without a job.
+func (m *DeploymentWatcherModule) GetJobSpec(namespace, name, sessionJobName string) (*FlinkDeploymentJob, error) {
+	if sessionJobName != "" {
+		sessionJob, err := m.getDeploymentSessionJob(namespace, name, sessionJobName)
+		if err != nil {
+			return nil, err
+		}
+
+		return sessionJob.Spec.Job, nil
+	}
+
+	deployment, exists := m.GetDeployment(namespace, name)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s/%s", ErrDeploymentNotFound, namespace, name)
+	}
+
+	return deployment.Spec.Job, nil
+}
+
+// getDeploymentSessionJob returns the session job if it belongs to the session cluster of the deployment.
+func (m *DeploymentWatcherModule) getDeploymentSessionJob(namespace, name, sessionJobName string) (*FlinkSessionJob, error) {
+	sessionJob, exists := m.GetSessionJob(namespace, sessionJobName)
+	if !exists || sessionJob.Spec.DeploymentName != name {
+		return nil, fmt.Errorf("%w: %s/%s/%s", ErrSessionJobNotFound, namespace, name, sessionJobName)
+	}
+
+	return sessionJob, nil
 }
 
 func (m *DeploymentWatcherModule) Run(ctx context.Context) error {
@@ -109,6 +250,22 @@ func (m *DeploymentWatcherModule) Run(ctx context.Context) error {
 		return m.streamEvents(cfnCtx)
 	})
 
+	// clusters without session job support lack the CRD or the permissions for it, which must not stop the deployments from being watched
+	cfn.GoWithContext(cfnCtx, func(cfnCtx context.Context) error {
+		err := m.watcher.WatchSessionJobs(cfnCtx)
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			m.logger.Warn(cfnCtx, "not watching session jobs: %v", err)
+
+			return nil
+		}
+
+		return err
+	})
+
+	cfn.GoWithContext(cfnCtx, func(cfnCtx context.Context) error {
+		return m.streamSessionJobEvents(cfnCtx)
+	})
+
 	return cfn.Wait()
 }
 
@@ -128,6 +285,39 @@ func (m *DeploymentWatcherModule) streamEvents(ctx context.Context) error {
 	}
 }
 
+func (m *DeploymentWatcherModule) streamSessionJobEvents(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-m.watcher.SessionJobResultChan():
+			if !ok {
+				return nil
+			}
+			m.applySessionJobEvent(ctx, event)
+		}
+	}
+}
+
+func (m *DeploymentWatcherModule) applySessionJobEvent(ctx context.Context, event SessionJobEvent) {
+	sessionJob := event.SessionJob
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.logger.Info(ctx, "got session job event from k8s watcher for %s", sessionJob.Name)
+
+	if event.Type == watch.Deleted {
+		delete(m.sessionJobs[sessionJob.Namespace], sessionJob.Name)
+
+		return
+	}
+
+	if _, ok := m.sessionJobs[sessionJob.Namespace]; !ok {
+		m.sessionJobs[sessionJob.Namespace] = make(map[string]*FlinkSessionJob)
+	}
+	m.sessionJobs[sessionJob.Namespace][sessionJob.Name] = sessionJob
+}
+
 func (m *DeploymentWatcherModule) closeChannels() {
 	m.lck.Lock()
 	defer m.lck.Unlock()
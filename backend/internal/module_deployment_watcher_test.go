@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetJobConfigurationOfSessionJob(t *testing.T) {
+	session := &FlinkDeployment{
+		Spec: FlinkDeploymentSpec{FlinkConfiguration: map[string]any{
+			"execution.checkpointing.dir":      "s3://bucket/session/checkpoints",
+			"execution.checkpointing.interval": "1m",
+		}},
+	}
+	session.Namespace, session.Name = "flink", "session"
+
+	sessionJob := &FlinkSessionJob{
+		Spec: FlinkSessionJobSpec{
+			DeploymentName:     "session",
+			FlinkConfiguration: map[string]any{"execution.checkpointing.interval": "10s"},
+		},
+		Status: FlinkSessionJobStatus{JobStatus: FlinkJobStatus{JobId: "job-a"}},
+	}
+	sessionJob.Namespace, sessionJob.Name = "flink", "orders"
+
+	watcher := &DeploymentWatcherModule{
+		deployments: map[string]map[string]*FlinkDeployment{"flink": {"session": session}},
+		sessionJobs: map[string]map[string]*FlinkSessionJob{"flink": {"orders": sessionJob}},
+	}
+
+	flinkConfig, jobID, err := watcher.GetJobConfiguration("flink", "session", "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if jobID != "job-a" || flinkConfig["execution.checkpointing.interval"] != "10s" || flinkConfig["execution.checkpointing.dir"] != "s3://bucket/session/checkpoints" {
+		t.Errorf("unexpected configuration %v of job %s", flinkConfig, jobID)
+	}
+
+	if session.Spec.FlinkConfiguration["execution.checkpointing.interval"] != "1m" {
+		t.Errorf("the configuration of the session cluster must not be modified")
+	}
+
+	other := &FlinkDeployment{}
+	other.Namespace, other.Name = "flink", "other"
+	watcher.deployments["flink"]["other"] = other
+
+	if _, _, err = watcher.GetJobConfiguration("flink", "other", "orders"); !errors.Is(err, ErrSessionJobNotFound) {
+		t.Errorf("expected the session job to be unknown on another deployment, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/justtrackio/gosoline/pkg/appctx"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/kernel"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+// ExceptionCollectorSettings configures the exception collector. The exceptions are stored below the directory,
+// which defaults to the exceptions subdirectory of data.directory and has to be on a persistent volume.
+type ExceptionCollectorSettings struct {
+	Enabled       bool          `cfg:"enabled" default:"true"`
+	Interval      time.Duration `cfg:"interval" default:"1m"`
+	Directory     string        `cfg:"directory"`
+	MaxExceptions int           `cfg:"max_exceptions" default:"100"`
+	Retention     time.Duration `cfg:"retention" default:"720h"`
+	PruneInterval time.Duration `cfg:"prune_interval" default:"1h"`
+}
+
+type exceptionCollectorModuleCtxKey struct{}
+
+// ExceptionCollectorModule periodically collects the exception history of all running deployments and session jobs
+// and persists it, so exceptions are still available after Flink dropped them from its history or the job was restarted.
+type ExceptionCollectorModule struct {
+	kernel.BackgroundModule
+	kernel.ServiceStage
+
+	logger   log.Logger
+	settings *ExceptionCollectorSettings
+	client   *FlinkClient
+	watcher  *DeploymentWatcherModule
+	store    *exceptionStore
+}
+
+func ProvideExceptionCollectorModule(ctx context.Context, config cfg.Config, logger log.Logger) (*ExceptionCollectorModule, error) {
+	return appctx.Provide(ctx, exceptionCollectorModuleCtxKey{}, func() (*ExceptionCollectorModule, error) {
+		var err error
+		var client *FlinkClient
+		var watcher *DeploymentWatcherModule
+
+		settings := &ExceptionCollectorSettings{}
+		if err = config.UnmarshalKey("exceptions.collector", settings); err != nil {
+			return nil, fmt.Errorf("could not unmarshal exception collector settings: %w", err)
+		}
+
+		if client, err = ProvideFlinkClient(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not create flink client: %w", err)
+		}
+
+		if watcher, err = ProvideDeploymentWatcherModule(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not initialize deployment watcher: %w", err)
+		}
+
+		module := &ExceptionCollectorModule{
+			logger:   logger.WithChannel("exception-collector"),
+			settings: settings,
+			client:   client,
+			watcher:  watcher,
+		}
+
+		// without a data directory the collector stays off and the exception history answers with an error
+		directory, err := dataDirectory(config, settings.Directory, "exceptions")
+		if err == nil {
+			module.store = newExceptionStore(directory)
+		} else if !errors.Is(err, ErrDataDirectoryMissing) {
+			return nil, err
+		}
+
+		return module, nil
+	})
+}
+
+func (m *ExceptionCollectorModule) Run(ctx context.Context) error {
+	if !m.settings.Enabled {
+		m.logger.Info(ctx, "exception collection is disabled")
+
+		return nil
+	}
+
+	if m.store == nil {
+		m.logger.Warn(ctx, "exception collection is disabled: %s", ErrDataDirectoryMissing)
+
+		return nil
+	}
+
+	m.logger.Info(ctx, "starting exception collection every %s into %s", m.settings.Interval, m.store.directory)
+
+	ticker := time.NewTicker(m.settings.Interval)
+	defer ticker.Stop()
+
+	pruneTicker := time.NewTicker(m.settings.PruneInterval)
+	defer pruneTicker.Stop()
+
+	m.prune(ctx)
+	m.collectAll(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.collectAll(ctx)
+		case <-pruneTicker.C:
+			m.prune(ctx)
+		}
+	}
+}
+
+// Query returns the collected exceptions of a deployment or, if a session job is given, of a session job in the
+// time range, the latest first. Without a job id the exceptions of all jobs the deployment ran are returned.
+func (m *ExceptionCollectorModule) Query(namespace string, name string, sessionJob string, jobID string, from time.Time, to time.Time) ([]StoredException, error) {
+	if m.store == nil {
+		return nil, ErrDataDirectoryMissing
+	}
+
+	return m.store.Query(namespace, name, sessionJob, jobID, from, to)
+}
+
+// Collect fetches the exception history of a deployment or session job and stores the exceptions which are not
+// stored yet.
+func (m *ExceptionCollectorModule) Collect(ctx context.Context, namespace string, name string, sessionJob string) (int, error) {
+	if m.store == nil {
+		return 0, ErrDataDirectoryMissing
+	}
+
+	flinkURL, jobID, err := m.watcher.GetJobEndpoint(namespace, name, sessionJob)
+	if err != nil {
+		return 0, err
+	}
+
+	exceptions, err := m.client.GetExceptions(ctx, flinkURL, jobID, m.settings.MaxExceptions)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch exceptions from Flink: %w", err)
+	}
+
+	// exceptions which would be pruned right away are skipped, otherwise they are collected again after every prune
+	cutoff := time.Now().Add(-m.settings.Retention).UnixMilli()
+	entries := make([]FlinkExceptionEntry, 0, len(exceptions.ExceptionHistory.Entries))
+
+	for _, entry := range exceptions.ExceptionHistory.Entries {
+		if entry.Timestamp >= cutoff {
+			entries = append(entries, entry)
+		}
+	}
+
+	return m.store.Add(namespace, name, sessionJob, jobID, entries, time.Now().UTC())
+}
+
+// collectAll collects the exceptions of all running jobs. A session cluster has no job of its own, its jobs are the
+// session jobs deployed to it.
+func (m *ExceptionCollectorModule) collectAll(ctx context.Context) {
+	for _, deployment := range m.watcher.GetDeployments() {
+		if deployment.GetStatusGroup() == "running" && deployment.Status.JobStatus.JobId != "" {
+			m.collect(ctx, deployment.Namespace, deployment.Name, "")
+		}
+
+		for _, sessionJob := range m.watcher.GetSessionJobs(deployment.Namespace, deployment.Name) {
+			if sessionJob.GetStatusGroup() == "running" && sessionJob.Status.JobStatus.JobId != "" {
+				m.collect(ctx, deployment.Namespace, deployment.Name, sessionJob.Name)
+			}
+		}
+	}
+}
+
+func (m *ExceptionCollectorModule) collect(ctx context.Context, namespace string, name string, sessionJob string) {
+	target := namespace + "/" + name
+	if sessionJob != "" {
+		target += "/" + sessionJob
+	}
+
+	added, err := m.Collect(ctx, namespace, name, sessionJob)
+	if err != nil {
+		m.logger.Warn(ctx, "failed to collect exceptions of %s: %v", target, err)
+
+		return
+	}
+
+	if added > 0 {
+		m.logger.Info(ctx, "collected %d new exceptions of %s", added, target)
+	}
+}
+
+func (m *ExceptionCollectorModule) prune(ctx context.Context) {
+	pruned, err := m.store.Prune(time.Now().Add(-m.settings.Retention))
+	if err != nil {
+		m.logger.Warn(ctx, "failed to prune collected exceptions: %v", err)
+
+		return
+	}
+
+	if pruned > 0 {
+		m.logger.Info(ctx, "pruned %d collected exceptions older than %s", pruned, m.settings.Retention)
+	}
+}
@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/justtrackio/gosoline/pkg/appctx"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/kernel"
+	"github.com/justtrackio/gosoline/pkg/log"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// JobRunHistorySettings configures the job run history. The runs are stored below the directory, which defaults to
+// the job_runs subdirectory of data.directory and has to be on a persistent volume.
+type JobRunHistorySettings struct {
+	Enabled   bool          `cfg:"enabled" default:"true"`
+	Directory string        `cfg:"directory"`
+	MaxRuns   int           `cfg:"max_runs" default:"100"`
+	Interval  time.Duration `cfg:"interval" default:"30s"`
+}
+
+type jobRunHistoryModuleCtxKey struct{}
+
+// JobRunHistoryModule tracks every job id a deployment or session job had as the deployment watcher observes it. The
+// job id changes on every upgrade and last-state restart, so the runs tell which deploy changed the behavior of a job.
+type JobRunHistoryModule struct {
+	kernel.BackgroundModule
+	kernel.ServiceStage
+
+	logger   log.Logger
+	settings *JobRunHistorySettings
+	client   *FlinkClient
+	watcher  *DeploymentWatcherModule
+	store    *jobRunStore
+}
+
+func ProvideJobRunHistoryModule(ctx context.Context, config cfg.Config, logger log.Logger) (*JobRunHistoryModule, error) {
+	return appctx.Provide(ctx, jobRunHistoryModuleCtxKey{}, func() (*JobRunHistoryModule, error) {
+		var err error
+		var client *FlinkClient
+		var watcher *DeploymentWatcherModule
+
+		settings := &JobRunHistorySettings{}
+		if err = config.UnmarshalKey("job_runs", settings); err != nil {
+			return nil, fmt.Errorf("could not unmarshal job run settings: %w", err)
+		}
+
+		if client, err = ProvideFlinkClient(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not create flink client: %w", err)
+		}
+
+		if watcher, err = ProvideDeploymentWatcherModule(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not initialize deployment watcher: %w", err)
+		}
+
+		module := &JobRunHistoryModule{
+			logger:   logger.WithChannel("job-run-history"),
+			settings: settings,
+			client:   client,
+			watcher:  watcher,
+		}
+
+		// without a data directory the history stays off and answers with an error
+		directory, err := dataDirectory(config, settings.Directory, "job_runs")
+		if err == nil {
+			module.store = newJobRunStore(directory, settings.MaxRuns)
+		} else if !errors.Is(err, ErrDataDirectoryMissing) {
+			return nil, err
+		}
+
+		return module, nil
+	})
+}
+
+func (m *JobRunHistoryModule) Run(ctx context.Context) error {
+	if !m.settings.Enabled {
+		m.logger.Info(ctx, "job run history is disabled")
+
+		return nil
+	}
+
+	if m.store == nil {
+		m.logger.Warn(ctx, "job run history is disabled: %s", ErrDataDirectoryMissing)
+
+		return nil
+	}
+
+	m.logger.Info(ctx, "tracking job runs in %s", m.store.directory)
+
+	deployments, events, stop := m.watcher.Watch(ctx)
+	defer close(stop)
+
+	for _, nsDeployments := range deployments {
+		for _, deployment := range nsDeployments {
+			m.observeDeployment(ctx, deployment)
+		}
+	}
+
+	// events are dropped if the watcher can't deliver them right away, so all deployments are observed periodically
+	ticker := time.NewTicker(m.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			m.apply(ctx, event)
+		case <-ticker.C:
+			m.observeAll(ctx)
+		}
+	}
+}
+
+// GetHistory returns the runs of a deployment or, if a session job is given, of a session job, the latest first.
+// Deleted deployments keep their history.
+func (m *JobRunHistoryModule) GetHistory(namespace string, name string, sessionJob string) (*JobRunHistory, error) {
+	if m.store == nil {
+		return nil, ErrDataDirectoryMissing
+	}
+
+	return m.store.History(jobRunTarget{Namespace: namespace, Name: name, SessionJob: sessionJob})
+}
+
+func (m *JobRunHistoryModule) apply(ctx context.Context, event DeploymentEvent) {
+	if event.Type != watch.Deleted {
+		m.observeDeployment(ctx, event.Deployment)
+
+		return
+	}
+
+	ended, err := m.store.Delete(jobRunTarget{Namespace: event.Deployment.Namespace, Name: event.Deployment.Name}, time.Now().UTC())
+	if err != nil {
+		m.logger.Warn(ctx, "failed to end job run of deleted deployment %s/%s: %v", event.Deployment.Namespace, event.Deployment.Name, err)
+
+		return
+	}
+
+	if ended {
+		m.logger.Info(ctx, "ended job run of deleted deployment %s/%s", event.Deployment.Namespace, event.Deployment.Name)
+	}
+}
+
+func (m *JobRunHistoryModule) observeAll(ctx context.Context) {
+	for _, deployment := range m.watcher.GetDeployments() {
+		m.observeDeployment(ctx, deployment)
+	}
+}
+
+// observeDeployment observes the job of a deployment and, for a session cluster, the session jobs deployed to it.
+func (m *JobRunHistoryModule) observeDeployment(ctx context.Context, deployment *FlinkDeployment) {
+	m.observe(ctx, observedDeploymentJob(deployment))
+
+	for _, sessionJob := range m.watcher.GetSessionJobs(deployment.Namespace, deployment.Name) {
+		m.observe(ctx, observedSessionJob(deployment, sessionJob))
+	}
+}
+
+func (m *JobRunHistoryModule) observe(ctx context.Context, job observedJob) {
+	if _, err := m.store.Observe(job, time.Now().UTC()); err != nil {
+		m.logger.Warn(ctx, "failed to track job run of %s: %v", job.jobRunTarget, err)
+
+		return
+	}
+
+	// the restored checkpoint is only reported once the job was scheduled
+	if job.JobStatus.State != "RUNNING" {
+		return
+	}
+
+	run, ok, err := m.store.Current(job.jobRunTarget)
+	if err != nil || !ok || run.RestoreChecked || run.JobId != job.JobStatus.JobId {
+		return
+	}
+
+	m.fetchRestored(ctx, job.jobRunTarget, run.JobId)
+}
+
+// fetchRestored fetches the checkpoint the run restored from. Flink only knows it while the job is running, so
+// it is fetched until it succeeded once.
+func (m *JobRunHistoryModule) fetchRestored(ctx context.Context, target jobRunTarget, jobID string) {
+	flinkURL, _, err := m.watcher.GetJobEndpoint(target.Namespace, target.Name, target.SessionJob)
+	if err != nil {
+		m.logger.Warn(ctx, "failed to resolve endpoint of %s: %v", target, err)
+
+		return
+	}
+
+	checkpoints, err := m.client.GetCheckpoints(ctx, flinkURL, jobID)
+	if err != nil {
+		m.logger.Warn(ctx, "failed to fetch restored checkpoint of %s (job %s): %v", target, jobID, err)
+
+		return
+	}
+
+	if err = m.store.SetRestored(target, jobID, checkpoints.Restored); err != nil {
+		m.logger.Warn(ctx, "failed to track restored checkpoint of %s: %v", target, err)
+	}
+}
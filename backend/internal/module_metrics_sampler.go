@@ -0,0 +1,269 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/justtrackio/gosoline/pkg/appctx"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/kernel"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+// metricsSamplerAggregations are requested from the aggregating endpoints, skew is left out as it is hardly useful in charts
+var metricsSamplerAggregations = []string{"min", "max", "avg", "sum"}
+
+type MetricsSamplerSettings struct {
+	Enabled            bool          `cfg:"enabled" default:"true"`
+	Interval           time.Duration `cfg:"interval" default:"15s"`
+	Retention          time.Duration `cfg:"retention" default:"1h"`
+	JobMetrics         []string      `cfg:"job"`
+	VertexMetrics      []string      `cfg:"vertex"`
+	TaskManagerMetrics []string      `cfg:"taskmanager"`
+	JobManagerMetrics  []string      `cfg:"jobmanager"`
+}
+
+type metricsSamplerModuleCtxKey struct{}
+
+type metricSeriesBuffer struct {
+	series MetricSeries
+	points *ringBuffer[MetricPoint]
+}
+
+type deploymentMetricSeries struct {
+	jobId  string
+	series map[string]*metricSeriesBuffer
+}
+
+type sampledMetric struct {
+	scope      string
+	vertexId   string
+	vertexName string
+	metric     FlinkMetric
+}
+
+// MetricsSamplerModule periodically samples the configured metrics of all running deployments and keeps
+// them in a ring buffer per metric, so the last hour can be charted without an external monitoring stack.
+type MetricsSamplerModule struct {
+	kernel.BackgroundModule
+	kernel.ServiceStage
+
+	lck         sync.Mutex
+	logger      log.Logger
+	settings    *MetricsSamplerSettings
+	client      *FlinkClient
+	watcher     *DeploymentWatcherModule
+	deployments map[string]*deploymentMetricSeries
+}
+
+func ProvideMetricsSamplerModule(ctx context.Context, config cfg.Config, logger log.Logger) (*MetricsSamplerModule, error) {
+	return appctx.Provide(ctx, metricsSamplerModuleCtxKey{}, func() (*MetricsSamplerModule, error) {
+		var err error
+		var client *FlinkClient
+		var watcher *DeploymentWatcherModule
+
+		settings := &MetricsSamplerSettings{}
+		if err = config.UnmarshalKey("metrics.sampler", settings); err != nil {
+			return nil, fmt.Errorf("could not unmarshal metrics sampler settings: %w", err)
+		}
+
+		if client, err = ProvideFlinkClient(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not create flink client: %w", err)
+		}
+
+		if watcher, err = ProvideDeploymentWatcherModule(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not initialize deployment watcher: %w", err)
+		}
+
+		return &MetricsSamplerModule{
+			logger:      logger.WithChannel("metrics-sampler"),
+			settings:    settings,
+			client:      client,
+			watcher:     watcher,
+			deployments: map[string]*deploymentMetricSeries{},
+		}, nil
+	})
+}
+
+func (m *MetricsSamplerModule) Run(ctx context.Context) error {
+	if !m.settings.Enabled {
+		m.logger.Info(ctx, "metrics sampling is disabled")
+
+		return nil
+	}
+
+	m.logger.Info(ctx, "starting metrics sampling every %s with a retention of %s", m.settings.Interval, m.settings.Retention)
+
+	ticker := time.NewTicker(m.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.sampleAll(ctx)
+		}
+	}
+}
+
+// GetSeries returns the sampled series of a deployment, optionally filtered by scope, vertex and metric name.
+func (m *MetricsSamplerModule) GetSeries(namespace, name, scope, vertexId, metric string) []MetricSeries {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	result := make([]MetricSeries, 0)
+
+	deployment, ok := m.deployments[namespace+"/"+name]
+	if !ok {
+		return result
+	}
+
+	for _, buffer := range deployment.series {
+		if (scope != "" && buffer.series.Scope != scope) || (vertexId != "" && buffer.series.VertexId != vertexId) || (metric != "" && buffer.series.Metric != metric) {
+			continue
+		}
+
+		series := buffer.series
+		series.Points = buffer.points.Items()
+		result = append(result, series)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Scope != result[j].Scope {
+			return result[i].Scope < result[j].Scope
+		}
+
+		if result[i].VertexName != result[j].VertexName {
+			return result[i].VertexName < result[j].VertexName
+		}
+
+		return result[i].Metric < result[j].Metric
+	})
+
+	return result
+}
+
+func (m *MetricsSamplerModule) sampleAll(ctx context.Context) {
+	running := map[string]bool{}
+
+	for _, deployment := range m.watcher.GetDeployments() {
+		if deployment.GetStatusGroup() != "running" || deployment.Status.JobStatus.JobId == "" {
+			continue
+		}
+
+		running[deployment.Namespace+"/"+deployment.Name] = true
+
+		if err := m.sample(ctx, deployment.Namespace, deployment.Name); err != nil {
+			m.logger.Warn(ctx, "failed to sample metrics of deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
+		}
+	}
+
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	for key := range m.deployments {
+		if !running[key] {
+			delete(m.deployments, key)
+		}
+	}
+}
+
+func (m *MetricsSamplerModule) sample(ctx context.Context, namespace, name string) error {
+	flinkURL, jobID, err := m.watcher.GetFlinkEndpoint(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	samples := make([]sampledMetric, 0)
+
+	collect := func(scope FlinkMetricScope, vertexName string, metrics []string, aggregations []string) error {
+		if len(metrics) == 0 {
+			return nil
+		}
+
+		values, err := m.client.GetMetrics(ctx, flinkURL, jobID, scope, metrics, aggregations)
+		if err != nil {
+			return err
+		}
+
+		for _, value := range values {
+			samples = append(samples, sampledMetric{scope: scope.Scope, vertexId: scope.VertexId, vertexName: vertexName, metric: value})
+		}
+
+		return nil
+	}
+
+	if err = collect(FlinkMetricScope{Scope: MetricScopeJob}, "", m.settings.JobMetrics, nil); err != nil {
+		return err
+	}
+
+	if err = collect(FlinkMetricScope{Scope: MetricScopeJobManager}, "", m.settings.JobManagerMetrics, nil); err != nil {
+		return err
+	}
+
+	if err = collect(FlinkMetricScope{Scope: MetricScopeTaskManager}, "", m.settings.TaskManagerMetrics, metricsSamplerAggregations); err != nil {
+		return err
+	}
+
+	if len(m.settings.VertexMetrics) > 0 {
+		details, err := m.client.GetJob(ctx, flinkURL, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch job from Flink: %w", err)
+		}
+
+		for _, vertex := range details.Vertices {
+			if err = collect(FlinkMetricScope{Scope: MetricScopeVertex, VertexId: vertex.Id}, vertex.Name, m.settings.VertexMetrics, metricsSamplerAggregations); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.store(namespace+"/"+name, jobID, now, samples)
+
+	return nil
+}
+
+func (m *MetricsSamplerModule) store(key string, jobID string, now time.Time, samples []sampledMetric) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	deployment, ok := m.deployments[key]
+	if !ok || deployment.jobId != jobID {
+		deployment = &deploymentMetricSeries{
+			jobId:  jobID,
+			series: map[string]*metricSeriesBuffer{},
+		}
+		m.deployments[key] = deployment
+	}
+
+	capacity := int(m.settings.Retention/m.settings.Interval) + 1
+
+	for _, sample := range samples {
+		point, ok := toMetricPoint(now, sample.metric)
+		if !ok {
+			continue
+		}
+
+		seriesKey := sample.scope + "/" + sample.vertexId + "/" + sample.metric.Id
+		buffer, ok := deployment.series[seriesKey]
+		if !ok {
+			buffer = &metricSeriesBuffer{
+				series: MetricSeries{
+					Scope:      sample.scope,
+					VertexId:   sample.vertexId,
+					VertexName: sample.vertexName,
+					Metric:     sample.metric.Id,
+				},
+				points: newRingBuffer[MetricPoint](capacity),
+			}
+			deployment.series[seriesKey] = buffer
+		}
+
+		buffer.points.Add(point)
+	}
+}
@@ -0,0 +1,340 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justtrackio/gosoline/pkg/appctx"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/kernel"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+var storageJobIdPattern = regexp.MustCompile(`^(?:job_)?([0-9a-f]{32}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+
+type StorageUsageSettings struct {
+	InitialDelay time.Duration `cfg:"initial_delay" default:"1m"`
+	Interval     time.Duration `cfg:"interval" default:"1h"`
+	HistorySize  int           `cfg:"history_size" default:"168"`
+	Directory    string        `cfg:"directory"`
+}
+
+type storageUsageModuleCtxKey struct{}
+
+// StorageUsageModule periodically scans the checkpoint, savepoint and high availability storage of all
+// deployments and keeps the latest report together with a bounded history of condensed snapshots. Both are
+// persisted to the data directory if one is configured, otherwise the history starts over on every restart.
+type StorageUsageModule struct {
+	kernel.BackgroundModule
+	kernel.ServiceStage
+
+	lck       sync.Mutex
+	logger    log.Logger
+	settings  *StorageUsageSettings
+	watcher   *DeploymentWatcherModule
+	s3Service *S3Service
+	directory string
+	latest    *StorageUsageReport
+	history   []StorageUsageSnapshot
+}
+
+func ProvideStorageUsageModule(ctx context.Context, config cfg.Config, logger log.Logger) (*StorageUsageModule, error) {
+	return appctx.Provide(ctx, storageUsageModuleCtxKey{}, func() (*StorageUsageModule, error) {
+		var err error
+		var watcher *DeploymentWatcherModule
+		var s3Service *S3Service
+
+		settings := &StorageUsageSettings{}
+		if err = config.UnmarshalKey("storage.usage", settings); err != nil {
+			return nil, fmt.Errorf("could not unmarshal storage usage settings: %w", err)
+		}
+
+		if watcher, err = ProvideDeploymentWatcherModule(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not initialize deployment watcher: %w", err)
+		}
+
+		if s3Service, err = ProvideS3Service(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not initialize s3 service: %w", err)
+		}
+
+		directory, err := dataDirectory(config, settings.Directory, "storage_usage")
+		if err != nil && !errors.Is(err, ErrDataDirectoryMissing) {
+			return nil, err
+		}
+
+		return &StorageUsageModule{
+			logger:    logger.WithChannel("storage-usage"),
+			settings:  settings,
+			watcher:   watcher,
+			s3Service: s3Service,
+			directory: directory,
+		}, nil
+	})
+}
+
+func (m *StorageUsageModule) Run(ctx context.Context) error {
+	m.logger.Info(ctx, "starting storage usage scans every %s", m.settings.Interval)
+
+	if m.directory == "" {
+		m.logger.Warn(ctx, "no data directory configured, the storage usage history is only kept in memory")
+	} else if err := m.load(); err != nil {
+		m.logger.Warn(ctx, "failed to load the storage usage history from %s: %v", m.directory, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-time.After(m.settings.InitialDelay):
+	}
+
+	ticker := time.NewTicker(m.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetLatestReport returns the report of the last finished scan, if there was one already.
+func (m *StorageUsageModule) GetLatestReport() (*StorageUsageReport, bool) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	return m.latest, m.latest != nil
+}
+
+// GetHistory returns the snapshots of all scans kept in memory, oldest first.
+func (m *StorageUsageModule) GetHistory() []StorageUsageSnapshot {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	history := make([]StorageUsageSnapshot, len(m.history))
+	copy(history, m.history)
+
+	return history
+}
+
+// GetDeploymentUsage returns the latest usage of a single deployment together with its trend over the history.
+func (m *StorageUsageModule) GetDeploymentUsage(namespace, name string) (*DeploymentStorageUsage, []DeploymentStorageUsagePoint) {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	key := namespace + "/" + name
+	trend := make([]DeploymentStorageUsagePoint, 0, len(m.history))
+
+	for _, snapshot := range m.history {
+		if usage, ok := snapshot.Deployments[key]; ok {
+			trend = append(trend, DeploymentStorageUsagePoint{ScannedAt: snapshot.ScannedAt, Usage: usage})
+		}
+	}
+
+	if m.latest == nil {
+		return nil, trend
+	}
+
+	for i := range m.latest.Deployments {
+		if m.latest.Deployments[i].Namespace == namespace && m.latest.Deployments[i].Name == name {
+			return &m.latest.Deployments[i], trend
+		}
+	}
+
+	return nil, trend
+}
+
+func (m *StorageUsageModule) scan(ctx context.Context) {
+	start := time.Now()
+	deployments := m.watcher.GetDeployments()
+	m.logger.Info(ctx, "scanning storage usage of %d deployments", len(deployments))
+
+	report := &StorageUsageReport{
+		ScannedAt:   start.UTC(),
+		Deployments: make([]DeploymentStorageUsage, 0, len(deployments)),
+	}
+	namespaces := map[string]*NamespaceStorageUsage{}
+
+	for _, deployment := range deployments {
+		if ctx.Err() != nil {
+			return
+		}
+
+		usage := m.scanDeployment(ctx, deployment)
+		report.Deployments = append(report.Deployments, usage)
+		report.Total.Add(usage.Total)
+
+		namespace, ok := namespaces[usage.Namespace]
+		if !ok {
+			namespace = &NamespaceStorageUsage{Namespace: usage.Namespace}
+			namespaces[usage.Namespace] = namespace
+		}
+
+		namespace.Deployments++
+		namespace.Checkpoints.Add(usage.Checkpoints)
+		namespace.Savepoints.Add(usage.Savepoints)
+		namespace.HighAvailability.Add(usage.HighAvailability)
+		namespace.Total.Add(usage.Total)
+	}
+
+	for _, namespace := range namespaces {
+		report.Namespaces = append(report.Namespaces, *namespace)
+	}
+
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		return report.Namespaces[i].Total.Bytes > report.Namespaces[j].Total.Bytes
+	})
+	sort.Slice(report.Deployments, func(i, j int) bool {
+		return report.Deployments[i].Total.Bytes > report.Deployments[j].Total.Bytes
+	})
+
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.latest = report
+	m.history = append(m.history, report.toSnapshot())
+	if len(m.history) > m.settings.HistorySize {
+		m.history = m.history[len(m.history)-m.settings.HistorySize:]
+	}
+
+	m.logger.Info(ctx, "scanned storage usage in %s: %d objects with %d bytes", time.Since(start), report.Total.Objects, report.Total.Bytes)
+
+	if err := m.save(); err != nil {
+		m.logger.Warn(ctx, "failed to persist the storage usage history to %s: %v", m.directory, err)
+	}
+}
+
+// load restores the latest report and the history persisted before the last restart.
+func (m *StorageUsageModule) load() error {
+	latest := &StorageUsageReport{}
+	history := make([]StorageUsageSnapshot, 0)
+
+	found, err := readJSONFile(filepath.Join(m.directory, "latest.json"), latest)
+	if err != nil || !found {
+		return err
+	}
+
+	if _, err = readJSONFile(filepath.Join(m.directory, "history.json"), &history); err != nil {
+		return err
+	}
+
+	if len(history) > m.settings.HistorySize {
+		history = history[len(history)-m.settings.HistorySize:]
+	}
+
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	m.latest = latest
+	m.history = history
+
+	return nil
+}
+
+// save persists the latest report and the history, the caller has to hold the lock.
+func (m *StorageUsageModule) save() error {
+	if m.directory == "" {
+		return nil
+	}
+
+	if err := writeJSONFile(filepath.Join(m.directory, "latest.json"), m.latest); err != nil {
+		return err
+	}
+
+	return writeJSONFile(filepath.Join(m.directory, "history.json"), m.history)
+}
+
+func (m *StorageUsageModule) scanDeployment(ctx context.Context, deployment *FlinkDeployment) DeploymentStorageUsage {
+	usage := DeploymentStorageUsage{
+		Namespace: deployment.Namespace,
+		Name:      deployment.Name,
+		Jobs:      map[string]StorageUsage{},
+	}
+
+	config := deployment.Spec.FlinkConfiguration
+	checkpointDir, _ := getStringConfig(config, "execution.checkpointing.dir")
+	savepointDir, _ := getStringConfig(config, "execution.checkpointing.savepoint-dir")
+
+	locations := []struct {
+		dir   string
+		usage *StorageUsage
+	}{
+		{dir: checkpointDir, usage: &usage.Checkpoints},
+		{dir: savepointDir, usage: &usage.Savepoints},
+		{dir: highAvailabilityDir(config, deployment.Name), usage: &usage.HighAvailability},
+	}
+
+	for _, location := range locations {
+		dir := location.dir
+		if dir == "" {
+			continue
+		}
+
+		if !strings.HasSuffix(dir, "/") {
+			dir += "/"
+		}
+
+		err := m.s3Service.WalkObjects(ctx, dir, func(object StorageObject) {
+			objectUsage := StorageUsage{Objects: 1, Bytes: object.Size}
+			jobId := storageJobId(strings.TrimPrefix(object.Path, dir))
+
+			location.usage.Add(objectUsage)
+			jobUsage := usage.Jobs[jobId]
+			jobUsage.Add(objectUsage)
+			usage.Jobs[jobId] = jobUsage
+		})
+		if err != nil {
+			m.logger.Warn(ctx, "failed to scan %s of deployment %s/%s: %v", dir, deployment.Namespace, deployment.Name, err)
+			usage.Errors = append(usage.Errors, fmt.Sprintf("%s: %s", dir, err))
+		}
+
+		usage.Total.Add(*location.usage)
+	}
+
+	return usage
+}
+
+// highAvailabilityDir returns the directory below high-availability.storageDir which holds the high availability data
+// of a deployment. Flink stores it below the cluster id, so deployments sharing a storage dir don't count each other's data.
+func highAvailabilityDir(config map[string]any, name string) string {
+	storageDir, ok := getStringConfig(config, "high-availability.storageDir")
+	if !ok {
+		return ""
+	}
+
+	clusterId := name
+	if id, ok := getStringConfig(config, "kubernetes.cluster-id"); ok {
+		clusterId = id
+	}
+
+	if id, ok := getStringConfig(config, "high-availability.cluster-id"); ok {
+		clusterId = id
+	}
+
+	return strings.TrimSuffix(storageDir, "/") + "/" + strings.Trim(clusterId, "/")
+}
+
+// storageJobId returns the dashless job ID an object belongs to, based on its path relative to the storage directory.
+// Checkpoints and savepoints are stored below a job ID directory while the high availability storage uses
+// "job_<id>" directories further down the tree.
+func storageJobId(relativePath string) string {
+	for _, segment := range strings.Split(relativePath, "/") {
+		if match := storageJobIdPattern.FindStringSubmatch(segment); match != nil {
+			return strings.ReplaceAll(match[1], "-", "")
+		}
+	}
+
+	return ""
+}
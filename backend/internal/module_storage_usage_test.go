@@ -0,0 +1,36 @@
+package internal
+
+import "testing"
+
+func TestHighAvailabilityDir(t *testing.T) {
+	cases := map[string]struct {
+		config   map[string]any
+		expected string
+	}{
+		"deployment name": {
+			config:   map[string]any{"high-availability.storageDir": "s3://bucket/ha/"},
+			expected: "s3://bucket/ha/orders",
+		},
+		"kubernetes cluster id": {
+			config:   map[string]any{"high-availability.storageDir": "s3://bucket/ha", "kubernetes.cluster-id": "orders-cluster"},
+			expected: "s3://bucket/ha/orders-cluster",
+		},
+		"high availability cluster id": {
+			config: map[string]any{
+				"high-availability.storageDir": "s3://bucket/ha",
+				"kubernetes.cluster-id":        "orders-cluster",
+				"high-availability.cluster-id": "/orders-ha",
+			},
+			expected: "s3://bucket/ha/orders-ha",
+		},
+		"no storage dir": {
+			config: map[string]any{"high-availability.cluster-id": "orders-ha"},
+		},
+	}
+
+	for name, tc := range cases {
+		if dir := highAvailabilityDir(tc.config, "orders"); dir != tc.expected {
+			t.Errorf("%s: expected %q, got %q", name, tc.expected, dir)
+		}
+	}
+}
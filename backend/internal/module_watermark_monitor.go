@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/justtrackio/gosoline/pkg/appctx"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/kernel"
+	"github.com/justtrackio/gosoline/pkg/log"
+)
+
+type WatermarkMonitorSettings struct {
+	Enabled       bool          `cfg:"enabled" default:"true"`
+	Interval      time.Duration `cfg:"interval" default:"1m"`
+	LagThreshold  time.Duration `cfg:"lag_threshold" default:"15m"`
+	SkewThreshold time.Duration `cfg:"skew_threshold" default:"10m"`
+	IdleTimeout   time.Duration `cfg:"idle_timeout" default:"5m"`
+}
+
+type watermarkMonitorModuleCtxKey struct{}
+
+// WatermarkMonitorModule periodically polls the watermarks of all running deployments. It keeps track of
+// when the watermark of every subtask advanced the last time to detect idle subtasks and raises alerts
+// for lagging, skewed or idle watermarks.
+type WatermarkMonitorModule struct {
+	kernel.BackgroundModule
+	kernel.ServiceStage
+
+	lck      sync.Mutex
+	logger   log.Logger
+	settings *WatermarkMonitorSettings
+	client   *FlinkClient
+	watcher  *DeploymentWatcherModule
+	trackers map[string]*watermarkTracker
+	reports  map[string]*WatermarkReport
+}
+
+func ProvideWatermarkMonitorModule(ctx context.Context, config cfg.Config, logger log.Logger) (*WatermarkMonitorModule, error) {
+	return appctx.Provide(ctx, watermarkMonitorModuleCtxKey{}, func() (*WatermarkMonitorModule, error) {
+		var err error
+		var client *FlinkClient
+		var watcher *DeploymentWatcherModule
+
+		settings := &WatermarkMonitorSettings{}
+		if err = config.UnmarshalKey("watermarks", settings); err != nil {
+			return nil, fmt.Errorf("could not unmarshal watermark settings: %w", err)
+		}
+
+		if client, err = ProvideFlinkClient(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not create flink client: %w", err)
+		}
+
+		if watcher, err = ProvideDeploymentWatcherModule(ctx, config, logger); err != nil {
+			return nil, fmt.Errorf("could not initialize deployment watcher: %w", err)
+		}
+
+		return &WatermarkMonitorModule{
+			logger:   logger.WithChannel("watermark-monitor"),
+			settings: settings,
+			client:   client,
+			watcher:  watcher,
+			trackers: map[string]*watermarkTracker{},
+			reports:  map[string]*WatermarkReport{},
+		}, nil
+	})
+}
+
+func (m *WatermarkMonitorModule) Run(ctx context.Context) error {
+	if !m.settings.Enabled {
+		m.logger.Info(ctx, "watermark monitoring is disabled")
+
+		return nil
+	}
+
+	m.logger.Info(ctx, "starting watermark monitoring every %s", m.settings.Interval)
+
+	ticker := time.NewTicker(m.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.checkAll(ctx)
+		}
+	}
+}
+
+// GetAlerts returns the active alerts of all deployments as of their last check.
+func (m *WatermarkMonitorModule) GetAlerts() []WatermarkAlert {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	alerts := make([]WatermarkAlert, 0)
+	for _, report := range m.reports {
+		alerts = append(alerts, report.Alerts...)
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Since.Before(alerts[j].Since)
+	})
+
+	return alerts
+}
+
+// Check polls the watermarks of all vertices of a deployment and updates the tracked state with them.
+func (m *WatermarkMonitorModule) Check(ctx context.Context, namespace, name string) (*WatermarkReport, error) {
+	flinkURL, jobID, err := m.watcher.GetFlinkEndpoint(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := m.client.GetJob(ctx, flinkURL, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job from Flink: %w", err)
+	}
+
+	vertices := make([]VertexWatermarks, len(details.Vertices))
+	watermarks := make([][]FlinkMetric, len(details.Vertices))
+
+	wg := sync.WaitGroup{}
+	for i, vertex := range details.Vertices {
+		vertices[i] = VertexWatermarks{
+			VertexId:     vertex.Id,
+			Name:         vertex.Name,
+			Parallelism:  vertex.Parallelism,
+			IdleSubtasks: []int{},
+			Subtasks:     []SubtaskWatermark{},
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			values, err := m.client.GetVertexWatermarks(ctx, flinkURL, jobID, vertices[i].VertexId)
+			if err != nil {
+				m.logger.Warn(ctx, "failed to fetch watermarks of vertex %s: %v", vertices[i].VertexId, err)
+				vertices[i].Error = err.Error()
+
+				return
+			}
+
+			watermarks[i] = values
+		}(i)
+	}
+	wg.Wait()
+
+	return m.update(ctx, namespace, name, jobID, vertices, watermarks), nil
+}
+
+func (m *WatermarkMonitorModule) update(ctx context.Context, namespace, name, jobID string, vertices []VertexWatermarks, watermarks [][]FlinkMetric) *WatermarkReport {
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	now := time.Now()
+	key := namespace + "/" + name
+
+	tracker, ok := m.trackers[key]
+	if !ok || tracker.jobId != jobID {
+		tracker = newWatermarkTracker(jobID)
+		m.trackers[key] = tracker
+	}
+
+	for i := range vertices {
+		if vertices[i].Error == "" {
+			tracker.observe(now, &vertices[i], watermarks[i], m.settings.IdleTimeout)
+		}
+	}
+
+	report := &WatermarkReport{
+		Namespace: namespace,
+		Name:      name,
+		JobId:     jobID,
+		CheckedAt: now.UTC(),
+		Vertices:  vertices,
+	}
+
+	for _, alert := range tracker.evaluate(now, report, m.settings) {
+		m.logger.Warn(ctx, "watermark alert for deployment %s/%s (job %s) on vertex %s: %s", namespace, name, jobID, alert.VertexName, alert.Message)
+	}
+
+	m.reports[key] = report
+
+	return report
+}
+
+func (m *WatermarkMonitorModule) checkAll(ctx context.Context) {
+	running := map[string]bool{}
+
+	for _, deployment := range m.watcher.GetDeployments() {
+		if deployment.GetStatusGroup() != "running" || deployment.Status.JobStatus.JobId == "" {
+			continue
+		}
+
+		running[deployment.Namespace+"/"+deployment.Name] = true
+
+		if _, err := m.Check(ctx, deployment.Namespace, deployment.Name); err != nil {
+			m.logger.Warn(ctx, "failed to check watermarks of deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
+		}
+	}
+
+	m.lck.Lock()
+	defer m.lck.Unlock()
+
+	for key := range m.trackers {
+		if !running[key] {
+			delete(m.trackers, key)
+			delete(m.reports, key)
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -18,9 +20,27 @@ import (
 
 type s3ServiceCtxKey struct{}
 
+const (
+	// metadataCacheTtl is how long the _metadata of a checkpoint which was not listed anymore is remembered.
+	metadataCacheTtl = time.Hour
+	// metadataCacheEvictionInterval is how often the cache is checked for expired entries.
+	metadataCacheEvictionInterval = 5 * time.Minute
+)
+
 type S3Service struct {
 	logger   log.Logger
 	s3Client *s3.Client
+
+	// metadataLck guards metadataCache, which keeps the _metadata of completed checkpoints and savepoints by their
+	// directory. The file is written once when the checkpoint completes, so listings only have to check new ones.
+	metadataLck     sync.Mutex
+	metadataCache   map[string]cachedMetadataInfo
+	metadataEvicted time.Time
+}
+
+type cachedMetadataInfo struct {
+	info *MetadataInfo
+	seen time.Time
 }
 
 func ProvideS3Service(ctx context.Context, config cfg.Config, logger log.Logger) (*S3Service, error) {
@@ -31,8 +51,9 @@ func ProvideS3Service(ctx context.Context, config cfg.Config, logger log.Logger)
 		}
 
 		return &S3Service{
-			logger:   logger.WithChannel("s3_service"),
-			s3Client: s3Client,
+			logger:        logger.WithChannel("s3_service"),
+			s3Client:      s3Client,
+			metadataCache: map[string]cachedMetadataInfo{},
 		}, nil
 	})
 }
@@ -40,7 +61,9 @@ func ProvideS3Service(ctx context.Context, config cfg.Config, logger log.Logger)
 type StorageEntry struct {
 	Name         string     `json:"name"`
 	Path         string     `json:"path"`
+	Type         string     `json:"type,omitempty"`
 	JobId        string     `json:"jobId,omitempty"`
+	CheckpointId *int64     `json:"checkpointId,omitempty"`
 	LastModified *time.Time `json:"lastModified,omitempty"`
 	Size         *int64     `json:"size,omitempty"`
 }
@@ -51,6 +74,21 @@ type MetadataInfo struct {
 	Size         *int64
 }
 
+// StorageObject is a single object found by a recursive listing below a storage directory.
+type StorageObject struct {
+	Key          string    `json:"key"`
+	Path         string    `json:"path"`
+	LastModified time.Time `json:"lastModified"`
+	Size         int64     `json:"size"`
+}
+
+// PresignedURL is a time limited GET URL for a single object.
+type PresignedURL struct {
+	Path      string    `json:"path"`
+	Url       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 // parseS3URI parses an S3 URI like "s3://bucket/prefix/path" into bucket and prefix
 func parseS3URI(uri string) (bucket, prefix string, err error) {
 	if !strings.HasPrefix(uri, "s3://") {
@@ -79,6 +117,25 @@ func parseS3URI(uri string) (bucket, prefix string, err error) {
 	return bucket, prefix, nil
 }
 
+// parseS3ObjectURI parses an S3 URI like "s3://bucket/path/to/object" into bucket and object key.
+// Unlike parseS3URI, the key is returned as is and must not be empty.
+func parseS3ObjectURI(uri string) (bucket, key string, err error) {
+	if !strings.HasPrefix(uri, "s3://") {
+		return "", "", fmt.Errorf("invalid S3 URI format: %s (must start with s3://)", uri)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(uri, "s3://"), "/", 2)
+	if parts[0] == "" {
+		return "", "", fmt.Errorf("invalid S3 URI: missing bucket name")
+	}
+
+	if len(parts) < 2 || parts[1] == "" || strings.HasSuffix(parts[1], "/") {
+		return "", "", fmt.Errorf("invalid S3 URI: %s does not point to an object", uri)
+	}
+
+	return parts[0], parts[1], nil
+}
+
 // listCommonPrefixNames paginates through S3 ListObjectsV2 with a "/" delimiter and returns
 // the directory names (common prefix entries with the base prefix and trailing slash stripped).
 func (s *S3Service) listCommonPrefixNames(ctx context.Context, bucket, prefix string) ([]string, error) {
@@ -200,10 +257,70 @@ func (s *S3Service) GetMetadataInfo(ctx context.Context, s3URI string) (*Metadat
 
 	s.logger.Debug(ctx, "checking for metadata file: s3://%s/%s", bucket, metadataKey)
 
-	// Use HeadObject to check if the _metadata file exists
+	return s.headObject(ctx, bucket, metadataKey)
+}
+
+// GetCompletedMetadataInfo is GetMetadataInfo for checkpoint and savepoint directories whose _metadata doesn't change
+// once it exists. Existing files are cached, so repeated listings don't check every directory again.
+func (s *S3Service) GetCompletedMetadataInfo(ctx context.Context, s3URI string) (*MetadataInfo, error) {
+	now := time.Now()
+
+	s.metadataLck.Lock()
+	cached, ok := s.metadataCache[s3URI]
+	if ok {
+		s.metadataCache[s3URI] = cachedMetadataInfo{info: cached.info, seen: now}
+	}
+	s.metadataLck.Unlock()
+
+	if ok {
+		return cached.info, nil
+	}
+
+	info, err := s.GetMetadataInfo(ctx, s3URI)
+	if err != nil || !info.Exists {
+		return info, err
+	}
+
+	s.metadataLck.Lock()
+	defer s.metadataLck.Unlock()
+
+	s.evictMetadataCache(now)
+	s.metadataCache[s3URI] = cachedMetadataInfo{info: info, seen: now}
+
+	return info, nil
+}
+
+// evictMetadataCache removes the entries which were not seen within the ttl. It only scans the cache once per
+// eviction interval, so adding many directories in a single listing doesn't scan it again for every directory.
+// The caller has to hold metadataLck.
+func (s *S3Service) evictMetadataCache(now time.Time) {
+	if now.Sub(s.metadataEvicted) < metadataCacheEvictionInterval {
+		return
+	}
+
+	s.metadataEvicted = now
+
+	for path, entry := range s.metadataCache {
+		if now.Sub(entry.seen) > metadataCacheTtl {
+			delete(s.metadataCache, path)
+		}
+	}
+}
+
+// GetObjectInfo checks if a single object exists and returns its info
+func (s *S3Service) GetObjectInfo(ctx context.Context, s3URI string) (*MetadataInfo, error) {
+	bucket, key, err := parseS3ObjectURI(s3URI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse S3 URI: %w", err)
+	}
+
+	return s.headObject(ctx, bucket, key)
+}
+
+func (s *S3Service) headObject(ctx context.Context, bucket string, key string) (*MetadataInfo, error) {
 	input := &s3.HeadObjectInput{
 		Bucket: &bucket,
-		Key:    &metadataKey,
+		Key:    &key,
 	}
 
 	result, err := s.s3Client.HeadObject(ctx, input)
@@ -213,7 +330,7 @@ func (s *S3Service) GetMetadataInfo(ctx context.Context, s3URI string) (*Metadat
 			return &MetadataInfo{Exists: false}, nil
 		}
 
-		return nil, fmt.Errorf("failed to get metadata head for s3://%s/%s: %w", bucket, metadataKey, err)
+		return nil, fmt.Errorf("failed to get head for s3://%s/%s: %w", bucket, key, err)
 	}
 
 	return &MetadataInfo{
@@ -252,7 +369,7 @@ func (s *S3Service) ListValidCheckpoints(ctx context.Context, checkpointBasePath
 		}
 
 		// Get metadata file info
-		metadataInfo, err := s.GetMetadataInfo(ctx, checkpoint.Path)
+		metadataInfo, err := s.GetCompletedMetadataInfo(ctx, checkpoint.Path)
 		if err != nil {
 			s.logger.Warn(ctx, "failed to check metadata for %s: %v", checkpoint.Path, err)
 
@@ -260,7 +377,9 @@ func (s *S3Service) ListValidCheckpoints(ctx context.Context, checkpointBasePath
 		}
 
 		if metadataInfo.Exists {
+			checkpoint.Type = StorageEntryTypeCheckpoint
 			checkpoint.JobId = jobId
+			checkpoint.CheckpointId = parseCheckpointId(checkpoint.Name)
 			checkpoint.LastModified = metadataInfo.LastModified
 			checkpoint.Size = metadataInfo.Size
 			validCheckpoints = append(validCheckpoints, checkpoint)
@@ -271,3 +390,105 @@ func (s *S3Service) ListValidCheckpoints(ctx context.Context, checkpointBasePath
 
 	return validCheckpoints, nil
 }
+
+// ListObjects recursively lists all objects below a storage directory.
+func (s *S3Service) ListObjects(ctx context.Context, s3URI string) ([]StorageObject, error) {
+	var objects []StorageObject
+
+	err := s.WalkObjects(ctx, s3URI, func(object StorageObject) {
+		objects = append(objects, object)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// WalkObjects recursively iterates over all objects below a storage directory without keeping them in memory.
+func (s *S3Service) WalkObjects(ctx context.Context, s3URI string, fn func(object StorageObject)) error {
+	bucket, prefix, err := parseS3URI(s3URI)
+	if err != nil {
+		return fmt.Errorf("failed to parse S3 URI: %w", err)
+	}
+
+	var continuationToken *string
+
+	for {
+		result, err := s.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            &bucket,
+			Prefix:            &prefix,
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list objects in S3: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			if object.Key == nil {
+				continue
+			}
+
+			entry := StorageObject{
+				Key:  *object.Key,
+				Path: "s3://" + bucket + "/" + *object.Key,
+			}
+
+			if object.LastModified != nil {
+				entry.LastModified = *object.LastModified
+			}
+
+			if object.Size != nil {
+				entry.Size = *object.Size
+			}
+
+			fn(entry)
+		}
+
+		if result.IsTruncated == nil || !*result.IsTruncated {
+			return nil
+		}
+
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// OpenObject opens a single object for reading. The caller has to close the returned body.
+func (s *S3Service) OpenObject(ctx context.Context, s3URI string) (io.ReadCloser, error) {
+	bucket, key, err := parseS3ObjectURI(s3URI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse S3 URI: %w", err)
+	}
+
+	result, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return result.Body, nil
+}
+
+// PresignGetObject creates a presigned GET URL for a single object which is valid for the given duration.
+func (s *S3Service) PresignGetObject(ctx context.Context, s3URI string, expiry time.Duration) (*PresignedURL, error) {
+	bucket, key, err := parseS3ObjectURI(s3URI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse S3 URI: %w", err)
+	}
+
+	request, err := s3.NewPresignClient(s.s3Client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return &PresignedURL{
+		Path:      s3URI,
+		Url:       request.URL,
+		ExpiresAt: time.Now().Add(expiry),
+	}, nil
+}
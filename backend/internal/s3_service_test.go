@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestS3ServiceEvictMetadataCache(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service := &S3Service{
+		metadataCache: map[string]cachedMetadataInfo{
+			"s3://bucket/job/chk-1": {info: &MetadataInfo{Exists: true}, seen: now.Add(-2 * metadataCacheTtl)},
+			"s3://bucket/job/chk-2": {info: &MetadataInfo{Exists: true}, seen: now.Add(-time.Minute)},
+		},
+	}
+
+	service.evictMetadataCache(now)
+
+	if _, ok := service.metadataCache["s3://bucket/job/chk-1"]; ok || len(service.metadataCache) != 1 {
+		t.Fatalf("expected only the expired entry to be evicted, got %v", service.metadataCache)
+	}
+
+	// the cache is not scanned again until the eviction interval passed
+	expiredAt := now.Add(metadataCacheTtl + time.Minute)
+	service.metadataCache["s3://bucket/job/chk-3"] = cachedMetadataInfo{info: &MetadataInfo{Exists: true}, seen: now.Add(-2 * metadataCacheTtl)}
+
+	service.evictMetadataCache(now.Add(time.Minute))
+	if len(service.metadataCache) != 2 {
+		t.Errorf("expected no eviction within the interval, got %v", service.metadataCache)
+	}
+
+	service.evictMetadataCache(expiredAt)
+	if len(service.metadataCache) != 0 {
+		t.Errorf("expected all entries to be evicted, got %v", service.metadataCache)
+	}
+}
@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StorageEntryTypeCheckpoint = "checkpoint"
+	StorageEntryTypeSavepoint  = "savepoint"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// StorageCheckpointsQuery filters, sorts and paginates the checkpoints and savepoints found in storage.
+// A zero value returns all entries, newest first.
+type StorageCheckpointsQuery struct {
+	JobId  string
+	Type   string
+	From   time.Time
+	To     time.Time
+	Sort   string
+	Limit  int
+	Cursor string
+}
+
+// storageCursor is the position after the last entry of a page. It is handed to clients base64 encoded
+// and is only meaningful together with the same filters and sort order.
+type storageCursor struct {
+	LastModified int64  `json:"t"`
+	CheckpointId int64  `json:"c"`
+	Path         string `json:"p"`
+}
+
+func (q StorageCheckpointsQuery) Validate() error {
+	if q.Type != "" && q.Type != StorageEntryTypeCheckpoint && q.Type != StorageEntryTypeSavepoint {
+		return fmt.Errorf("invalid type %q, must be %s or %s", q.Type, StorageEntryTypeCheckpoint, StorageEntryTypeSavepoint)
+	}
+
+	if q.Sort != "" && q.Sort != SortOrderAsc && q.Sort != SortOrderDesc {
+		return fmt.Errorf("invalid sort order %q, must be %s or %s", q.Sort, SortOrderAsc, SortOrderDesc)
+	}
+
+	if q.Limit < 0 {
+		return fmt.Errorf("invalid limit %d", q.Limit)
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return fmt.Errorf("the end of the time range is before its start")
+	}
+
+	if _, err := decodeStorageCursor(q.Cursor); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (q StorageCheckpointsQuery) includesType(entryType string) bool {
+	return q.Type == "" || q.Type == entryType
+}
+
+func (q StorageCheckpointsQuery) includesJob(jobId string) bool {
+	return q.JobId == "" || strings.ReplaceAll(q.JobId, "-", "") == strings.ReplaceAll(jobId, "-", "")
+}
+
+// Apply returns the page of entries matching the query, the total number of matching entries and
+// the cursor for the next page, which is empty on the last page.
+func (q StorageCheckpointsQuery) Apply(entries []StorageEntry) (page []StorageEntry, total int, nextCursor string, err error) {
+	cursor, err := decodeStorageCursor(q.Cursor)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	matching := make([]StorageEntry, 0, len(entries))
+	for _, entry := range entries {
+		if q.matches(entry) {
+			matching = append(matching, entry)
+		}
+	}
+
+	less := func(a, b storageCursor) bool {
+		if q.Sort == SortOrderAsc {
+			return compareStorageCursors(a, b) < 0
+		}
+
+		return compareStorageCursors(a, b) > 0
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return less(storageCursorOf(matching[i]), storageCursorOf(matching[j]))
+	})
+
+	total = len(matching)
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(matching), func(i int) bool {
+			return less(*cursor, storageCursorOf(matching[i]))
+		})
+	}
+
+	end := len(matching)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+		nextCursor = encodeStorageCursor(storageCursorOf(matching[end-1]))
+	}
+
+	return matching[start:end], total, nextCursor, nil
+}
+
+func (q StorageCheckpointsQuery) matches(entry StorageEntry) bool {
+	if !q.includesType(entry.Type) || !q.includesJob(entry.JobId) {
+		return false
+	}
+
+	if q.From.IsZero() && q.To.IsZero() {
+		return true
+	}
+
+	if entry.LastModified == nil {
+		return false
+	}
+
+	if !q.From.IsZero() && entry.LastModified.Before(q.From) {
+		return false
+	}
+
+	return q.To.IsZero() || !entry.LastModified.After(q.To)
+}
+
+// parseCheckpointId returns the number of a "chk-<n>" directory.
+func parseCheckpointId(name string) *int64 {
+	if !strings.HasPrefix(name, "chk-") {
+		return nil
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(name, "chk-"), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &id
+}
+
+func storageCursorOf(entry StorageEntry) storageCursor {
+	cursor := storageCursor{Path: entry.Path}
+
+	if entry.LastModified != nil {
+		cursor.LastModified = entry.LastModified.UnixNano()
+	}
+
+	if entry.CheckpointId != nil {
+		cursor.CheckpointId = *entry.CheckpointId
+	}
+
+	return cursor
+}
+
+// compareStorageCursors orders by modification time, then checkpoint number and finally by path to be total.
+func compareStorageCursors(a, b storageCursor) int {
+	switch {
+	case a.LastModified != b.LastModified:
+		return cmp.Compare(a.LastModified, b.LastModified)
+	case a.CheckpointId != b.CheckpointId:
+		return cmp.Compare(a.CheckpointId, b.CheckpointId)
+	default:
+		return strings.Compare(a.Path, b.Path)
+	}
+}
+
+func encodeStorageCursor(cursor storageCursor) string {
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeStorageCursor(value string) (*storageCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	cursor := &storageCursor{}
+	if err = json.Unmarshal(data, cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	return cursor, nil
+}
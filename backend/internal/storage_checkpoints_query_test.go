@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestStorageEntry(entryType string, jobId string, name string, minute int) StorageEntry {
+	lastModified := time.Date(2024, 1, 1, 0, minute, 0, 0, time.UTC)
+
+	return StorageEntry{
+		Name:         name,
+		Path:         "s3://bucket/" + jobId + "/" + name + "/",
+		Type:         entryType,
+		JobId:        jobId,
+		CheckpointId: parseCheckpointId(name),
+		LastModified: &lastModified,
+	}
+}
+
+func TestStorageCheckpointsQueryPagination(t *testing.T) {
+	entries := []StorageEntry{
+		newTestStorageEntry(StorageEntryTypeCheckpoint, "a", "chk-1", 1),
+		newTestStorageEntry(StorageEntryTypeCheckpoint, "a", "chk-3", 3),
+		newTestStorageEntry(StorageEntryTypeSavepoint, "a", "savepoint-1", 2),
+		newTestStorageEntry(StorageEntryTypeCheckpoint, "b", "chk-2", 2),
+		newTestStorageEntry(StorageEntryTypeCheckpoint, "a", "chk-4", 4),
+	}
+
+	var names []string
+	query := StorageCheckpointsQuery{Limit: 2}
+
+	for {
+		page, total, nextCursor, err := query.Apply(entries)
+		if err != nil {
+			t.Fatalf("apply query: %v", err)
+		}
+		if total != len(entries) {
+			t.Fatalf("expected total %d, got %d", len(entries), total)
+		}
+
+		for _, entry := range page {
+			names = append(names, entry.Name)
+		}
+
+		if nextCursor == "" {
+			break
+		}
+		query.Cursor = nextCursor
+	}
+
+	expected := []string{"chk-4", "chk-3", "chk-2", "savepoint-1", "chk-1"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, names)
+		}
+	}
+}
+
+func TestStorageCheckpointsQueryFilters(t *testing.T) {
+	entries := []StorageEntry{
+		newTestStorageEntry(StorageEntryTypeCheckpoint, "a", "chk-1", 1),
+		newTestStorageEntry(StorageEntryTypeCheckpoint, "a", "chk-2", 2),
+		newTestStorageEntry(StorageEntryTypeSavepoint, "a", "savepoint-1", 2),
+		newTestStorageEntry(StorageEntryTypeCheckpoint, "b", "chk-3", 3),
+	}
+
+	query := StorageCheckpointsQuery{
+		JobId: "a",
+		Type:  StorageEntryTypeCheckpoint,
+		From:  time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC),
+		Sort:  SortOrderAsc,
+	}
+
+	page, total, nextCursor, err := query.Apply(entries)
+	if err != nil {
+		t.Fatalf("apply query: %v", err)
+	}
+
+	if total != 1 || len(page) != 1 || page[0].Name != "chk-2" {
+		t.Fatalf("expected only chk-2, got %d entries: %v", total, page)
+	}
+	if nextCursor != "" {
+		t.Fatalf("expected no next cursor, got %s", nextCursor)
+	}
+}
+
+func TestStorageCheckpointsQueryValidate(t *testing.T) {
+	invalid := []StorageCheckpointsQuery{
+		{Type: "snapshot"},
+		{Sort: "up"},
+		{Limit: -1},
+		{Cursor: "not a cursor"},
+		{From: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, query := range invalid {
+		if err := query.Validate(); err == nil {
+			t.Fatalf("expected query %+v to be invalid", query)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package internal
+
+import "time"
+
+// StorageUsage counts the objects and bytes found below a storage location.
+type StorageUsage struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+func (u *StorageUsage) Add(other StorageUsage) {
+	u.Objects += other.Objects
+	u.Bytes += other.Bytes
+}
+
+// DeploymentStorageUsage is the storage usage of a single deployment split by storage location and job ID.
+// Objects which can not be attributed to a job ID are reported under the empty job ID.
+type DeploymentStorageUsage struct {
+	Namespace        string                  `json:"namespace"`
+	Name             string                  `json:"name"`
+	Checkpoints      StorageUsage            `json:"checkpoints"`
+	Savepoints       StorageUsage            `json:"savepoints"`
+	HighAvailability StorageUsage            `json:"highAvailability"`
+	Total            StorageUsage            `json:"total"`
+	Jobs             map[string]StorageUsage `json:"jobs"`
+	Errors           []string                `json:"errors,omitempty"`
+}
+
+// NamespaceStorageUsage rolls up the storage usage of all deployments in a namespace.
+type NamespaceStorageUsage struct {
+	Namespace        string       `json:"namespace"`
+	Deployments      int          `json:"deployments"`
+	Checkpoints      StorageUsage `json:"checkpoints"`
+	Savepoints       StorageUsage `json:"savepoints"`
+	HighAvailability StorageUsage `json:"highAvailability"`
+	Total            StorageUsage `json:"total"`
+}
+
+// StorageUsageReport is the result of a single scan over all deployments.
+type StorageUsageReport struct {
+	ScannedAt   time.Time                `json:"scannedAt"`
+	DurationMs  int64                    `json:"durationMs"`
+	Total       StorageUsage             `json:"total"`
+	Namespaces  []NamespaceStorageUsage  `json:"namespaces"`
+	Deployments []DeploymentStorageUsage `json:"deployments"`
+}
+
+// StorageUsageSnapshot is the condensed form of a report which is kept in the history.
+// Deployments are keyed by "namespace/name".
+type StorageUsageSnapshot struct {
+	ScannedAt   time.Time               `json:"scannedAt"`
+	Total       StorageUsage            `json:"total"`
+	Namespaces  map[string]StorageUsage `json:"namespaces"`
+	Deployments map[string]StorageUsage `json:"deployments"`
+}
+
+// DeploymentStorageUsagePoint is a single entry of the storage usage trend of one deployment.
+type DeploymentStorageUsagePoint struct {
+	ScannedAt time.Time    `json:"scannedAt"`
+	Usage     StorageUsage `json:"usage"`
+}
+
+func (r *StorageUsageReport) toSnapshot() StorageUsageSnapshot {
+	snapshot := StorageUsageSnapshot{
+		ScannedAt:   r.ScannedAt,
+		Total:       r.Total,
+		Namespaces:  make(map[string]StorageUsage, len(r.Namespaces)),
+		Deployments: make(map[string]StorageUsage, len(r.Deployments)),
+	}
+
+	for _, namespace := range r.Namespaces {
+		snapshot.Namespaces[namespace.Namespace] = namespace.Total
+	}
+
+	for _, deployment := range r.Deployments {
+		snapshot.Deployments[deployment.Namespace+"/"+deployment.Name] = deployment.Total
+	}
+
+	return snapshot
+}
@@ -0,0 +1,385 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ThreadStateRunnable     = "RUNNABLE"
+	ThreadStateBlocked      = "BLOCKED"
+	ThreadStateWaiting      = "WAITING"
+	ThreadStateTimedWaiting = "TIMED_WAITING"
+
+	ThreadDumpFindingDeadlock     = "deadlock"
+	ThreadDumpFindingSharedLock   = "task_threads_on_same_lock"
+	ThreadDumpFindingContention   = "lock_contention"
+	ThreadDumpFindingBackpressure = "waiting_for_buffers"
+
+	// lockContentionThreshold is the number of threads blocked on the same lock from which on it is reported as contended
+	lockContentionThreshold = 3
+)
+
+var (
+	// threadInfoHeader matches the first line of a stringified thread info like
+	// "Map (1/4)#0" daemon prio=5 Id=87 BLOCKED on java.lang.Object@1b2c3d owned by "Legacy Source Thread" Id=91
+	threadInfoHeader = regexp.MustCompile(`^"(.*?)"( daemon)?(?: prio=\d+)? Id=(\d+) ([A-Z_]+)(?: on (\S+))?(?: owned by "(.*)" Id=(\d+))?`)
+	// taskThreadName matches the threads running the subtasks of a job, which also run their mailbox, e.g. "Map -> Sink: Print (2/4)#0"
+	taskThreadName = regexp.MustCompile(`\(\d+/\d+\)(#\d+)?$`)
+	// bufferRequestFrames are the frames of task threads waiting for network buffers, i.e. for their downstream tasks
+	bufferRequestFrames = []string{"LocalBufferPool.requestMemorySegmentBlocking", "LocalBufferPool.requestBufferBuilderBlocking"}
+	// idleMailboxFrames are the frames of task threads waiting for new mails or input, which is their normal idle state
+	idleMailboxFrames = []string{"TaskMailboxImpl.take", "MailboxProcessor.suspend"}
+)
+
+// ThreadDumpThread is a parsed thread of a thread dump. Frames are ordered from the top of the stack.
+type ThreadDumpThread struct {
+	Name           string   `json:"name"`
+	Id             int64    `json:"id"`
+	State          string   `json:"state"`
+	Daemon         bool     `json:"daemon"`
+	TaskThread     bool     `json:"taskThread"`
+	LockName       string   `json:"lockName,omitempty"`
+	LockOwnerName  string   `json:"lockOwnerName,omitempty"`
+	LockOwnerId    int64    `json:"lockOwnerId,omitempty"`
+	LockedMonitors []string `json:"lockedMonitors,omitempty"`
+	Frames         []string `json:"frames"`
+}
+
+// ThreadStackGroup are threads with the same state and identical stacks, the largest group first.
+type ThreadStackGroup struct {
+	State   string   `json:"state"`
+	Count   int      `json:"count"`
+	Threads []string `json:"threads"`
+	Frames  []string `json:"frames"`
+}
+
+// BlockedThread is a thread waiting to enter a monitor together with the thread holding it.
+type BlockedThread struct {
+	Thread        string `json:"thread"`
+	ThreadId      int64  `json:"threadId"`
+	LockName      string `json:"lockName"`
+	OwnerName     string `json:"ownerName,omitempty"`
+	OwnerId       int64  `json:"ownerId,omitempty"`
+	OwnerState    string `json:"ownerState,omitempty"`
+	OwnerTopFrame string `json:"ownerTopFrame,omitempty"`
+}
+
+// ThreadDumpFinding is a pathology detected in a thread dump.
+type ThreadDumpFinding struct {
+	Kind    string   `json:"kind"`
+	Message string   `json:"message"`
+	Threads []string `json:"threads"`
+}
+
+// ThreadDumpAnalysis summarizes a thread dump.
+type ThreadDumpAnalysis struct {
+	ThreadCount int                 `json:"threadCount"`
+	States      map[string]int      `json:"states"`
+	StackGroups []ThreadStackGroup  `json:"stackGroups"`
+	Blocked     []BlockedThread     `json:"blocked"`
+	Findings    []ThreadDumpFinding `json:"findings"`
+}
+
+// parseThreadInfo parses the stringified thread info Flink returns for every thread.
+func parseThreadInfo(info FlinkThreadInfo) ThreadDumpThread {
+	thread := ThreadDumpThread{
+		Name:   info.ThreadName,
+		Frames: []string{},
+	}
+
+	lines := strings.Split(strings.TrimSpace(info.StringifiedThreadInfo), "\n")
+	if match := threadInfoHeader.FindStringSubmatch(strings.TrimSpace(lines[0])); match != nil {
+		thread.Name = match[1]
+		thread.Daemon = match[2] != ""
+		thread.Id, _ = strconv.ParseInt(match[3], 10, 64)
+		thread.State = match[4]
+		thread.LockName = match[5]
+		thread.LockOwnerName = match[6]
+		thread.LockOwnerId, _ = strconv.ParseInt(match[7], 10, 64)
+	}
+
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "at "):
+			thread.Frames = append(thread.Frames, strings.TrimPrefix(line, "at "))
+		case strings.HasPrefix(line, "-  locked "):
+			thread.LockedMonitors = append(thread.LockedMonitors, strings.TrimPrefix(line, "-  locked "))
+		}
+	}
+
+	thread.TaskThread = taskThreadName.MatchString(thread.Name)
+
+	return thread
+}
+
+func parseThreadDump(dump *FlinkThreadDump) []ThreadDumpThread {
+	threads := make([]ThreadDumpThread, 0, len(dump.ThreadInfos))
+	for _, info := range dump.ThreadInfos {
+		threads = append(threads, parseThreadInfo(info))
+	}
+
+	return threads
+}
+
+func (t ThreadDumpThread) stackKey() string {
+	return t.State + "\n" + strings.Join(t.Frames, "\n")
+}
+
+func (t ThreadDumpThread) topFrame() string {
+	if len(t.Frames) == 0 {
+		return ""
+	}
+
+	return t.Frames[0]
+}
+
+func (t ThreadDumpThread) hasFrame(frames []string) bool {
+	for _, frame := range t.Frames {
+		for _, candidate := range frames {
+			if strings.Contains(frame, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// waitsOnLock reports whether the thread waits for a lock or a condition, which is not the idle state of a task thread.
+func (t ThreadDumpThread) waitsOnLock() bool {
+	if t.LockName == "" {
+		return false
+	}
+
+	return t.State == ThreadStateBlocked || !t.hasFrame(idleMailboxFrames)
+}
+
+func analyzeThreadDump(threads []ThreadDumpThread) ThreadDumpAnalysis {
+	analysis := ThreadDumpAnalysis{
+		ThreadCount: len(threads),
+		States:      map[string]int{},
+		StackGroups: groupThreadStacks(threads),
+		Blocked:     []BlockedThread{},
+		Findings:    []ThreadDumpFinding{},
+	}
+
+	byId := make(map[int64]ThreadDumpThread, len(threads))
+	for _, thread := range threads {
+		analysis.States[thread.State]++
+		byId[thread.Id] = thread
+	}
+
+	for _, thread := range threads {
+		if thread.State != ThreadStateBlocked {
+			continue
+		}
+
+		blocked := BlockedThread{
+			Thread:    thread.Name,
+			ThreadId:  thread.Id,
+			LockName:  thread.LockName,
+			OwnerName: thread.LockOwnerName,
+			OwnerId:   thread.LockOwnerId,
+		}
+
+		if owner, ok := byId[thread.LockOwnerId]; ok && thread.LockOwnerName != "" {
+			blocked.OwnerState = owner.State
+			blocked.OwnerTopFrame = owner.topFrame()
+		}
+
+		analysis.Blocked = append(analysis.Blocked, blocked)
+	}
+
+	analysis.Findings = append(analysis.Findings, findDeadlocks(threads, byId)...)
+	analysis.Findings = append(analysis.Findings, findSharedTaskLocks(threads)...)
+	analysis.Findings = append(analysis.Findings, findLockContention(analysis.Blocked)...)
+	analysis.Findings = append(analysis.Findings, findBufferWaits(threads)...)
+
+	return analysis
+}
+
+func groupThreadStacks(threads []ThreadDumpThread) []ThreadStackGroup {
+	groups := map[string]*ThreadStackGroup{}
+	keys := make([]string, 0)
+
+	for _, thread := range threads {
+		key := thread.stackKey()
+
+		group, ok := groups[key]
+		if !ok {
+			group = &ThreadStackGroup{State: thread.State, Frames: thread.Frames}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+
+		group.Count++
+		group.Threads = append(group.Threads, thread.Name)
+	}
+
+	result := make([]ThreadStackGroup, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *groups[key])
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Count > result[j].Count
+	})
+
+	return result
+}
+
+// findDeadlocks follows the lock owners of blocked threads and reports every cycle once.
+func findDeadlocks(threads []ThreadDumpThread, byId map[int64]ThreadDumpThread) []ThreadDumpFinding {
+	findings := make([]ThreadDumpFinding, 0)
+	reported := map[int64]bool{}
+
+	for _, thread := range threads {
+		cycle := deadlockCycle(thread, byId)
+		if len(cycle) == 0 || reported[cycle[0].Id] {
+			continue
+		}
+
+		names := make([]string, 0, len(cycle))
+		for _, member := range cycle {
+			reported[member.Id] = true
+			names = append(names, member.Name)
+		}
+
+		findings = append(findings, ThreadDumpFinding{
+			Kind:    ThreadDumpFindingDeadlock,
+			Message: fmt.Sprintf("%d threads are deadlocked, each waiting for a lock held by the next one: %s", len(cycle), strings.Join(names, " -> ")),
+			Threads: names,
+		})
+	}
+
+	return findings
+}
+
+// deadlockCycle returns the threads of the cycle the thread is part of, starting with the one with the lowest id.
+func deadlockCycle(thread ThreadDumpThread, byId map[int64]ThreadDumpThread) []ThreadDumpThread {
+	path := []ThreadDumpThread{thread}
+	seen := map[int64]bool{thread.Id: true}
+	current := thread
+
+	for current.State == ThreadStateBlocked && current.LockOwnerName != "" {
+		owner, ok := byId[current.LockOwnerId]
+		if !ok {
+			return nil
+		}
+
+		if owner.Id == thread.Id {
+			lowest := 0
+			for i := range path {
+				if path[i].Id < path[lowest].Id {
+					lowest = i
+				}
+			}
+
+			return append(path[lowest:], path[:lowest]...)
+		}
+
+		if seen[owner.Id] {
+			// the thread only leads into a cycle it is not part of
+			return nil
+		}
+
+		seen[owner.Id] = true
+		path = append(path, owner)
+		current = owner
+	}
+
+	return nil
+}
+
+// findSharedTaskLocks reports locks several task threads wait for. As the task threads also run the mailbox,
+// none of these subtasks is processing records or checkpoint barriers.
+func findSharedTaskLocks(threads []ThreadDumpThread) []ThreadDumpFinding {
+	waiting := map[string][]string{}
+	locks := make([]string, 0)
+
+	for _, thread := range threads {
+		if !thread.TaskThread || !thread.waitsOnLock() {
+			continue
+		}
+
+		if _, ok := waiting[thread.LockName]; !ok {
+			locks = append(locks, thread.LockName)
+		}
+
+		waiting[thread.LockName] = append(waiting[thread.LockName], thread.Name)
+	}
+
+	findings := make([]ThreadDumpFinding, 0)
+	for _, lock := range locks {
+		if len(waiting[lock]) < 2 {
+			continue
+		}
+
+		findings = append(findings, ThreadDumpFinding{
+			Kind:    ThreadDumpFindingSharedLock,
+			Message: fmt.Sprintf("%d task threads are waiting on the same lock %s, so their mailboxes are not processed", len(waiting[lock]), lock),
+			Threads: waiting[lock],
+		})
+	}
+
+	return findings
+}
+
+func findLockContention(blocked []BlockedThread) []ThreadDumpFinding {
+	byLock := map[string][]BlockedThread{}
+	locks := make([]string, 0)
+
+	for _, thread := range blocked {
+		if _, ok := byLock[thread.LockName]; !ok {
+			locks = append(locks, thread.LockName)
+		}
+
+		byLock[thread.LockName] = append(byLock[thread.LockName], thread)
+	}
+
+	findings := make([]ThreadDumpFinding, 0)
+	for _, lock := range locks {
+		if len(byLock[lock]) < lockContentionThreshold {
+			continue
+		}
+
+		owner := byLock[lock][0]
+		names := make([]string, 0, len(byLock[lock]))
+		for _, thread := range byLock[lock] {
+			names = append(names, thread.Thread)
+		}
+
+		findings = append(findings, ThreadDumpFinding{
+			Kind:    ThreadDumpFindingContention,
+			Message: fmt.Sprintf("%d threads are blocked on %s held by %q (%s at %s)", len(names), lock, owner.OwnerName, owner.OwnerState, owner.OwnerTopFrame),
+			Threads: names,
+		})
+	}
+
+	return findings
+}
+
+func findBufferWaits(threads []ThreadDumpThread) []ThreadDumpFinding {
+	names := make([]string, 0)
+	for _, thread := range threads {
+		if thread.TaskThread && thread.hasFrame(bufferRequestFrames) {
+			names = append(names, thread.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	return []ThreadDumpFinding{{
+		Kind:    ThreadDumpFindingBackpressure,
+		Message: fmt.Sprintf("%d task threads are waiting for network buffers, so they are back pressured by their downstream tasks", len(names)),
+		Threads: names,
+	}}
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestAnalyzeThreadDump(t *testing.T) {
+	dump := &FlinkThreadDump{ThreadInfos: []FlinkThreadInfo{
+		{
+			ThreadName: "Map (1/2)#0",
+			StringifiedThreadInfo: "\"Map (1/2)#0\" prio=5 Id=81 BLOCKED on java.lang.Object@1a2b owned by \"Sink (1/1)#0\" Id=83\n" +
+				"\tat com.example.Cache.get(Cache.java:42)\n" +
+				"\t-  blocked on java.lang.Object@1a2b\n" +
+				"\tat com.example.Enricher.map(Enricher.java:17)\n" +
+				"\t-  locked java.lang.Object@3c4d\n",
+		},
+		{
+			ThreadName: "Map (2/2)#0",
+			StringifiedThreadInfo: "\"Map (2/2)#0\" prio=5 Id=82 BLOCKED on java.lang.Object@1a2b owned by \"Sink (1/1)#0\" Id=83\n" +
+				"\tat com.example.Cache.get(Cache.java:42)\n" +
+				"\t-  blocked on java.lang.Object@1a2b\n" +
+				"\tat com.example.Enricher.map(Enricher.java:17)\n",
+		},
+		{
+			ThreadName: "Sink (1/1)#0",
+			StringifiedThreadInfo: "\"Sink (1/1)#0\" prio=5 Id=83 BLOCKED on java.lang.Object@3c4d owned by \"Map (1/2)#0\" Id=81\n" +
+				"\tat com.example.Cache.refresh(Cache.java:70)\n" +
+				"\t-  locked java.lang.Object@1a2b\n",
+		},
+		{
+			ThreadName:            "Flink Netty Server (0) Thread 0",
+			StringifiedThreadInfo: "\"Flink Netty Server (0) Thread 0\" daemon prio=5 Id=40 RUNNABLE (in native)\n\tat sun.nio.ch.EPoll.wait(Native Method)\n",
+		},
+	}}
+
+	threads := parseThreadDump(dump)
+	if threads[0].Id != 81 || threads[0].State != ThreadStateBlocked || threads[0].LockOwnerId != 83 || !threads[0].TaskThread {
+		t.Fatalf("unexpected parsed thread %+v", threads[0])
+	}
+	if len(threads[0].Frames) != 2 || threads[0].LockedMonitors[0] != "java.lang.Object@3c4d" {
+		t.Fatalf("unexpected frames %v and locked monitors %v", threads[0].Frames, threads[0].LockedMonitors)
+	}
+	if !threads[3].Daemon || threads[3].TaskThread || threads[3].State != ThreadStateRunnable {
+		t.Fatalf("unexpected parsed thread %+v", threads[3])
+	}
+
+	analysis := analyzeThreadDump(threads)
+	if analysis.States[ThreadStateBlocked] != 3 || len(analysis.Blocked) != 3 {
+		t.Fatalf("expected 3 blocked threads, got %v", analysis.States)
+	}
+	if analysis.StackGroups[0].Count != 2 {
+		t.Fatalf("expected the map threads to share a stack, got %+v", analysis.StackGroups[0])
+	}
+
+	kinds := map[string]int{}
+	for _, finding := range analysis.Findings {
+		kinds[finding.Kind]++
+	}
+
+	if kinds[ThreadDumpFindingDeadlock] != 1 || kinds[ThreadDumpFindingSharedLock] != 1 || kinds[ThreadDumpFindingContention] != 0 {
+		t.Fatalf("unexpected findings %+v", analysis.Findings)
+	}
+}
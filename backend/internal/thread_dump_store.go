@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/justtrackio/gosoline/pkg/appctx"
+	"github.com/justtrackio/gosoline/pkg/cfg"
+	"github.com/justtrackio/gosoline/pkg/uuid"
+)
+
+const (
+	ThreadDumpSourceJobManager  = "jobmanager"
+	ThreadDumpSourceTaskManager = "taskmanager"
+)
+
+type ThreadDumpSettings struct {
+	Retention int `cfg:"retention" default:"20"`
+}
+
+// ThreadDump is a captured and analyzed thread dump of the job manager or a task manager of a deployment.
+type ThreadDump struct {
+	ThreadDumpSummary
+	Threads  []ThreadDumpThread `json:"threads"`
+	Analysis ThreadDumpAnalysis `json:"analysis"`
+}
+
+// ThreadDumpSummary identifies a captured thread dump without its threads.
+type ThreadDumpSummary struct {
+	Id            string    `json:"id"`
+	Namespace     string    `json:"namespace"`
+	Name          string    `json:"name"`
+	Source        string    `json:"source"`
+	TaskManagerId string    `json:"taskManagerId,omitempty"`
+	CapturedAt    time.Time `json:"capturedAt"`
+	ThreadCount   int       `json:"threadCount"`
+	FindingCount  int       `json:"findingCount"`
+}
+
+// ThreadChange is a thread present in both compared thread dumps.
+type ThreadChange struct {
+	Name        string `json:"name"`
+	Id          int64  `json:"id"`
+	BeforeState string `json:"beforeState"`
+	AfterState  string `json:"afterState"`
+	TopFrame    string `json:"topFrame,omitempty"`
+}
+
+// ThreadDumpComparison compares two thread dumps of the same process. Stuck threads are blocked threads or busy
+// task threads which have the identical state and stack in both dumps.
+type ThreadDumpComparison struct {
+	Before     ThreadDumpSummary `json:"before"`
+	After      ThreadDumpSummary `json:"after"`
+	StateDelta map[string]int    `json:"stateDelta"`
+	Added      []string          `json:"added"`
+	Removed    []string          `json:"removed"`
+	Changed    []ThreadChange    `json:"changed"`
+	Stuck      []ThreadChange    `json:"stuck"`
+}
+
+type threadDumpStoreCtxKey struct{}
+
+// ThreadDumpStore keeps the last captured thread dumps of every deployment in memory, so they can be compared.
+type ThreadDumpStore struct {
+	lck      sync.Mutex
+	settings *ThreadDumpSettings
+	dumps    map[string]*ringBuffer[*ThreadDump]
+}
+
+func ProvideThreadDumpStore(ctx context.Context, config cfg.Config) (*ThreadDumpStore, error) {
+	return appctx.Provide(ctx, threadDumpStoreCtxKey{}, func() (*ThreadDumpStore, error) {
+		settings := &ThreadDumpSettings{}
+		if err := config.UnmarshalKey("thread_dumps", settings); err != nil {
+			return nil, fmt.Errorf("could not unmarshal thread dump settings: %w", err)
+		}
+
+		return &ThreadDumpStore{
+			settings: settings,
+			dumps:    map[string]*ringBuffer[*ThreadDump]{},
+		}, nil
+	})
+}
+
+// Add parses and analyzes a thread dump fetched from Flink and keeps it.
+func (s *ThreadDumpStore) Add(namespace string, name string, source string, taskManagerID string, dump *FlinkThreadDump) *ThreadDump {
+	threads := parseThreadDump(dump)
+	analysis := analyzeThreadDump(threads)
+
+	threadDump := &ThreadDump{
+		ThreadDumpSummary: ThreadDumpSummary{
+			Id:            uuid.New().NewV4(),
+			Namespace:     namespace,
+			Name:          name,
+			Source:        source,
+			TaskManagerId: taskManagerID,
+			CapturedAt:    time.Now().UTC(),
+			ThreadCount:   analysis.ThreadCount,
+			FindingCount:  len(analysis.Findings),
+		},
+		Threads:  threads,
+		Analysis: analysis,
+	}
+
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	key := namespace + "/" + name
+	if _, ok := s.dumps[key]; !ok {
+		s.dumps[key] = newRingBuffer[*ThreadDump](s.settings.Retention)
+	}
+
+	s.dumps[key].Add(threadDump)
+
+	return threadDump
+}
+
+// List returns the summaries of the kept thread dumps of a deployment, the latest first.
+func (s *ThreadDumpStore) List(namespace string, name string) []ThreadDumpSummary {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	summaries := make([]ThreadDumpSummary, 0)
+	if dumps, ok := s.dumps[namespace+"/"+name]; ok {
+		for _, dump := range dumps.Items() {
+			summaries = append(summaries, dump.ThreadDumpSummary)
+		}
+	}
+
+	slices.Reverse(summaries)
+
+	return summaries
+}
+
+func (s *ThreadDumpStore) Get(namespace string, name string, id string) (*ThreadDump, bool) {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+
+	dumps, ok := s.dumps[namespace+"/"+name]
+	if !ok {
+		return nil, false
+	}
+
+	for _, dump := range dumps.Items() {
+		if dump.Id == id {
+			return dump, true
+		}
+	}
+
+	return nil, false
+}
+
+func compareThreadDumps(before *ThreadDump, after *ThreadDump) *ThreadDumpComparison {
+	comparison := &ThreadDumpComparison{
+		Before:     before.ThreadDumpSummary,
+		After:      after.ThreadDumpSummary,
+		StateDelta: map[string]int{},
+		Added:      []string{},
+		Removed:    []string{},
+		Changed:    []ThreadChange{},
+		Stuck:      []ThreadChange{},
+	}
+
+	for state, count := range after.Analysis.States {
+		comparison.StateDelta[state] += count
+	}
+
+	for state, count := range before.Analysis.States {
+		comparison.StateDelta[state] -= count
+	}
+
+	beforeThreads := make(map[int64]ThreadDumpThread, len(before.Threads))
+	for _, thread := range before.Threads {
+		beforeThreads[thread.Id] = thread
+	}
+
+	for _, thread := range after.Threads {
+		previous, ok := beforeThreads[thread.Id]
+		if !ok {
+			comparison.Added = append(comparison.Added, thread.Name)
+
+			continue
+		}
+
+		delete(beforeThreads, thread.Id)
+
+		change := ThreadChange{
+			Name:        thread.Name,
+			Id:          thread.Id,
+			BeforeState: previous.State,
+			AfterState:  thread.State,
+			TopFrame:    thread.topFrame(),
+		}
+
+		switch {
+		case previous.State != thread.State:
+			comparison.Changed = append(comparison.Changed, change)
+		case previous.stackKey() == thread.stackKey() && isStuckThread(thread):
+			comparison.Stuck = append(comparison.Stuck, change)
+		}
+	}
+
+	for _, thread := range beforeThreads {
+		comparison.Removed = append(comparison.Removed, thread.Name)
+	}
+
+	sort.Strings(comparison.Added)
+	sort.Strings(comparison.Removed)
+
+	return comparison
+}
+
+// isStuckThread reports whether an unchanged thread indicates a problem. Most threads of a process wait in the
+// same place for work all the time, so only blocked threads and task threads which are not idle in their
+// mailbox count as stuck.
+func isStuckThread(thread ThreadDumpThread) bool {
+	if thread.State == ThreadStateBlocked {
+		return true
+	}
+
+	return thread.TaskThread && len(thread.Frames) > 0 && !thread.hasFrame(idleMailboxFrames)
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompareCapturedThreadDumps(t *testing.T) {
+	dumps := []FlinkThreadDump{
+		{ThreadInfos: []FlinkThreadInfo{
+			{ThreadName: "Map (1/1)#0", StringifiedThreadInfo: "\"Map (1/1)#0\" prio=5 Id=81 RUNNABLE\n\tat com.example.Enricher.map(Enricher.java:17)\n"},
+			{ThreadName: "Sink (1/1)#0", StringifiedThreadInfo: "\"Sink (1/1)#0\" prio=5 Id=83 BLOCKED on java.lang.Object@1a2b owned by \"Map (1/1)#0\" Id=81\n\tat com.example.Cache.refresh(Cache.java:70)\n"},
+		}},
+		{ThreadInfos: []FlinkThreadInfo{
+			{ThreadName: "Map (1/1)#0", StringifiedThreadInfo: "\"Map (1/1)#0\" prio=5 Id=81 WAITING on java.lang.Object@3c4d\n\tat java.lang.Object.wait(Native Method)\n"},
+			{ThreadName: "Sink (1/1)#0", StringifiedThreadInfo: "\"Sink (1/1)#0\" prio=5 Id=83 BLOCKED on java.lang.Object@1a2b owned by \"Map (1/1)#0\" Id=81\n\tat com.example.Cache.refresh(Cache.java:70)\n"},
+			{ThreadName: "Timer", StringifiedThreadInfo: "\"Timer\" daemon prio=5 Id=90 TIMED_WAITING\n\tat java.lang.Thread.sleep(Native Method)\n"},
+		}},
+	}
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		dump := dumps[min(int(requests.Add(1))-1, len(dumps)-1)]
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(dump)
+	}))
+	defer server.Close()
+
+	// captures within the ttl of the response cache have to be distinct
+	client := newTestFlinkClient(FlinkClientSettings{CacheTtl: time.Minute, Retry: FlinkClientRetrySettings{MaxAttempts: 1}})
+	store := &ThreadDumpStore{settings: &ThreadDumpSettings{Retention: 5}, dumps: map[string]*ringBuffer[*ThreadDump]{}}
+
+	captured := make([]*ThreadDump, 0, len(dumps))
+	for range dumps {
+		dump, err := client.GetTaskManagerThreadDump(context.Background(), server.URL, "tm-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		captured = append(captured, store.Add("streaming", "orders", ThreadDumpSourceTaskManager, "tm-1", dump))
+	}
+
+	if requests.Load() != 2 || captured[0].ThreadCount != 2 || captured[1].ThreadCount != 3 {
+		t.Fatalf("expected two distinct captures, got %d requests and %d and %d threads", requests.Load(), captured[0].ThreadCount, captured[1].ThreadCount)
+	}
+
+	comparison := compareThreadDumps(captured[0], captured[1])
+
+	if !slices.Equal(comparison.Added, []string{"Timer"}) || len(comparison.Removed) != 0 {
+		t.Errorf("unexpected added %v or removed %v threads", comparison.Added, comparison.Removed)
+	}
+
+	if len(comparison.Changed) != 1 || comparison.Changed[0].Name != "Map (1/1)#0" ||
+		comparison.Changed[0].BeforeState != ThreadStateRunnable || comparison.Changed[0].AfterState != ThreadStateWaiting {
+		t.Errorf("unexpected changed threads %+v", comparison.Changed)
+	}
+
+	if len(comparison.Stuck) != 1 || comparison.Stuck[0].Name != "Sink (1/1)#0" {
+		t.Errorf("unexpected stuck threads %+v", comparison.Stuck)
+	}
+
+	if comparison.StateDelta[ThreadStateRunnable] != -1 || comparison.StateDelta[ThreadStateTimedWaiting] != 1 {
+		t.Errorf("unexpected state delta %v", comparison.StateDelta)
+	}
+}
@@ -0,0 +1,247 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// flinkNoWatermark is reported by Flink for subtasks which did not receive a watermark yet (Long.MIN_VALUE)
+	flinkNoWatermark = math.MinInt64
+
+	WatermarkAlertLag  = "lag"
+	WatermarkAlertSkew = "skew"
+	WatermarkAlertIdle = "idle"
+)
+
+// SubtaskWatermark is the current input watermark of a single subtask. The watermark is nil if the subtask
+// did not receive any watermark yet.
+type SubtaskWatermark struct {
+	Subtask        int       `json:"subtask"`
+	Watermark      *int64    `json:"watermark"`
+	LagMs          *int64    `json:"lagMs"`
+	LastAdvancedAt time.Time `json:"lastAdvancedAt"`
+	Idle           bool      `json:"idle"`
+}
+
+// VertexWatermarks summarizes the watermarks of all subtasks of a vertex. The lag is measured from the lowest
+// watermark to the wall clock, the skew is the difference between the highest and the lowest watermark.
+type VertexWatermarks struct {
+	VertexId     string             `json:"vertexId"`
+	Name         string             `json:"name"`
+	Parallelism  int                `json:"parallelism"`
+	MinWatermark *int64             `json:"minWatermark"`
+	MaxWatermark *int64             `json:"maxWatermark"`
+	LagMs        *int64             `json:"lagMs"`
+	SkewMs       int64              `json:"skewMs"`
+	IdleSubtasks []int              `json:"idleSubtasks"`
+	Subtasks     []SubtaskWatermark `json:"subtasks"`
+	Error        string             `json:"error,omitempty"`
+}
+
+// WatermarkAlert is raised if the watermarks of a vertex lag behind, are skewed or stopped moving.
+type WatermarkAlert struct {
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	JobId       string    `json:"jobId"`
+	Type        string    `json:"type"`
+	VertexId    string    `json:"vertexId"`
+	VertexName  string    `json:"vertexName"`
+	Subtasks    []int     `json:"subtasks,omitempty"`
+	ValueMs     int64     `json:"valueMs"`
+	ThresholdMs int64     `json:"thresholdMs"`
+	Message     string    `json:"message"`
+	Since       time.Time `json:"since"`
+}
+
+// WatermarkReport is the watermark state of all vertices of a job together with the currently active alerts.
+type WatermarkReport struct {
+	Namespace string             `json:"namespace"`
+	Name      string             `json:"name"`
+	JobId     string             `json:"jobId"`
+	CheckedAt time.Time          `json:"checkedAt"`
+	Vertices  []VertexWatermarks `json:"vertices"`
+	Alerts    []WatermarkAlert   `json:"alerts"`
+}
+
+type trackedWatermark struct {
+	value      int64
+	advancedAt time.Time
+}
+
+// watermarkTracker remembers when the watermark of each subtask of a job advanced the last time, which is
+// needed to detect idle subtasks over consecutive polls, and since when each alert is active.
+type watermarkTracker struct {
+	jobId       string
+	subtasks    map[string]trackedWatermark
+	alertsSince map[string]time.Time
+}
+
+func newWatermarkTracker(jobId string) *watermarkTracker {
+	return &watermarkTracker{
+		jobId:       jobId,
+		subtasks:    map[string]trackedWatermark{},
+		alertsSince: map[string]time.Time{},
+	}
+}
+
+// observe records the watermarks of a vertex and returns them ordered by subtask. Metrics which are not
+// a current input watermark are ignored.
+func (t *watermarkTracker) observe(now time.Time, vertex *VertexWatermarks, values []FlinkMetric, idleTimeout time.Duration) {
+	vertex.Subtasks = make([]SubtaskWatermark, 0, len(values))
+	vertex.IdleSubtasks = []int{}
+
+	for _, metric := range values {
+		subtask, watermark, ok := parseSubtaskWatermark(metric)
+		if !ok {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%d", vertex.VertexId, subtask)
+		tracked, exists := t.subtasks[key]
+		if !exists || tracked.value != watermark {
+			tracked = trackedWatermark{value: watermark, advancedAt: now}
+			t.subtasks[key] = tracked
+		}
+
+		// sources and processing time jobs never receive a watermark, only a real watermark which stopped moving is idle
+		result := SubtaskWatermark{
+			Subtask:        subtask,
+			LastAdvancedAt: tracked.advancedAt,
+			Idle:           watermark != flinkNoWatermark && now.Sub(tracked.advancedAt) >= idleTimeout,
+		}
+
+		if watermark != flinkNoWatermark {
+			lag := now.UnixMilli() - watermark
+			result.Watermark = &watermark
+			result.LagMs = &lag
+
+			if vertex.MinWatermark == nil || watermark < *vertex.MinWatermark {
+				vertex.MinWatermark = &watermark
+			}
+
+			if vertex.MaxWatermark == nil || watermark > *vertex.MaxWatermark {
+				vertex.MaxWatermark = &watermark
+			}
+		}
+
+		if result.Idle {
+			vertex.IdleSubtasks = append(vertex.IdleSubtasks, subtask)
+		}
+
+		vertex.Subtasks = append(vertex.Subtasks, result)
+	}
+
+	sort.Slice(vertex.Subtasks, func(i, j int) bool {
+		return vertex.Subtasks[i].Subtask < vertex.Subtasks[j].Subtask
+	})
+	sort.Ints(vertex.IdleSubtasks)
+
+	if vertex.MinWatermark != nil {
+		lag := now.UnixMilli() - *vertex.MinWatermark
+		vertex.LagMs = &lag
+		vertex.SkewMs = *vertex.MaxWatermark - *vertex.MinWatermark
+	}
+}
+
+// evaluate derives the alerts of the report. As the watermark of a vertex can never be ahead of its inputs,
+// the lag only grows downstream, so only the first vertex in topological order exceeding the lag threshold
+// is reported as it is the closest one to the cause.
+func (t *watermarkTracker) evaluate(now time.Time, report *WatermarkReport, settings *WatermarkMonitorSettings) (raised []WatermarkAlert) {
+	report.Alerts = []WatermarkAlert{}
+	lagReported := false
+
+	for _, vertex := range report.Vertices {
+		if vertex.Error != "" {
+			continue
+		}
+
+		if !lagReported && vertex.LagMs != nil && *vertex.LagMs > settings.LagThreshold.Milliseconds() {
+			lagReported = true
+			report.Alerts = append(report.Alerts, WatermarkAlert{
+				Type:        WatermarkAlertLag,
+				VertexId:    vertex.VertexId,
+				VertexName:  vertex.Name,
+				ValueMs:     *vertex.LagMs,
+				ThresholdMs: settings.LagThreshold.Milliseconds(),
+				Message:     fmt.Sprintf("watermark lags %s behind the wall clock", time.Duration(*vertex.LagMs)*time.Millisecond),
+			})
+		}
+
+		if vertex.SkewMs > settings.SkewThreshold.Milliseconds() {
+			report.Alerts = append(report.Alerts, WatermarkAlert{
+				Type:        WatermarkAlertSkew,
+				VertexId:    vertex.VertexId,
+				VertexName:  vertex.Name,
+				ValueMs:     vertex.SkewMs,
+				ThresholdMs: settings.SkewThreshold.Milliseconds(),
+				Message:     fmt.Sprintf("watermarks of the subtasks are %s apart", time.Duration(vertex.SkewMs)*time.Millisecond),
+			})
+		}
+
+		if len(vertex.IdleSubtasks) > 0 {
+			report.Alerts = append(report.Alerts, WatermarkAlert{
+				Type:        WatermarkAlertIdle,
+				VertexId:    vertex.VertexId,
+				VertexName:  vertex.Name,
+				Subtasks:    vertex.IdleSubtasks,
+				ValueMs:     int64(len(vertex.IdleSubtasks)),
+				ThresholdMs: settings.IdleTimeout.Milliseconds(),
+				Message:     fmt.Sprintf("watermark of %d of %d subtasks did not advance for at least %s", len(vertex.IdleSubtasks), len(vertex.Subtasks), settings.IdleTimeout),
+			})
+		}
+	}
+
+	active := make(map[string]time.Time, len(report.Alerts))
+	for i := range report.Alerts {
+		alert := &report.Alerts[i]
+		alert.Namespace = report.Namespace
+		alert.Name = report.Name
+		alert.JobId = report.JobId
+
+		key := alert.Type + "/" + alert.VertexId
+		since, ok := t.alertsSince[key]
+		if !ok {
+			since = now
+			raised = append(raised, *alert)
+		}
+
+		alert.Since = since
+		active[key] = since
+	}
+	t.alertsSince = active
+
+	return raised
+}
+
+// parseSubtaskWatermark parses a metric like {"id": "0.currentInputWatermark", "value": "1700000000000"}.
+func parseSubtaskWatermark(metric FlinkMetric) (subtask int, watermark int64, ok bool) {
+	index, name, found := strings.Cut(metric.Id, ".")
+	if !found || name != "currentInputWatermark" {
+		return 0, 0, false
+	}
+
+	subtask, err := strconv.Atoi(index)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if watermark, err = strconv.ParseInt(metric.Value, 10, 64); err == nil {
+		return subtask, watermark, true
+	}
+
+	value, err := strconv.ParseFloat(metric.Value, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if value <= math.MinInt64 {
+		return subtask, flinkNoWatermark, true
+	}
+
+	return subtask, int64(value), true
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseSubtaskWatermark(t *testing.T) {
+	cases := map[string]struct {
+		metric    FlinkMetric
+		subtask   int
+		watermark int64
+		ok        bool
+	}{
+		"integer":        {metric: FlinkMetric{Id: "2.currentInputWatermark", Value: "1700000000000"}, subtask: 2, watermark: 1700000000000, ok: true},
+		"float":          {metric: FlinkMetric{Id: "0.currentInputWatermark", Value: "1.7E12"}, subtask: 0, watermark: 1700000000000, ok: true},
+		"no watermark":   {metric: FlinkMetric{Id: "1.currentInputWatermark", Value: "-9223372036854775808"}, subtask: 1, watermark: flinkNoWatermark, ok: true},
+		"float minimum":  {metric: FlinkMetric{Id: "1.currentInputWatermark", Value: "-9.223372036854776E18"}, subtask: 1, watermark: flinkNoWatermark, ok: true},
+		"other metric":   {metric: FlinkMetric{Id: "0.numRecordsIn", Value: "5"}},
+		"no subtask":     {metric: FlinkMetric{Id: "currentInputWatermark", Value: "5"}},
+		"invalid value":  {metric: FlinkMetric{Id: "0.currentInputWatermark", Value: "n/a"}},
+		"invalid index":  {metric: FlinkMetric{Id: "x.currentInputWatermark", Value: "5"}},
+		"empty response": {metric: FlinkMetric{}},
+	}
+
+	for name, tc := range cases {
+		subtask, watermark, ok := parseSubtaskWatermark(tc.metric)
+		if ok != tc.ok || ok && (subtask != tc.subtask || watermark != tc.watermark) {
+			t.Errorf("%s: expected %d/%d/%v, got %d/%d/%v", name, tc.subtask, tc.watermark, tc.ok, subtask, watermark, ok)
+		}
+	}
+}
+
+func TestWatermarkTrackerObserve(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	idleTimeout := 5 * time.Minute
+	noWatermark := strconv.FormatInt(flinkNoWatermark, 10)
+
+	watermarks := func(values ...string) []FlinkMetric {
+		metrics := make([]FlinkMetric, 0, len(values))
+		for i, value := range values {
+			metrics = append(metrics, FlinkMetric{Id: strconv.Itoa(i) + ".currentInputWatermark", Value: value})
+		}
+
+		return metrics
+	}
+
+	millis := func(at time.Time) string {
+		return strconv.FormatInt(at.UnixMilli(), 10)
+	}
+
+	cases := map[string]struct {
+		first        []FlinkMetric
+		second       []FlinkMetric
+		idleSubtasks []int
+		lagMs        *int64
+		skewMs       int64
+	}{
+		"no watermark is never idle": {
+			first:        watermarks(noWatermark, noWatermark),
+			second:       watermarks(noWatermark, noWatermark),
+			idleSubtasks: []int{},
+		},
+		"advancing watermark": {
+			first:        watermarks(millis(start.Add(-time.Minute))),
+			second:       watermarks(millis(start.Add(5 * time.Minute))),
+			idleSubtasks: []int{},
+			lagMs:        millisOf(time.Minute),
+		},
+		"stalled watermark": {
+			first:        watermarks(millis(start), millis(start)),
+			second:       watermarks(millis(start), millis(start.Add(6*time.Minute))),
+			idleSubtasks: []int{0},
+			lagMs:        millisOf(6 * time.Minute),
+			skewMs:       (6 * time.Minute).Milliseconds(),
+		},
+		"skew ignores subtasks without watermark": {
+			first:        watermarks(noWatermark, millis(start)),
+			second:       watermarks(noWatermark, millis(start.Add(2*time.Minute)), millis(start.Add(6*time.Minute))),
+			idleSubtasks: []int{},
+			lagMs:        millisOf(4 * time.Minute),
+			skewMs:       (4 * time.Minute).Milliseconds(),
+		},
+	}
+
+	for name, tc := range cases {
+		tracker := newWatermarkTracker("job")
+
+		vertex := &VertexWatermarks{VertexId: "vertex"}
+		tracker.observe(start, vertex, tc.first, idleTimeout)
+
+		vertex = &VertexWatermarks{VertexId: "vertex"}
+		tracker.observe(start.Add(6*time.Minute), vertex, tc.second, idleTimeout)
+
+		if !slices.Equal(vertex.IdleSubtasks, tc.idleSubtasks) {
+			t.Errorf("%s: expected idle subtasks %v, got %v", name, tc.idleSubtasks, vertex.IdleSubtasks)
+		}
+
+		if tc.lagMs == nil && vertex.LagMs != nil || tc.lagMs != nil && (vertex.LagMs == nil || *vertex.LagMs != *tc.lagMs) {
+			t.Errorf("%s: expected lag %v, got %v", name, tc.lagMs, vertex.LagMs)
+		}
+
+		if vertex.SkewMs != tc.skewMs {
+			t.Errorf("%s: expected skew %d, got %d", name, tc.skewMs, vertex.SkewMs)
+		}
+
+		for _, subtask := range vertex.Subtasks {
+			if subtask.Watermark == nil && (subtask.Idle || subtask.LagMs != nil) {
+				t.Errorf("%s: expected subtask %d without watermark to be neither idle nor lagging: %+v", name, subtask.Subtask, subtask)
+			}
+		}
+	}
+}
+
+func TestWatermarkTrackerEvaluate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	settings := &WatermarkMonitorSettings{LagThreshold: 15 * time.Minute, SkewThreshold: 10 * time.Minute, IdleTimeout: 5 * time.Minute}
+
+	report := &WatermarkReport{
+		Namespace: "flink",
+		Name:      "orders",
+		JobId:     "job",
+		// the vertices are in topological order, the lag only grows downstream
+		Vertices: []VertexWatermarks{
+			{VertexId: "source", Name: "Source", IdleSubtasks: []int{}},
+			{VertexId: "broken", Name: "Broken", Error: "unavailable", LagMs: millisOf(time.Hour)},
+			{VertexId: "window", Name: "Window", LagMs: millisOf(20 * time.Minute), SkewMs: (11 * time.Minute).Milliseconds(), IdleSubtasks: []int{}},
+			{VertexId: "sink", Name: "Sink", LagMs: millisOf(30 * time.Minute), IdleSubtasks: []int{1}, Subtasks: make([]SubtaskWatermark, 2)},
+		},
+	}
+
+	tracker := newWatermarkTracker("job")
+
+	raised := tracker.evaluate(now, report, settings)
+
+	type alertKey struct {
+		Type     string
+		VertexId string
+	}
+
+	keys := make([]alertKey, 0, len(report.Alerts))
+	for _, alert := range report.Alerts {
+		keys = append(keys, alertKey{alert.Type, alert.VertexId})
+
+		if alert.Namespace != "flink" || alert.Name != "orders" || alert.JobId != "job" || !alert.Since.Equal(now) {
+			t.Errorf("unexpected alert %+v", alert)
+		}
+	}
+
+	expected := []alertKey{{WatermarkAlertLag, "window"}, {WatermarkAlertSkew, "window"}, {WatermarkAlertIdle, "sink"}}
+	if !slices.Equal(keys, expected) {
+		t.Fatalf("expected alerts %v, got %v", expected, keys)
+	}
+
+	if len(raised) != 3 {
+		t.Errorf("expected all alerts to be raised, got %+v", raised)
+	}
+
+	// active alerts keep the time they were raised and are not raised again
+	report.Vertices[2].SkewMs = 0
+	if raised = tracker.evaluate(now.Add(time.Minute), report, settings); len(raised) != 0 {
+		t.Errorf("expected no new alerts, got %+v", raised)
+	}
+
+	if len(report.Alerts) != 2 || !report.Alerts[0].Since.Equal(now) {
+		t.Errorf("unexpected alerts after the skew resolved: %+v", report.Alerts)
+	}
+}
+
+func millisOf(duration time.Duration) *int64 {
+	millis := duration.Milliseconds()
+
+	return &millis
+}
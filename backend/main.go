@@ -31,26 +31,141 @@ func main() {
 		application.WithModuleFactory("k8s-watcher", func(ctx context.Context, config cfg.Config, logger log.Logger) (kernel.Module, error) {
 			return internal.ProvideDeploymentWatcherModule(ctx, config, logger)
 		}),
+		application.WithModuleFactory("storage-usage", func(ctx context.Context, config cfg.Config, logger log.Logger) (kernel.Module, error) {
+			return internal.ProvideStorageUsageModule(ctx, config, logger)
+		}),
+		application.WithModuleFactory("watermark-monitor", func(ctx context.Context, config cfg.Config, logger log.Logger) (kernel.Module, error) {
+			return internal.ProvideWatermarkMonitorModule(ctx, config, logger)
+		}),
+		application.WithModuleFactory("metrics-sampler", func(ctx context.Context, config cfg.Config, logger log.Logger) (kernel.Module, error) {
+			return internal.ProvideMetricsSamplerModule(ctx, config, logger)
+		}),
+		application.WithModuleFactory("job-run-history", func(ctx context.Context, config cfg.Config, logger log.Logger) (kernel.Module, error) {
+			return internal.ProvideJobRunHistoryModule(ctx, config, logger)
+		}),
+		application.WithModuleFactory("exception-collector", func(ctx context.Context, config cfg.Config, logger log.Logger) (kernel.Module, error) {
+			return internal.ProvideExceptionCollectorModule(ctx, config, logger)
+		}),
 		application.WithModuleFactory("http", httpserver.NewServer("default", func(ctx context.Context, config cfg.Config, logger log.Logger, router *httpserver.Router) error {
 			router.Use(cors.Default())
 			router.UseFactory(httpserver.CreateEmbeddedStaticServe(publicFs, "public", "/api"))
+			router.UseFactory(internal.NewFlinkErrorMiddleware)
 
 			router.Group("/api/deployments").HandleWith(httpserver.With(internal.NewHandlerDeployments, func(r *httpserver.Router, handler *internal.HandlerDeployments) {
 				r.GET("/watch", httpserver.BindSseN(handler.WatchDeployments))
+				r.GET("/:namespace/:name/session-jobs", httpserver.Bind(handler.GetSessionJobs))
+			}))
+
+			router.Group("/api/storage-usage").HandleWith(httpserver.With(internal.NewHandlerStorageUsage, func(r *httpserver.Router, handler *internal.HandlerStorageUsage) {
+				r.GET("", httpserver.BindN(handler.GetStorageUsage))
+				r.GET("/history", httpserver.BindN(handler.GetStorageUsageHistory))
+			}))
+
+			router.Group("/api/watermarks").HandleWith(httpserver.With(internal.NewHandlerWatermarks, func(r *httpserver.Router, handler *internal.HandlerWatermarks) {
+				r.GET("/alerts", httpserver.BindN(handler.GetWatermarkAlerts))
 			}))
 
 			deploymentGroup := router.Group("/api/deployments/:namespace/:name")
 			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerCheckpoints, func(r *httpserver.Router, handler *internal.HandlerCheckpoints) {
 				r.GET("/checkpoints", httpserver.Bind(handler.GetCheckpoints))
+				r.GET("/checkpoints/config", httpserver.Bind(handler.GetCheckpointConfig))
+				r.GET("/checkpoints/:checkpointId", httpserver.Bind(handler.GetCheckpointDrilldown))
+				r.GET("/checkpoints/:checkpointId/subtasks/:vertexId", httpserver.Bind(handler.GetCheckpointSubtasks))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerAccumulators, func(r *httpserver.Router, handler *internal.HandlerAccumulators) {
+				r.GET("/accumulators", httpserver.Bind(handler.GetAccumulators))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerJobs, func(r *httpserver.Router, handler *internal.HandlerJobs) {
+				r.GET("/job", httpserver.Bind(handler.GetJobGraph))
+				r.GET("/job/runs", httpserver.Bind(handler.GetJobRuns))
+				r.GET("/job/environment", httpserver.Bind(handler.GetJobEnvironment))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerBackpressure, func(r *httpserver.Router, handler *internal.HandlerBackpressure) {
+				r.GET("/backpressure", httpserver.Bind(handler.GetBackpressure))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerWatermarks, func(r *httpserver.Router, handler *internal.HandlerWatermarks) {
+				r.GET("/watermarks", httpserver.Bind(handler.GetWatermarks))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerFlameGraph, func(r *httpserver.Router, handler *internal.HandlerFlameGraph) {
+				r.GET("/vertices/:vertexId/flamegraph", httpserver.Bind(handler.GetFlameGraph))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerMetrics, func(r *httpserver.Router, handler *internal.HandlerMetrics) {
+				r.GET("/metrics", httpserver.Bind(handler.GetMetrics))
+				r.GET("/metrics/history", httpserver.Bind(handler.GetMetricsHistory))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerSavepoints, func(r *httpserver.Router, handler *internal.HandlerSavepoints) {
+				r.POST("/savepoints", httpserver.BindSse(handler.TriggerSavepoint, internal.OptionalJsonBinding))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerCluster, func(r *httpserver.Router, handler *internal.HandlerCluster) {
+				r.GET("/taskmanagers", httpserver.Bind(handler.GetTaskManagers))
+				r.GET("/taskmanagers/:taskmanager", httpserver.Bind(handler.GetTaskManager))
+				r.GET("/taskmanagers/:taskmanager/logs", httpserver.Bind(handler.GetTaskManagerLogs))
+				r.GET("/taskmanagers/:taskmanager/logs/:file", handler.StreamTaskManagerLogFile)
+				r.GET("/taskmanagers/:taskmanager/log", handler.StreamTaskManagerLog)
+				r.GET("/taskmanagers/:taskmanager/stdout", handler.StreamTaskManagerStdout)
+				r.GET("/jobmanager/config", httpserver.Bind(handler.GetJobManagerConfig))
+				r.GET("/jobmanager/logs", httpserver.Bind(handler.GetJobManagerLogs))
+				r.GET("/jobmanager/logs/:file", handler.StreamJobManagerLogFile)
+				r.GET("/jobmanager/log", handler.StreamJobManagerLog)
+				r.GET("/jobmanager/stdout", handler.StreamJobManagerStdout)
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerThreadDumps, func(r *httpserver.Router, handler *internal.HandlerThreadDumps) {
+				r.POST("/taskmanagers/:taskmanager/thread-dumps", httpserver.Bind(handler.CaptureTaskManagerThreadDump))
+				r.POST("/jobmanager/thread-dumps", httpserver.Bind(handler.CaptureJobManagerThreadDump))
+				r.GET("/thread-dumps", httpserver.Bind(handler.ListThreadDumps))
+				r.GET("/thread-dumps/compare", httpserver.Bind(handler.CompareThreadDumps))
+				r.GET("/thread-dumps/:dumpId", httpserver.Bind(handler.GetThreadDump))
 			}))
 			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerStorageCheckpoints, func(r *httpserver.Router, handler *internal.HandlerStorageCheckpoints) {
 				r.GET("/storage-checkpoints", httpserver.Bind(handler.GetStorageCheckpoints))
 			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerStorageDownloads, func(r *httpserver.Router, handler *internal.HandlerStorageDownloads) {
+				r.GET("/storage/download-url", httpserver.Bind(handler.GetDownloadUrl))
+				r.GET("/storage/export", handler.ExportDirectory)
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerStorageUsage, func(r *httpserver.Router, handler *internal.HandlerStorageUsage) {
+				r.GET("/storage-usage", httpserver.Bind(handler.GetDeploymentStorageUsage))
+			}))
 			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerEvents, func(r *httpserver.Router, handler *internal.HandlerEvents) {
 				r.GET("/events", httpserver.Bind(handler.GetEvents))
 			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerConsistency, func(r *httpserver.Router, handler *internal.HandlerConsistency) {
+				r.GET("/consistency", httpserver.Bind(handler.GetConsistencyReport))
+			}))
+			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerHighAvailability, func(r *httpserver.Router, handler *internal.HandlerHighAvailability) {
+				r.GET("/high-availability", httpserver.Bind(handler.GetHighAvailability))
+			}))
 			deploymentGroup.HandleWith(httpserver.With(internal.NewHandlerExceptions, func(r *httpserver.Router, handler *internal.HandlerExceptions) {
 				r.GET("/exceptions", httpserver.Bind(handler.GetExceptions))
+				r.GET("/exceptions/groups", httpserver.Bind(handler.GetExceptionGroups))
+				r.GET("/exceptions/categories", httpserver.Bind(handler.GetExceptionCategories))
+				r.GET("/exceptions/history", httpserver.Bind(handler.GetExceptionHistory))
+			}))
+
+			// session jobs run on the session cluster of the deployment, so they use its REST API with their own job id
+			sessionJobGroup := router.Group("/api/deployments/:namespace/:name/session-jobs/:sessionJob")
+			sessionJobGroup.HandleWith(httpserver.With(internal.NewHandlerCheckpoints, func(r *httpserver.Router, handler *internal.HandlerCheckpoints) {
+				r.GET("/checkpoints", httpserver.Bind(handler.GetCheckpoints))
+				r.GET("/checkpoints/config", httpserver.Bind(handler.GetCheckpointConfig))
+				r.GET("/checkpoints/:checkpointId", httpserver.Bind(handler.GetCheckpointDrilldown))
+				r.GET("/checkpoints/:checkpointId/subtasks/:vertexId", httpserver.Bind(handler.GetCheckpointSubtasks))
+			}))
+			sessionJobGroup.HandleWith(httpserver.With(internal.NewHandlerJobs, func(r *httpserver.Router, handler *internal.HandlerJobs) {
+				r.GET("/job/environment", httpserver.Bind(handler.GetJobEnvironment))
+				r.GET("/job/runs", httpserver.Bind(handler.GetJobRuns))
+			}))
+			sessionJobGroup.HandleWith(httpserver.With(internal.NewHandlerExceptions, func(r *httpserver.Router, handler *internal.HandlerExceptions) {
+				r.GET("/exceptions", httpserver.Bind(handler.GetExceptions))
+				r.GET("/exceptions/history", httpserver.Bind(handler.GetExceptionHistory))
+				r.GET("/exceptions/groups", httpserver.Bind(handler.GetExceptionGroups))
+				r.GET("/exceptions/categories", httpserver.Bind(handler.GetExceptionCategories))
+			}))
+			sessionJobGroup.HandleWith(httpserver.With(internal.NewHandlerStorageCheckpoints, func(r *httpserver.Router, handler *internal.HandlerStorageCheckpoints) {
+				r.GET("/storage-checkpoints", httpserver.Bind(handler.GetStorageCheckpoints))
+			}))
+			sessionJobGroup.HandleWith(httpserver.With(internal.NewHandlerStorageDownloads, func(r *httpserver.Router, handler *internal.HandlerStorageDownloads) {
+				r.GET("/storage/download-url", httpserver.Bind(handler.GetDownloadUrl))
+				r.GET("/storage/export", handler.ExportDirectory)
 			}))
 
 			return nil
@@ -58,9 +58,16 @@ export interface FlinkCheckpointInfo {
   lastPeriodicCheckpointTimestamp?: number;
 }
 
+export interface FlinkSavepoint {
+  timeStamp?: number;
+  location?: string;
+  triggerType?: string;
+  formatType?: string;
+}
+
 export interface FlinkSavepointInfo {
   lastPeriodicSavepointTimestamp?: number;
-  savepointHistory?: string[];
+  savepointHistory?: FlinkSavepoint[];
 }
 
 export interface FlinkJobStatus {